@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince     string
+	auditUntil     string
+	auditUser      string
+	auditOperation string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the log of destructive operations",
+	Long: `Show the audit log that every clear, removal, prune, trash empty and
+undo appends to: when it ran, who ran it with which command line, the
+caches it targeted, what it deleted and freed, and its errors.
+
+--since and --until take a date (2025-06-01), a time (2025-06-01T15:04:05Z)
+or an age such as 7d, meaning that long ago. A date given to --until
+includes that whole day.
+
+The log is written to audit.log from the config file, by default
+gocachectl/audit.jsonl in the user's config directory.`,
+	Example: `  gocachectl audit                              # Every recorded operation
+  gocachectl audit --since 7d                   # The last week
+  gocachectl audit --since 2025-06-01 --until 2025-06-30 --user alice
+  gocachectl audit --operation clear --json`,
+	Args: cobra.NoArgs,
+	RunE: runAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "show operations at or after this date, time or age")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "show operations before this date, time or age")
+	auditCmd.Flags().StringVar(&auditUser, "user", "", "show only operations run by this user")
+	auditCmd.Flags().StringVar(&auditOperation, "operation", "", `show only this operation, e.g. "clear" or "toolchain prune"`)
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	filter := audit.Filter{User: auditUser, Operation: auditOperation}
+
+	var err error
+	if filter.Since, err = parseAuditTime(auditSince, now, false); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseAuditTime(auditUntil, now, true); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	log, err := auditLog()
+	if err != nil {
+		return err
+	}
+	if log == nil {
+		return fmt.Errorf("auditing is disabled by audit.enabled in the config")
+	}
+
+	records, err := log.Query(filter)
+	if err != nil {
+		return err
+	}
+	if records == nil {
+		records = []audit.Record{}
+	}
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data: records,
+		Table: func(w io.Writer) error {
+			if len(records) == 0 {
+				if !quiet {
+					fmt.Fprintf(w, "No operations recorded in %s\n", log.Path())
+				}
+				return nil
+			}
+			for _, r := range records {
+				outputAuditRecord(w, r)
+			}
+			return nil
+		},
+	})
+}
+
+// outputAuditRecord writes one record as a summary line, plus its command
+// line and failures when verbose
+func outputAuditRecord(w io.Writer, r audit.Record) {
+	targets := joinKinds(r.Targets)
+	if len(r.Roots) > 0 {
+		targets += " @ " + strings.Join(r.Roots, ",")
+	}
+
+	result := fmt.Sprintf("%s deleted, %s freed", cache.FormatCount(r.Deleted), cache.FormatBytes(r.Freed))
+	if r.Operation == "undo" {
+		result = cache.FormatCount(r.Restored) + " restored"
+	}
+	if r.TrashID != "" && r.Operation != "undo" {
+		result += ", quarantined"
+	}
+	if r.Errors > 0 {
+		result += fmt.Sprintf(", %d errors", r.Errors)
+	}
+
+	fmt.Fprintf(w, "%s  %-10s %-16s %-20s %s\n", r.Time.Local().Format("2006-01-02 15:04:05"),
+		r.User, r.Operation, targets, result)
+	if verbose {
+		fmt.Fprintf(w, "   %s (on %s)\n", strings.Join(r.Command, " "), r.Host)
+		for _, failure := range r.Failures {
+			fmt.Fprintf(w, "   %s\n", failure)
+		}
+		if r.Errors > len(r.Failures) {
+			fmt.Fprintf(w, "   ... %d more errors\n", r.Errors-len(r.Failures))
+		}
+	}
+}
+
+// parseAuditTime parses a date, an RFC 3339 time or an age before now.
+// With endOfDay, a date means the end of that day.
+func parseAuditTime(s string, now time.Time, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	age, err := cache.ParseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want a date, a time or an age, got %q", s)
+	}
+	return now.Add(-age), nil
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchCount int
+	benchCases []string
+)
+
+// benchReport is the document written by bench
+type benchReport struct {
+	Package string               `json:"package"`
+	Flags   []string             `json:"flags,omitempty"`
+	Count   int                  `json:"count"` // runs per case
+	Cases   []cache.BenchSummary `json:"cases"`
+	// How many times faster the median warm and edit builds were than the
+	// median cold build; zero when a case was not run
+	WarmSpeedup float64 `json:"warm_speedup,omitempty"`
+	EditSpeedup float64 `json:"edit_speedup,omitempty"`
+}
+
+// benchRecord is one run, the csv row of bench
+type benchRecord struct {
+	Case string `json:"case"`
+	Run  int    `json:"run"`
+	cache.BenchRun
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench <package> [-- build flags]",
+	Short: "Compare build times with a cold cache, a warm cache and after an edit",
+	Long: `Benchmark what the build cache saves when building one package, to put
+numbers behind cache retention budgets. Three cases are timed, each --count
+times:
+
+  cold  the build starts from an empty, temporary GOCACHE
+  warm  the build uses the current GOCACHE, primed by one untimed build
+  edit  the build uses the current GOCACHE after a one-file edit
+
+The edit appends a comment to the package's first Go file through a build
+overlay, so the source is not touched; every run edits it differently, so
+only that package is compiled again, as after a typical change.
+
+For each case, bench reports the minimum, median and mean build time and how
+much the build wrote to the cache. Warm and edit builds write to the current
+GOCACHE; a cold build's cache is removed after it is measured.
+
+The build uses the go command and environment of the current directory.
+Build flags after -- are passed to go build.`,
+	Example: `  gocachectl bench ./cmd/server
+  gocachectl bench ./cmd/server --count 5 --json > bench.json
+  gocachectl bench ./cmd/server --cases warm,edit       # Skip the slow cold builds
+  gocachectl bench ./cmd/server -- -tags integration`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().IntVar(&benchCount, "count", 3, "number of runs per case")
+	benchCmd.Flags().StringSliceVar(&benchCases, "cases", []string{cache.BenchCold, cache.BenchWarm, cache.BenchEdit},
+		"cases to run: cold, warm, edit")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	var flags []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		args, flags = args[:dash], args[dash:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("bench needs exactly one package, got %d", len(args))
+	}
+	if benchCount < 1 {
+		return fmt.Errorf("--count must be at least 1, got %d", benchCount)
+	}
+	var cases []string
+	for _, name := range []string{cache.BenchCold, cache.BenchWarm, cache.BenchEdit} {
+		if slices.Contains(benchCases, name) {
+			cases = append(cases, name)
+		}
+	}
+	for _, name := range benchCases {
+		if !slices.Contains(cases, name) {
+			return fmt.Errorf("unknown case %q (want cold, warm or edit)", name)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	pkg, err := cache.ImportPath(dir, args[0])
+	if err != nil {
+		return err
+	}
+	bencher, err := cache.NewBencher(dir, args[0], flags)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	// Progress would break machine-readable reports
+	progress := cmd.ErrOrStderr()
+	if !output.machine() {
+		progress = cmd.OutOrStdout()
+	}
+	report := benchReport{Package: pkg, Flags: flags, Count: benchCount}
+	var records []benchRecord
+	for _, name := range cases {
+		if name == cache.BenchWarm {
+			if err := bencher.Prime(); err != nil {
+				return err
+			}
+		}
+		var runs []cache.BenchRun
+		for i := range benchCount {
+			if !quiet {
+				fmt.Fprintf(progress, "Running %s build %d/%d...\n", name, i+1, benchCount)
+			}
+			run, err := bencher.Run(name)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			records = append(records, benchRecord{Case: name, Run: i + 1, BenchRun: run})
+		}
+		report.Cases = append(report.Cases, cache.Summarize(name, runs))
+	}
+	report.WarmSpeedup = speedup(report.Cases, cache.BenchWarm)
+	report.EditSpeedup = speedup(report.Cases, cache.BenchEdit)
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data:    report,
+		Records: records,
+		Table:   func(w io.Writer) error { return outputBenchTable(w, report) },
+	})
+}
+
+// speedup returns how many times faster the median build of a case was
+// than the median cold build, or zero if either was not run
+func speedup(cases []cache.BenchSummary, name string) float64 {
+	var cold, other float64
+	for _, c := range cases {
+		switch c.Case {
+		case cache.BenchCold:
+			cold = c.MedianSeconds
+		case name:
+			other = c.MedianSeconds
+		}
+	}
+	if cold == 0 || other == 0 {
+		return 0
+	}
+	return cold / other
+}
+
+func outputBenchTable(w io.Writer, report benchReport) error {
+	seconds := func(s float64) string {
+		return time.Duration(s * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Build Benchmark")
+		fmt.Fprintln(w, "===============")
+		fmt.Fprintf(w, "Package:  %s\n", report.Package)
+		fmt.Fprintf(w, "Runs:     %d per case\n", report.Count)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%-6s %10s %10s %10s %18s\n", "CASE", "MIN", "MEDIAN", "MEAN", "WRITTEN (MEDIAN)")
+	for _, c := range report.Cases {
+		fmt.Fprintf(w, "%-6s %10s %10s %10s %18s\n", c.Case, seconds(c.MinSeconds), seconds(c.MedianSeconds),
+			seconds(c.MeanSeconds), cache.FormatBytes(c.MedianBytesWritten))
+	}
+	if report.WarmSpeedup > 0 || report.EditSpeedup > 0 {
+		fmt.Fprintln(w)
+	}
+	if report.WarmSpeedup > 0 {
+		fmt.Fprintf(w, "A warm cache builds %.1fx faster than a cold one\n", report.WarmSpeedup)
+	}
+	if report.EditSpeedup > 0 {
+		fmt.Fprintf(w, "After an edit, the cache builds %.1fx faster than a cold one\n", report.EditSpeedup)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Fail when caches exceed size, age or module limits",
+	Long: `Compare the caches against thresholds and exit with a non-zero status
+when any is exceeded, so CI pipelines can fail fast or trigger a prune
+before a runner's disk fills up.
+
+Limits not given as flags are read from the thresholds section of the config
+file; a limit of zero or an empty one is not checked.
+- --max-total:   size of all caches together
+- --max-build:   size of each build cache root
+- --max-age:     age of the oldest entry in each cache with entry times
+- --max-modules: number of modules in each module cache root`,
+	Example: `  gocachectl check --max-total 15GB --max-build 10GB --max-age 30d --max-modules 2000
+  gocachectl check --profile ci            # Use the limits of the ci profile
+  gocachectl check --max-total 15GB --json # Machine-readable report
+  gocachectl check --max-build 10GB || gocachectl clear --build --force`,
+	RunE: runCheck,
+}
+
+// checkReport is the document written by check
+type checkReport struct {
+	Passed  bool                   `json:"passed"`
+	Results []cachemgr.CheckResult `json:"results"`
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().String("max-total", "", "maximum size of all caches together, e.g. 15GB")
+	checkCmd.Flags().String("max-build", "", "maximum size of each build cache root, e.g. 10GB")
+	checkCmd.Flags().String("max-age", "", "maximum age of the oldest cache entry, e.g. 30d")
+	checkCmd.Flags().Int("max-modules", 0, "maximum number of modules in each module cache root")
+
+	configFlags["max-total"] = "thresholds.max_total"
+	configFlags["max-build"] = "thresholds.max_build"
+	configFlags["max-age"] = "thresholds.max_age"
+	configFlags["max-modules"] = "thresholds.max_modules"
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	limits := cachemgr.Limits{
+		MaxTotal:   int64(cfg.Thresholds.MaxTotal),
+		MaxBuild:   int64(cfg.Thresholds.MaxBuild),
+		MaxAge:     time.Duration(cfg.Thresholds.MaxAge),
+		MaxModules: cfg.Thresholds.MaxModules,
+	}
+	if limits == (cachemgr.Limits{}) {
+		return fmt.Errorf("no thresholds set: use --max-total, --max-build, --max-age or --max-modules, or thresholds in the config file")
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+
+	stats, err := manager.GetStatsFor(allKinds(), nil)
+	if err != nil {
+		return err
+	}
+
+	report := checkReport{
+		Passed:  true,
+		Results: cachemgr.Check(stats, limits, time.Now()),
+	}
+	var failed int
+	for _, result := range report.Results {
+		if result.Exceeded {
+			failed++
+			report.Passed = false
+		}
+	}
+
+	err = render(cmd.OutOrStdout(), output, view{
+		Data:    report,
+		Records: report.Results,
+		Table:   func(w io.Writer) error { return outputCheckTable(w, report) },
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		// The report explains the failure; usage would only bury it
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d threshold checks failed", failed, len(report.Results))
+	}
+	return nil
+}
+
+func outputCheckTable(w io.Writer, report checkReport) error {
+	if !quiet {
+		fmt.Fprintln(w, "Cache Threshold Check")
+		fmt.Fprintln(w, "=====================")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%-12s %-26s %10s %10s   %s\n", "LIMIT", "CACHE", "MAX", "ACTUAL", "STATUS")
+	}
+
+	for _, result := range report.Results {
+		status := "ok"
+		if result.Exceeded {
+			status = "EXCEEDED"
+		} else if quiet {
+			continue
+		}
+		fmt.Fprintf(w, "%-12s %-26s %10s %10s   %s\n", result.Limit, checkedCache(result),
+			formatCheckValue(result.Unit, result.Max), formatCheckValue(result.Unit, result.Actual), status)
+	}
+
+	if !quiet {
+		fmt.Fprintln(w)
+		if report.Passed {
+			fmt.Fprintln(w, "All thresholds passed")
+		} else {
+			fmt.Fprintln(w, "Some thresholds were exceeded")
+		}
+	}
+	return nil
+}
+
+// checkedCache names the cache a result applies to
+func checkedCache(result cachemgr.CheckResult) string {
+	kind := cachemgr.Lookup(result.Kind)
+	if kind == nil {
+		return "all caches"
+	}
+	return sectionTitle(cachemgr.RootStats{Kind: kind, Root: cachemgr.Root{Label: result.Root}})
+}
+
+// formatCheckValue formats a limit or measured value in its unit
+func formatCheckValue(unit string, value int64) string {
+	switch unit {
+	case cachemgr.UnitBytes:
+		return cache.FormatBytes(value)
+	case cachemgr.UnitSeconds:
+		age := time.Duration(value) * time.Second
+		if age >= 24*time.Hour {
+			return fmt.Sprintf("%dd", age/(24*time.Hour))
+		}
+		return age.Truncate(time.Minute).String()
+	}
+	return cache.FormatCount(int(value))
+}
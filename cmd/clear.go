@@ -3,8 +3,9 @@ package cmd
 import (
 	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/muhammadali7768/gocachectl/internal/cache"
 	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
@@ -13,27 +14,82 @@ import (
 
 var (
 	clearAll    bool
-	clearBuild  bool
-	clearMod    bool
-	clearTest   bool
 	clearForce  bool
+	clearYes    bool
 	clearDryRun bool
+	clearRoots  []string
+	clearWait   time.Duration
+	// clearQuarantine is read through cfg.Clear.Quarantine, which it
+	// overrides
+	clearQuarantine bool
+
+	// clearKinds holds the per-kind selection flags, keyed by kind name
+	clearKinds map[string]*bool
 )
 
+// clearSchemaVersion versions the clear JSON document. It is raised when a
+// field is removed or changes meaning, not when one is added.
+const clearSchemaVersion = 1
+
+// clearReport is the JSON document written by clear
+type clearReport struct {
+	SchemaVersion int                                `json:"schema_version"`
+	DryRun        bool                               `json:"dry_run"`
+	Targets       []string                           `json:"targets"`
+	Roots         []string                           `json:"roots"` // labels given with --root; empty means every root
+	Before        []clearedCache                     `json:"before"`
+	Caches        map[string]*cache.CacheClearResult `json:"caches"` // keyed by kind; empty on a dry run
+	TotalFreed    int64                              `json:"total_freed"`
+	Errors        []cache.ClearFailure               `json:"errors"`
+	TrashID       string                             `json:"trash_id,omitempty"` // set when entries were quarantined
+}
+
+// clearedCache describes one cache root as it was before clearing
+type clearedCache struct {
+	Kind  string      `json:"kind"`
+	Root  string      `json:"root"`
+	Size  int64       `json:"size"`
+	Count int         `json:"count"`
+	Stats cache.Stats `json:"stats"`
+}
+
 var clearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear cache entries",
 	Long: `Clear Go cache entries. You can clear all caches or specific ones.
 
-By default, a confirmation prompt will be shown before deletion.
-Use --force to skip the confirmation prompt.
-Use --dry-run to see what would be deleted without actually deleting.`,
+Without cache flags, the clear.targets configured in the config file are
+cleared. By default, a confirmation prompt will be shown before deletion.
+Use --yes to skip the confirmation prompt.
+Use --dry-run to see what would be deleted without actually deleting.
+
+With --json or another machine-readable --output format, clear writes a
+versioned document with the stats before clearing, what was deleted and
+every path that could not be removed (see "gocachectl schema clear"). It
+cannot prompt then, so --yes, --force or --dry-run is required.
+
+With --quarantine, or clear.quarantine in the config file, entries are
+moved into a trash inside their cache instead of being deleted.
+"gocachectl undo" restores the last clear and "gocachectl trash empty"
+frees the space for good.
+
+The build and test caches are not cleared while go commands use them:
+clear refuses when it finds a running go command using the cache, or one
+holding the lock the go command takes when it trims the cache. Use --wait
+to wait for them to finish, or --force to clear regardless; --force also
+skips the confirmation prompt, and --wait takes precedence over it.`,
 	Example: `  gocachectl clear --all                 # Clear all caches (with confirmation)
   gocachectl clear --build               # Clear only build cache
   gocachectl clear --modules             # Clear only module cache
   gocachectl clear --test                # Clear only test cache
-  gocachectl clear --all --force         # Clear all without confirmation
-  gocachectl clear --all --dry-run       # Show what would be deleted`,
+  gocachectl clear --toolchains          # Clear downloaded Go toolchains
+  gocachectl clear --gopls --lint        # Clear gopls and golangci-lint caches
+  gocachectl clear --all --yes           # Clear all without confirmation
+  gocachectl clear --all --dry-run       # Show what would be deleted
+  gocachectl clear --build --root ci     # Clear only the build cache root labelled ci
+  gocachectl clear --modules --quarantine # Move the module cache to the trash
+  gocachectl clear --build --wait 5m     # Wait up to 5 minutes for running builds
+  gocachectl clear --build --yes --json  # Report the result as JSON`,
 	RunE: runClear,
 }
 
@@ -41,84 +97,91 @@ func init() {
 	rootCmd.AddCommand(clearCmd)
 
 	clearCmd.Flags().BoolVar(&clearAll, "all", false, "clear all caches")
-	clearCmd.Flags().BoolVar(&clearBuild, "build", false, "clear build cache")
-	clearCmd.Flags().BoolVar(&clearMod, "modules", false, "clear module cache")
-	clearCmd.Flags().BoolVar(&clearTest, "test", false, "clear test cache")
-	clearCmd.Flags().BoolVarP(&clearForce, "force", "f", false, "skip confirmation prompt")
+	clearCmd.Flags().BoolVarP(&clearYes, "yes", "y", false, "skip confirmation prompt")
+	clearCmd.Flags().BoolVarP(&clearForce, "force", "f", false, "skip confirmation prompt and clear even while go commands use the cache")
 	clearCmd.Flags().BoolVar(&clearDryRun, "dry-run", false, "show what would be deleted")
+	clearCmd.Flags().DurationVar(&clearWait, "wait", 0, "wait up to this long for go commands to stop using the cache")
+	clearCmd.Flags().StringArrayVar(&clearRoots, "root", nil, "clear only caches under the root with this label (repeatable)")
+	clearCmd.Flags().BoolVar(&clearQuarantine, "quarantine", false, "move entries into the trash instead of deleting them")
+	configFlags["quarantine"] = "clear.quarantine"
 }
 
 func runClear(cmd *cobra.Command, args []string) error {
-	// Validate flags
-	if !clearAll && !clearBuild && !clearMod && !clearTest {
-		return fmt.Errorf("must specify at least one cache to clear: --all, --build, --modules, or --test")
+	// Validate flags, falling back to the configured default targets
+	targets := selectedKinds(clearKinds)
+	if len(targets) == 0 {
+		targets = cfg.Clear.Targets
+	}
+	if !clearAll && len(targets) == 0 {
+		var flags []string
+		for _, kind := range cachemgr.Kinds() {
+			flags = append(flags, "--"+kind.Flag)
+		}
+		return fmt.Errorf("must specify at least one cache to clear: --all, %s", strings.Join(flags, ", "))
+	}
+	if clearAll {
+		targets = allKinds()
+	}
+	if output.machine() && !clearYes && !clearForce && !clearDryRun {
+		return fmt.Errorf("clear cannot prompt for confirmation with %s output: use --yes, --force or --dry-run", output.name)
 	}
 
 	// Create unified manager
-	manager, err := cachemgr.NewUnifiedManager()
+	manager, err := newUnifiedManager()
 	if err != nil {
-		return fmt.Errorf("failed to initialize cache manager: %w", err)
+		return err
+	}
+	if err := checkRoots(manager, clearRoots); err != nil {
+		return err
 	}
 
 	// Get current stats before clearing
-	var stats []cache.Stats
-	if !quiet {
-		stats, err = manager.GetAllStats()
+	var stats []cachemgr.RootStats
+	if !quiet || output.machine() {
+		stats, err = manager.GetStatsFor(targets, clearRoots)
 		if err != nil {
 			return fmt.Errorf("failed to get cache stats: %w", err)
 		}
 	}
 
+	report := newClearReport(targets, stats)
+	w := cmd.OutOrStdout()
+
 	// Show what will be cleared
-	if !quiet {
-		fmt.Println("Cache entries to be cleared:")
-		fmt.Println("============================")
-		fmt.Println()
+	if !quiet && !output.machine() {
+		fmt.Fprintln(w, "Cache entries to be cleared:")
+		fmt.Fprintln(w, "============================")
+		fmt.Fprintln(w)
 		totalSize := int64(0)
 		for _, stat := range stats {
-			switch stat := stat.(type) {
-			case *cache.BuildCacheStats:
-				if clearAll || clearBuild {
-					totalSize += stat.Size
-					fmt.Printf("Build Cache:  %s (%s entries)\n",
-						cache.FormatBytes(stat.Size),
-						cache.FormatCount(stat.EntryCount))
-				}
-			case *cache.ModCacheStats:
-				if clearAll || clearMod {
-					totalSize += stat.Size
-					fmt.Printf("Module Cache: %s (%s modules)\n",
-						cache.FormatBytes(stat.Size),
-						cache.FormatCount(stat.ModuleCount))
-				}
-			case *cache.TestCacheStats:
-				if clearAll || clearTest {
-					totalSize += stat.Size
-					fmt.Printf("Test Cache:   %s (%s entries)\n",
-						cache.FormatBytes(stat.Size),
-						cache.FormatCount(stat.EntryCount))
-				}
-			}
+			summary := stat.Kind.Summary(stat.Stats)
+			totalSize += summary.Size
+			fmt.Fprintf(w, "%-21s %s (%s %s)\n", sectionTitle(stat)+":",
+				cache.FormatBytes(summary.Size),
+				cache.FormatCount(summary.Count), stat.Kind.Unit)
 		}
 
-		fmt.Println()
-		fmt.Printf("Total to be cleared: %s\n", cache.FormatBytes(totalSize))
-		fmt.Println()
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Total to be cleared: %s\n", cache.FormatBytes(totalSize))
+		fmt.Fprintln(w)
 	}
 
 	// Dry run mode
 	if clearDryRun {
+		if output.machine() {
+			return outputClearReport(w, report)
+		}
 		if !quiet {
-			fmt.Println("[DRY RUN] No entries were deleted")
+			fmt.Fprintln(w, "[DRY RUN] No entries were deleted")
 		}
 		return nil
 	}
 
 	// Confirmation prompt
-	if !clearForce {
-		if !confirm("Are you sure you want to delete these caches?") {
+	if !clearYes && !clearForce {
+		if !confirm(cmd, "Are you sure you want to delete these caches?") {
 			if !quiet {
-				fmt.Println("Operation cancelled")
+				fmt.Fprintln(w, "Operation cancelled")
 			}
 			return nil
 		}
@@ -126,56 +189,153 @@ func runClear(cmd *cobra.Command, args []string) error {
 
 	// Prepare clear options
 	opts := cache.ClearOptions{
-		Build:   clearBuild,
-		Modules: clearMod,
-		Test:    clearTest,
-		All:     clearAll,
-		Force:   clearForce,
-		DryRun:  clearDryRun,
+		Targets:    targets,
+		Roots:      clearRoots,
+		All:        clearAll,
+		Force:      clearForce,
+		DryRun:     clearDryRun,
+		Quarantine: cfg.Clear.Quarantine,
+		Wait:       clearWait,
 	}
 
 	// Perform clearing
-	if !quiet {
-		fmt.Println("Clearing caches...")
+	if !quiet && !output.machine() {
+		if clearWait > 0 {
+			fmt.Fprintf(w, "Clearing caches, waiting up to %s for running go commands...\n", clearWait)
+		} else {
+			fmt.Fprintln(w, "Clearing caches...")
+		}
 	}
 
-	result, err := manager.Clear(opts)
-	if err != nil {
-		return fmt.Errorf("failed to clear caches: %w", err)
+	// Errors from Clear, like a failure to write the audit log, come after
+	// clearing and are returned once the result is shown
+	result, clearErr := manager.Clear(opts)
+	if clearErr != nil {
+		cmd.SilenceUsage = true
+	}
+
+	if output.machine() {
+		report.Caches = result.Caches
+		report.TotalFreed = result.TotalFreed
+		report.Errors = append(report.Errors, result.Failures...)
+		report.TrashID = result.TrashID
+		if err := outputClearReport(w, report); err != nil {
+			return err
+		}
+		return clearErr
 	}
 
 	// Show results
 	if !quiet {
-		fmt.Println()
-		fmt.Println("Results:")
-		fmt.Println("========")
-		fmt.Println()
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Results:")
+		fmt.Fprintln(w, "========")
+		fmt.Fprintln(w)
 
-		if clearAll || clearBuild {
-			fmt.Printf("Build Cache:  %s entries deleted\n", cache.FormatCount(result.BuildDeleted))
+		for _, kind := range cachemgr.Kinds() {
+			kindResult, ok := result.Caches[kind.Name]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "%-21s %s %s deleted\n", kind.Title+":",
+				cache.FormatCount(kindResult.Deleted), kind.Unit)
 		}
-		if clearAll || clearMod {
-			fmt.Printf("Module Cache: %s entries deleted\n", cache.FormatCount(result.ModulesDeleted))
+
+		fmt.Fprintln(w)
+		if result.TrashID != "" {
+			fmt.Fprintf(w, "Total quarantined: %s\n", cache.FormatBytes(result.TotalFreed))
+			fmt.Fprintln(w, "Run 'gocachectl undo' to restore it or 'gocachectl trash empty' to free the space")
+		} else {
+			fmt.Fprintf(w, "Total space freed: %s\n", cache.FormatBytes(result.TotalFreed))
 		}
-		if clearAll || clearTest {
-			fmt.Printf("Test Cache:   %s entries deleted\n", cache.FormatCount(result.TestDeleted))
+
+		if result.Errors > 0 {
+			fmt.Fprintf(w, "\n Warning: %d errors occurred during clearing\n", result.Errors)
+			// A few failures, like a cache in use, are worth showing anyway
+			if verbose || result.Errors <= maxShownFailures {
+				for _, failure := range result.Failures {
+					fmt.Fprintf(w, "   %s: %s\n", failure.Path, failure.Error)
+				}
+			}
 		}
+	}
 
-		fmt.Println()
-		fmt.Printf("Total space freed: %s\n", cache.FormatBytes(result.TotalFreed))
+	return clearErr
+}
 
-		if result.Errors > 0 {
-			fmt.Printf("\n Warning: %d errors occurred during clearing\n", result.Errors)
+// maxShownFailures is how many failures clear shows without --verbose
+const maxShownFailures = 3
+
+// newClearReport starts the JSON document for clearing targets
+func newClearReport(targets []string, stats []cachemgr.RootStats) *clearReport {
+	report := &clearReport{
+		SchemaVersion: clearSchemaVersion,
+		DryRun:        clearDryRun,
+		Targets:       targets,
+		Roots:         append([]string{}, clearRoots...),
+		Before:        make([]clearedCache, 0, len(stats)),
+		Caches:        make(map[string]*cache.CacheClearResult),
+		Errors:        []cache.ClearFailure{},
+	}
+
+	for _, stat := range stats {
+		summary := stat.Kind.Summary(stat.Stats)
+		report.Before = append(report.Before, clearedCache{
+			Kind:  stat.Kind.Name,
+			Root:  stat.Root.Label,
+			Size:  summary.Size,
+			Count: summary.Count,
+			Stats: stat.Stats,
+		})
+	}
+	return report
+}
+
+// clearRecord is the csv row of one cleared kind
+type clearRecord struct {
+	Kind    string `json:"kind"`
+	Size    int64  `json:"size"` // before clearing
+	Count   int    `json:"count"`
+	Deleted int    `json:"deleted"`
+	Freed   int64  `json:"freed"`
+	Errors  int    `json:"errors"`
+}
+
+// outputClearReport writes the clear document in a machine-readable format
+func outputClearReport(w io.Writer, report *clearReport) error {
+	var records []clearRecord
+	for _, kind := range cachemgr.Kinds() {
+		record := clearRecord{Kind: kind.Name}
+		found := false
+		for _, before := range report.Before {
+			if before.Kind == kind.Name {
+				record.Size += before.Size
+				record.Count += before.Count
+				found = true
+			}
+		}
+		if result, ok := report.Caches[kind.Name]; ok {
+			record.Deleted = result.Deleted
+			record.Freed = result.Freed
+			found = true
+		}
+		for _, failure := range report.Errors {
+			if failure.Kind == kind.Name {
+				record.Errors++
+			}
+		}
+		if found {
+			records = append(records, record)
 		}
 	}
 
-	return nil
+	return render(w, output, view{Data: report, Records: records})
 }
 
 // confirm prompts the user for confirmation
-func confirm(message string) bool {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s [y/N]: ", message)
+func confirm(cmd *cobra.Command, message string) bool {
+	reader := bufio.NewReader(cmd.InOrStdin())
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N]: ", message)
 
 	response, err := reader.ReadString('\n')
 	if err != nil {
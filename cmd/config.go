@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/muhammadali7768/gocachectl/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configInitForce bool
+
+// configDocument is the JSON document written by config show
+type configDocument struct {
+	ConfigFile string                 `json:"config_file"`
+	Profile    string                 `json:"profile"`
+	Values     map[string]configValue `json:"values"` // keyed by config key
+}
+
+// configRecord is the csv row of one config key
+type configRecord struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// configValue is an effective config value and where it comes from
+type configValue struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show, create and validate the configuration",
+	Long: `Inspect gocachectl's configuration.
+
+Settings are resolved from, in increasing precedence: built-in defaults, the
+config file, the profile selected with --profile, GOCACHECTL_* environment
+variables and command line flags.`,
+	// Config commands report configuration problems themselves
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each value comes from",
+	Example: `  gocachectl config show
+  gocachectl config show --profile ci
+  gocachectl config show -o yaml`,
+	RunE: runConfigShow,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented config file",
+	Example: `  gocachectl config init                       # Write $HOME/.gocachectl.yaml
+  gocachectl config init --config ./ci.yaml    # Write another file`,
+	RunE: runConfigInit,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for errors",
+	Example: `  gocachectl config validate
+  gocachectl config validate --profile ci`,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configInitCmd.Flags().BoolVarP(&configInitForce, "force", "f", false, "overwrite an existing config file")
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	// Only --output and --json select the format here, since the output
+	// key being shown would otherwise decide how it is shown
+	format := outputFormat{name: formatTable}
+	switch {
+	case cmd.Flags().Changed("output"):
+		var err error
+		if format, err = parseOutput(outputFlag); err != nil {
+			return err
+		}
+	case jsonOutput:
+		format = outputFormat{name: formatJSON}
+	}
+
+	if err := loadConfig(cmd); err != nil {
+		return err
+	}
+
+	file := viper.ConfigFileUsed()
+	if _, err := os.Stat(file); err != nil {
+		file = ""
+	}
+
+	doc := configDocument{
+		ConfigFile: file,
+		Profile:    profile,
+		Values:     make(map[string]configValue),
+	}
+	var records []configRecord
+	for _, key := range config.Keys() {
+		doc.Values[key] = configValue{Value: cfg.Value(key), Source: cfgSources[key]}
+		records = append(records, configRecord{Key: key, Value: formatConfigValue(cfg.Value(key)), Source: cfgSources[key]})
+	}
+
+	return render(cmd.OutOrStdout(), format, view{
+		Data:    doc,
+		Records: records,
+		Table: func(w io.Writer) error {
+			if !quiet {
+				if file == "" {
+					file = "none"
+				}
+				fmt.Fprintf(w, "Config file:  %s\n", file)
+				if profile != "" {
+					fmt.Fprintf(w, "Profile:      %s\n", profile)
+				}
+				fmt.Fprintln(w)
+			}
+
+			for _, record := range records {
+				fmt.Fprintf(w, "%-32s %-28s %s\n", record.Key, record.Value, record.Source)
+			}
+			return nil
+		},
+	})
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	path := cfgFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to find home directory: %w", err)
+		}
+		path = filepath.Join(home, ".gocachectl.yaml")
+	}
+
+	if _, err := os.Stat(path); err == nil && !configInitForce {
+		return fmt.Errorf("config file %s already exists (use --force to overwrite)", path)
+	}
+
+	if err := os.WriteFile(path, []byte(config.Template), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if !quiet {
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+	}
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	if err := loadConfig(cmd); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// Every profile must be valid on its own, not just the selected one
+	for name := range viper.GetStringMap("profiles") {
+		if name == profile {
+			continue
+		}
+		v := viper.New()
+		config.SetDefaults(v)
+		if file := viper.ConfigFileUsed(); file != "" {
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err != nil {
+				return fmt.Errorf("failed to read config file: %w", err)
+			}
+		}
+		profileCfg, _, err := config.Load(v, config.Options{Profile: name})
+		if err == nil {
+			err = profileCfg.Validate()
+		}
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid")
+	}
+	return nil
+}
+
+// formatConfigValue formats a config value for the show table
+func formatConfigValue(value any) string {
+	switch value := value.(type) {
+	case []string:
+		return "[" + strings.Join(value, ", ") + "]"
+	case config.ByteSize:
+		if value == 0 {
+			return "-"
+		}
+	case config.Age:
+		if value == 0 {
+			return "-"
+		}
+	}
+	return fmt.Sprint(value)
+}
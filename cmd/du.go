@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	duModules bool
+	duDepth   int
+	duTop     int
+	duSort    string
+	duRoots   []string
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du [prefix]",
+	Short: "Show a disk usage tree of the module cache",
+	Long: `Break the module cache down by module path segment: host, then
+organisation, then repository and finally each version, like du but aware
+of the module cache's path@version layout.
+
+Sizes include each version's extracted files and its downloaded zip and
+metadata. Only versions extracted into the cache are shown, not go.mod
+files downloaded just to resolve versions. Give a module path prefix such
+as k8s.io or github.com/spf13 to start the tree there.`,
+	Example: `  gocachectl du --modules                    # Whole tree
+  gocachectl du --modules --depth 1          # Size per host
+  gocachectl du --modules --depth 2 --top 5  # Five largest orgs per host
+  gocachectl du --modules k8s.io             # Everything from k8s.io
+  gocachectl du --modules --sort versions    # Paths with most versions first
+  gocachectl du --modules --depth 2 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDu,
+}
+
+// duRecord is the csv row of one tree node
+type duRecord struct {
+	Path     string `json:"path"`
+	Depth    int    `json:"depth"`
+	Size     int64  `json:"size"`
+	Versions int    `json:"versions"`
+	Omitted  int    `json:"omitted"`
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+
+	duCmd.Flags().BoolVar(&duModules, "modules", false, "show the module cache (the only cache du supports, and the default)")
+	duCmd.Flags().IntVar(&duDepth, "depth", 0, "levels to show below the prefix (0 for all)")
+	duCmd.Flags().IntVar(&duTop, "top", 0, "show only the N largest entries per level (0 for all)")
+	duCmd.Flags().StringVar(&duSort, "sort", cache.SortBySize, "sort order: size, name or versions")
+	duCmd.Flags().StringArrayVar(&duRoots, "root", nil, "show only module cache roots with this label (repeatable)")
+}
+
+func runDu(cmd *cobra.Command, args []string) error {
+	if !slices.Contains([]string{cache.SortBySize, cache.SortByName, cache.SortByVersions}, duSort) {
+		return fmt.Errorf("invalid --sort %q: want size, name or versions", duSort)
+	}
+	if duDepth < 0 || duTop < 0 {
+		return fmt.Errorf("--depth and --top must not be negative")
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	if err := checkRoots(manager, duRoots); err != nil {
+		return err
+	}
+
+	var entries []cache.Entry
+	for _, c := range manager.Browsable([]string{"module"}) {
+		if len(duRoots) > 0 && !slices.Contains(duRoots, c.Root.Label) {
+			continue
+		}
+		rootEntries, err := c.Lister.ListEntries()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, rootEntries...)
+	}
+
+	tree := cache.ModuleUsage(entries)
+	if len(args) > 0 {
+		tree = tree.Find(args[0])
+		if tree == nil {
+			return fmt.Errorf("no modules under %s in the module cache", args[0])
+		}
+	}
+	tree.Sort(duSort)
+	tree.Prune(duDepth, duTop)
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data:    tree,
+		Records: duRecords(tree),
+		Table:   func(w io.Writer) error { return outputDuTree(w, tree) },
+	})
+}
+
+// duRecords flattens the tree in display order
+func duRecords(tree *cache.UsageNode) []duRecord {
+	var records []duRecord
+	var walk func(node *cache.UsageNode, depth int)
+	walk = func(node *cache.UsageNode, depth int) {
+		records = append(records, duRecord{
+			Path:     node.Path,
+			Depth:    depth,
+			Size:     node.Size,
+			Versions: node.Versions,
+			Omitted:  node.Omitted,
+		})
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(tree, 0)
+	return records
+}
+
+func outputDuTree(w io.Writer, tree *cache.UsageNode) error {
+	var walk func(node *cache.UsageNode, depth int)
+	walk = func(node *cache.UsageNode, depth int) {
+		indent := strings.Repeat("  ", depth)
+		name := node.Path
+		if depth > 0 {
+			name = node.Name
+			if node.IsVersion() {
+				name = "@" + name
+			}
+		}
+		if name == "" {
+			name = "(module cache)"
+		}
+
+		versions := ""
+		switch {
+		case node.IsVersion():
+		case node.Versions == 1:
+			versions = "1 version"
+		default:
+			versions = fmt.Sprintf("%s versions", cache.FormatCount(node.Versions))
+		}
+		fmt.Fprintf(w, "%10s  %16s  %s%s\n", cache.FormatBytes(node.Size), versions, indent, name)
+
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+		if len(node.Children) > 0 && node.Omitted > 0 {
+			fmt.Fprintf(w, "%10s  %16s  %s  ... %s more\n", cache.FormatBytes(node.OmittedSize), "", indent, cache.FormatCount(node.Omitted))
+		}
+	}
+	walk(tree, 0)
+	return nil
+}
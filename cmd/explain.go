@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainBaseline string
+	explainRecord   string
+)
+
+// Verdicts of explain
+const (
+	verdictHit      = "hit"      // the baseline's action ID was cached and is unchanged
+	verdictEvicted  = "evicted"  // the action ID is unchanged but its output was no longer cached
+	verdictRebuilt  = "rebuilt"  // inputs changed since the baseline
+	verdictStable   = "stable"   // consecutive builds hash the same inputs
+	verdictUnstable = "unstable" // consecutive builds hash different inputs, so every build misses
+)
+
+// explainReport is the document written by explain
+type explainReport struct {
+	Package string `json:"package"`
+	Action  string `json:"action"`
+	// Baseline is when the compared baseline was recorded; zero when two
+	// consecutive builds were compared
+	Baseline time.Time `json:"baseline,omitzero"`
+	OldID    string    `json:"old_id"`
+	NewID    string    `json:"new_id"`
+	// Cached reports whether the baseline's action was in the build cache
+	// before the build; always false without a baseline
+	Cached  bool                `json:"cached"`
+	Verdict string              `json:"verdict"`
+	Changes []cache.InputChange `json:"changes"`
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <package> [-- build flags]",
+	Short: "Explain why a package missed the build cache",
+	Long: `Explain why the go command rebuilt a package instead of using the build
+cache. The package is built under GODEBUG=gocachehash=1, which makes the go
+command print every input it hashes into the package's action ID: the
+toolchain, source files, environment, flags and the IDs of dependencies.
+
+Without --baseline, the package is built twice in a row and the inputs of
+both builds are compared. Inputs that differ between them, like a generated
+file or a flag carrying a timestamp, make every build miss.
+
+With --record, the inputs of a build are saved to a file; a later explain
+with --baseline compares the next build against them and reports which
+inputs changed. A changed dependency is followed into that dependency, down
+to the inputs that changed first.
+
+The build uses the go command, GOCACHE and environment of the current
+directory. Build flags after -- are passed to go build.`,
+	Example: `  gocachectl explain ./pkg/foo                        # Does it rebuild every time?
+  gocachectl explain ./pkg/foo --record foo.hash      # Record the inputs now
+  gocachectl explain ./pkg/foo --baseline foo.hash    # Why did it rebuild since?
+  gocachectl explain ./cmd/app -- -tags integration   # Pass build flags`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringVar(&explainBaseline, "baseline", "", "compare against the inputs recorded in this file")
+	explainCmd.Flags().StringVar(&explainRecord, "record", "", "record the inputs of this build to this file")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	var flags []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		args, flags = args[:dash], args[dash:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("explain needs exactly one package, got %d", len(args))
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	pkg, err := cache.ImportPath(dir, args[0])
+	if err != nil {
+		return err
+	}
+	report := explainReport{Package: pkg, Action: "build " + pkg}
+
+	var old cache.HashTrace
+	if explainBaseline != "" {
+		baseline, err := readBaseline(explainBaseline, pkg)
+		if err != nil {
+			return err
+		}
+		old, report.Baseline = baseline.Actions, baseline.Recorded
+		if action := old[report.Action]; action != nil {
+			build, err := cache.NewBuildManager("")
+			if err != nil {
+				return fmt.Errorf("failed to initialize build cache manager: %w", err)
+			}
+			report.Cached = build.HasAction(action.ID)
+		}
+	} else if explainRecord == "" {
+		if old, err = cache.TraceBuild(dir, args[0], flags); err != nil {
+			return err
+		}
+	}
+
+	current, err := cache.TraceBuild(dir, args[0], flags)
+	if err != nil {
+		return err
+	}
+	if current[report.Action] == nil {
+		return fmt.Errorf("the build of %s hashed no action %q", args[0], report.Action)
+	}
+	report.NewID = current[report.Action].ID
+
+	if explainRecord != "" {
+		if err := writeBaseline(explainRecord, cache.HashBaseline{Package: pkg, Recorded: time.Now(), Actions: current}); err != nil {
+			return err
+		}
+		if old == nil {
+			if !output.machine() && !quiet {
+				fmt.Fprintf(cmd.OutOrStdout(), "Recorded the inputs of %d actions for %s to %s\n", len(current), pkg, explainRecord)
+			}
+			return nil
+		}
+	}
+
+	if action := old[report.Action]; action != nil {
+		report.OldID = action.ID
+	}
+	report.Changes = cache.Diff(old, current, report.Action)
+	report.Verdict = explainVerdict(report)
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data:    report,
+		Records: report.Changes,
+		Table:   func(w io.Writer) error { return outputExplainTable(w, report) },
+	})
+}
+
+// explainVerdict sums up why the build did or did not miss
+func explainVerdict(report explainReport) string {
+	unchanged := report.OldID == report.NewID
+	switch {
+	case report.Baseline.IsZero() && unchanged:
+		return verdictStable
+	case report.Baseline.IsZero():
+		return verdictUnstable
+	case unchanged && report.Cached:
+		return verdictHit
+	case unchanged:
+		return verdictEvicted
+	}
+	return verdictRebuilt
+}
+
+// readBaseline reads a baseline recorded for pkg
+func readBaseline(path, pkg string) (*cache.HashBaseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline cache.HashBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Package != pkg {
+		return nil, fmt.Errorf("baseline %s was recorded for %s, not %s", path, baseline.Package, pkg)
+	}
+	return &baseline, nil
+}
+
+func writeBaseline(path string, baseline cache.HashBaseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+func outputExplainTable(w io.Writer, report explainReport) error {
+	if !quiet {
+		fmt.Fprintf(w, "Package:   %s\n", report.Package)
+		if report.Baseline.IsZero() {
+			fmt.Fprintln(w, "Compared:  two consecutive builds")
+		} else {
+			fmt.Fprintf(w, "Compared:  baseline recorded %s\n", report.Baseline.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Fprintf(w, "Action ID: %s\n", shortID(report.NewID))
+		if report.OldID != report.NewID {
+			fmt.Fprintf(w, "Was:       %s\n", shortID(report.OldID))
+		}
+		fmt.Fprintln(w)
+	}
+
+	switch report.Verdict {
+	case verdictHit:
+		fmt.Fprintln(w, "Cache hit: no input changed and the output is still cached")
+	case verdictEvicted:
+		fmt.Fprintln(w, "Rebuilt: no input changed, but the output was no longer in the build cache (trimmed or cleared)")
+	case verdictStable:
+		fmt.Fprintln(w, "Stable: consecutive builds hash the same inputs, so it rebuilds only when one changes")
+		if !quiet {
+			fmt.Fprintln(w, "Record a baseline with --record to find out which one next time")
+		}
+	case verdictUnstable:
+		fmt.Fprintf(w, "Rebuilds every time: %d inputs differ between consecutive builds\n", len(report.Changes))
+	default:
+		fmt.Fprintf(w, "Rebuilt: %d inputs changed since the baseline\n", len(report.Changes))
+	}
+	if len(report.Changes) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	if verbose {
+		action := ""
+		for _, change := range report.Changes {
+			if change.Action != action {
+				action = change.Action
+				fmt.Fprintf(w, "%s:\n", action)
+			}
+			fmt.Fprintf(w, "   %-10s %-32s %s\n", change.Kind, change.Key, describeChange(change))
+		}
+		return nil
+	}
+
+	// Without --verbose, changes of dependencies are condensed to the
+	// inputs that changed first, each listed once
+	fmt.Fprintf(w, "%s:\n", report.Action)
+	var causes []cache.InputChange
+	packages := make(map[cache.InputChange]int)
+	for _, change := range report.Changes {
+		if change.Action == report.Action {
+			fmt.Fprintf(w, "   %-10s %-32s %s\n", change.Kind, change.Key, describeChange(change))
+			continue
+		}
+		if change.Kind == cache.InputDependency {
+			continue
+		}
+		cause := change
+		cause.Action = ""
+		if change.Kind == cache.InputSource {
+			cause.Action = strings.TrimPrefix(change.Action, "build ")
+		}
+		if packages[cause] == 0 {
+			causes = append(causes, cause)
+		}
+		packages[cause]++
+	}
+	if len(causes) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "Changed in dependencies:")
+	for _, cause := range causes {
+		key := cause.Key
+		if cause.Action != "" {
+			key = cause.Action + "/" + key
+		}
+		where := ""
+		if n := packages[cause]; n > 1 {
+			where = fmt.Sprintf(" (%d packages)", n)
+		}
+		fmt.Fprintf(w, "   %-10s %-32s %s%s\n", cause.Kind, key, describeChange(cause), where)
+	}
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Use --verbose to list the changes of every package")
+	}
+	return nil
+}
+
+// describeChange shows the old and new value of an input, shortened
+func describeChange(change cache.InputChange) string {
+	switch {
+	case change.Old == "":
+		return "added: " + truncate(change.New, 40)
+	case change.New == "":
+		return "removed: " + truncate(change.Old, 40)
+	}
+	return truncate(change.Old, 30) + " -> " + truncate(change.New, 30)
+}
+
+// shortID shortens an action ID for display
+func shortID(id string) string {
+	if id == "" {
+		return "none"
+	}
+	return id[:min(12, len(id))]
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
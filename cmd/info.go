@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/muhammadali7768/gocachectl/internal/cache"
 	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
@@ -13,12 +13,12 @@ var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show cache location and environment information",
 	Long: `Display information about Go cache locations and environment:
-- GOCACHE location
-- GOMODCACHE location
-- Go version
-- Cache availability status`,
+- the location of every cache, at each configured root
+- whether each cache exists
+- Go version`,
 	Example: `  gocachectl info
-  gocachectl info --json`,
+  gocachectl info --json
+  gocachectl info -o yaml`,
 	RunE: runInfo,
 }
 
@@ -28,9 +28,9 @@ func init() {
 
 func runInfo(cmd *cobra.Command, args []string) error {
 	// Create unified manager
-	manager, err := cachemgr.NewUnifiedManager()
+	manager, err := newUnifiedManager()
 	if err != nil {
-		return fmt.Errorf("failed to initialize cache manager: %w", err)
+		return err
 	}
 
 	// Get cache info
@@ -39,53 +39,48 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get cache info: %w", err)
 	}
 
-	if jsonOutput {
-		return outputInfoJSON(cmd, info)
-	}
-
-	return outputInfoHuman(info)
-}
-
-func outputInfoJSON(cmd *cobra.Command, info *cache.CacheInfo) error {
-	encoder := json.NewEncoder(cmd.OutOrStdout())
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(info)
+	return render(cmd.OutOrStdout(), output, view{
+		Data:  info,
+		Table: func(w io.Writer) error { return outputInfoHuman(w, info) },
+	})
 }
 
-func outputInfoHuman(info *cache.CacheInfo) error {
+func outputInfoHuman(w io.Writer, info *cache.CacheInfo) error {
 	if !quiet {
-		fmt.Println("Go Cache Information")
-		fmt.Println("====================")
-		fmt.Println()
+		fmt.Fprintln(w, "Go Cache Information")
+		fmt.Fprintln(w, "====================")
+		fmt.Fprintln(w)
 	}
 
 	// Go Version
-	fmt.Printf("Go Version:       %s\n", info.GoVersion)
-	fmt.Println()
-
-	// Build Cache
-	fmt.Println("Build Cache (GOCACHE):")
-	fmt.Printf("   Location:      %s\n", info.GOCACHE)
-	if info.BuildCacheOK {
-		fmt.Printf("   Status:        ✓ Available\n")
-	} else {
-		fmt.Printf("   Status:        ✗ Not available\n")
-	}
-	fmt.Println()
+	fmt.Fprintf(w, "Go Version:       %s\n", info.GoVersion)
+	fmt.Fprintln(w)
 
-	// Module Cache
-	fmt.Println("Module Cache (GOMODCACHE):")
-	fmt.Printf("   Location:      %s\n", info.GOMODCACHE)
-	if info.ModCacheOK {
-		fmt.Printf("   Status:        ✓ Available\n")
-	} else {
-		fmt.Printf("   Status:        ✗ Not available\n")
+	// One section per cache, titled by its kind
+	for i, root := range info.Roots {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		title, env := root.Kind, root.Env
+		if kind := cachemgr.Lookup(root.Kind); kind != nil {
+			title = kind.Title
+		}
+		if root.Label != cachemgr.DefaultLabel {
+			env += ", " + root.Label
+		}
+		fmt.Fprintf(w, "%s (%s):\n", title, env)
+		fmt.Fprintf(w, "   Location:      %s\n", root.Path)
+		if root.Available {
+			fmt.Fprintf(w, "   Status:        ✓ Available\n")
+		} else {
+			fmt.Fprintf(w, "   Status:        ✗ Not available\n")
+		}
 	}
 
 	if verbose {
-		fmt.Println()
-		fmt.Println("Note: Test cache is part of the build cache.")
-		fmt.Println("Use 'gocachectl stats' to see cache sizes.")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Note: Test cache is part of the build cache.")
+		fmt.Fprintln(w, "Use 'gocachectl stats' to see cache sizes.")
 	}
 
 	return nil
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/muhammadali7768/gocachectl/internal/query"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsWhere  string
+	lsSort   string
+	lsLimit  int
+	lsDelete bool
+	lsForce  bool
+	lsYes    bool
+	lsWait   time.Duration
+	lsRoots  []string
+
+	// lsKinds holds the per-kind selection flags, keyed by kind name
+	lsKinds map[string]*bool
+)
+
+// lsFields are the fields --where and --sort can use
+var lsFields = query.Fields{
+	"cache":     query.String,
+	"root":      query.String,
+	"kind":      query.String,
+	"name":      query.String,
+	"path":      query.String,
+	"size":      query.Size,
+	"age":       query.Age,
+	"toolchain": query.Version,
+	"platform":  query.String,
+	"package":   query.String,
+	"module":    query.String,
+	"version":   query.Semver,
+}
+
+// lsDeleteReport is the document written by ls --delete
+type lsDeleteReport struct {
+	Entries    []cachemgr.ListedEntry             `json:"entries"` // the entries selected for removal
+	Caches     map[string]*cache.CacheClearResult `json:"caches"`  // keyed by kind
+	TotalFreed int64                              `json:"total_freed"`
+	Errors     []cache.ClearFailure               `json:"errors"`
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cache entries, filtered by an expression",
+	Long: `List the individual entries of the build, test and module caches: build
+action files and outputs with the toolchain and platform that produced
+them, test results with the package they tested, and module versions.
+Every entry has a kind:
+
+  action    a build action file, naming the output of a compile or link
+  archive   a compiled package
+  binary    a linked executable
+  output    any other build output: vet results, cgo files, go list data
+  test      a cached test result
+  module    an extracted module version
+
+--where keeps the entries an expression matches. Comparisons put a field
+on the left and a value on the right, with ==, !=, <, <=, >, >=, and =~
+or !~ for regular expressions; they combine with &&, || and ! and group
+with parentheses. Fields are cache, root, kind, name, path, size, age,
+toolchain, platform, package, module and version. Sizes take units such
+as 5MB, ages units such as 12h, 30d or 2w, and toolchains compare as Go
+releases, so toolchain<go1.24 matches go1.23.4 but not go1.24rc1.
+
+--sort orders by a comma-separated list of fields, each descending when
+prefixed with -, and --limit keeps the first entries after sorting.
+
+With --delete, exactly the listed entries are removed, through the same
+managers as clear and prune, after a confirmation unless --yes or --force
+is given. Like clear, ls --delete refuses while go commands use the build
+or test cache unless given --wait or --force, and --wait takes precedence.
+
+With --output ndjson, entries are written one JSON object per line.`,
+	Example: `  gocachectl ls --build --where 'kind=="archive" && age>30d && size>5MB'
+  gocachectl ls --sort -size --limit 20           # Largest entries
+  gocachectl ls --test --where 'package=~"^example.com/"'
+  gocachectl ls --build --where 'toolchain<go1.24' --delete
+  gocachectl ls --modules --where 'module=~"k8s.io"' -o ndjson | jq .version`,
+	Args: cobra.NoArgs,
+	RunE: runLs,
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+
+	lsCmd.Flags().StringVar(&lsWhere, "where", "", "list only entries matching this expression, e.g. 'kind==\"archive\" && age>30d'")
+	lsCmd.Flags().StringVar(&lsSort, "sort", "", "sort by these comma-separated fields, - for descending, e.g. -size,name")
+	lsCmd.Flags().IntVar(&lsLimit, "limit", 0, "list at most this many entries (0 for all)")
+	lsCmd.Flags().BoolVar(&lsDelete, "delete", false, "remove the listed entries")
+	lsCmd.Flags().BoolVarP(&lsYes, "yes", "y", false, "skip confirmation prompt")
+	lsCmd.Flags().BoolVarP(&lsForce, "force", "f", false, "skip confirmation prompt and delete even while go commands use the cache")
+	lsCmd.Flags().DurationVar(&lsWait, "wait", 0, "wait up to this long for go commands to stop using the cache")
+	lsCmd.Flags().StringArrayVar(&lsRoots, "root", nil, "list only caches under the root with this label (repeatable)")
+}
+
+// lsRecord exposes the fields of a listed entry to expressions
+type lsRecord struct {
+	entry *cachemgr.ListedEntry
+	now   time.Time
+}
+
+func (r lsRecord) Field(name string) any {
+	e := r.entry
+	switch name {
+	case "cache":
+		return e.Cache
+	case "root":
+		return e.Root
+	case "kind":
+		return e.Kind
+	case "name":
+		return e.Name
+	case "path":
+		return e.Path
+	case "size":
+		return e.Size
+	case "age":
+		return r.now.Sub(e.ModTime)
+	case "toolchain":
+		return e.Toolchain
+	case "platform":
+		return e.Platform
+	case "package":
+		return e.Package
+	case "module":
+		return e.Module
+	case "version":
+		return e.Version
+	}
+	return nil
+}
+
+// queryOrder returns the comparison sorting records by spec, a
+// comma-separated list of fields each descending when prefixed with -
+func queryOrder(fields query.Fields, spec string) (func(a, b query.Record) int, error) {
+	var keys []func(a, b query.Record) int
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		cmp, err := query.Compare(fields, strings.TrimPrefix(field, "-"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --sort: %w", err)
+		}
+		if strings.HasPrefix(field, "-") {
+			ascending := cmp
+			cmp = func(a, b query.Record) int { return ascending(b, a) }
+		}
+		keys = append(keys, cmp)
+	}
+	return func(a, b query.Record) int {
+		for _, cmp := range keys {
+			if c := cmp(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}, nil
+}
+
+func runLs(cmd *cobra.Command, args []string) error {
+	if lsLimit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	now := time.Now()
+	var where *query.Expr
+	if lsWhere != "" {
+		var err error
+		if where, err = query.Parse(lsWhere, lsFields); err != nil {
+			return fmt.Errorf("invalid --where: %w", err)
+		}
+	}
+	var order func(a, b query.Record) int
+	if lsSort != "" {
+		var err error
+		if order, err = queryOrder(lsFields, lsSort); err != nil {
+			return err
+		}
+	}
+	if lsDelete && output.machine() && !lsYes && !lsForce {
+		return fmt.Errorf("ls --delete cannot prompt for confirmation with %s output: use --yes or --force", output.name)
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	if err := checkRoots(manager, lsRoots); err != nil {
+		return err
+	}
+
+	kinds := selectedKinds(lsKinds)
+	if len(kinds) == 0 {
+		kinds = allKinds()
+	}
+	for _, kind := range selectedKinds(lsKinds) {
+		if !slices.ContainsFunc(manager.Browsable(kinds), func(c cachemgr.BrowsableCache) bool { return c.Kind.Name == kind }) {
+			return fmt.Errorf("the %s cannot be listed entry by entry", cachemgr.Lookup(kind).Description)
+		}
+	}
+	entries, err := manager.ListEntries(kinds, lsRoots)
+	if err != nil {
+		return err
+	}
+	if where != nil {
+		entries = slices.DeleteFunc(entries, func(e cachemgr.ListedEntry) bool { return !where.Match(lsRecord{&e, now}) })
+	}
+	if order != nil {
+		slices.SortStableFunc(entries, func(a, b cachemgr.ListedEntry) int {
+			return order(lsRecord{&a, now}, lsRecord{&b, now})
+		})
+	}
+	if lsLimit > 0 && len(entries) > lsLimit {
+		entries = entries[:lsLimit]
+	}
+	if entries == nil {
+		entries = []cachemgr.ListedEntry{}
+	}
+
+	w := cmd.OutOrStdout()
+	if !lsDelete {
+		return render(w, output, view{
+			Data:  entries,
+			Table: func(w io.Writer) error { return outputLsTable(w, entries, now) },
+		})
+	}
+
+	if !output.machine() {
+		if err := outputLsTable(w, entries, now); err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if !lsYes && !lsForce {
+			fmt.Fprintln(w)
+			if !confirm(cmd, fmt.Sprintf("Are you sure you want to delete these %s entries?", cache.FormatCount(len(entries)))) {
+				if !quiet {
+					fmt.Fprintln(w, "Operation cancelled")
+				}
+				return nil
+			}
+		}
+	}
+
+	concurrency := cache.Concurrency{Mode: cache.Refuse}
+	switch {
+	case lsWait > 0:
+		concurrency = cache.Concurrency{Mode: cache.Wait, Timeout: lsWait}
+	case lsForce:
+		concurrency.Mode = cache.Force
+	}
+	result, err := manager.RemoveListed(entries, concurrency)
+	if err != nil {
+		return err
+	}
+	report := lsDeleteReport{
+		Entries:    entries,
+		Caches:     result.Caches,
+		TotalFreed: result.TotalFreed,
+		Errors:     append([]cache.ClearFailure{}, result.Failures...),
+	}
+	var deleteErr error
+	if len(report.Errors) > 0 {
+		cmd.SilenceUsage = true
+		deleteErr = fmt.Errorf("%d entries could not be removed", len(report.Errors))
+	}
+
+	if output.machine() {
+		if err := render(w, output, view{Data: report, Records: entries}); err != nil {
+			return err
+		}
+		return deleteErr
+	}
+	if !quiet {
+		deleted := 0
+		for _, result := range report.Caches {
+			deleted += result.Deleted
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Removed %s entries, %s freed\n", cache.FormatCount(deleted), cache.FormatBytes(report.TotalFreed))
+		if len(report.Errors) > 0 && (verbose || len(report.Errors) <= maxShownFailures) {
+			for _, failure := range report.Errors {
+				fmt.Fprintf(w, "   %s: %s\n", failure.Path, failure.Error)
+			}
+		}
+	}
+	return deleteErr
+}
+
+// outputLsTable writes one line per entry, with a ROOT column when the
+// entries come from more than one root
+func outputLsTable(w io.Writer, entries []cachemgr.ListedEntry, now time.Time) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No matching entries")
+		return nil
+	}
+	multiRoot := slices.ContainsFunc(entries, func(e cachemgr.ListedEntry) bool { return e.Root != entries[0].Root })
+
+	rootHeader := ""
+	if multiRoot {
+		rootHeader = fmt.Sprintf("%-10s ", "ROOT")
+	}
+	fmt.Fprintf(w, "%-6s %s%-8s %10s %5s  %-10s %-14s %s\n", "CACHE", rootHeader, "KIND", "SIZE", "AGE", "TOOLCHAIN", "PLATFORM", "NAME")
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+		root := ""
+		if multiRoot {
+			root = fmt.Sprintf("%-10s ", e.Root)
+		}
+		name := e.Name
+		if e.Package != "" {
+			name = e.Package
+		}
+		fmt.Fprintf(w, "%-6s %s%-8s %10s %5s  %-10s %-14s %s\n", e.Cache, root, e.Kind,
+			cache.FormatBytes(e.Size), shortAge(now.Sub(e.ModTime)), orDash(e.Toolchain), orDash(e.Platform), name)
+	}
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s entries, %s\n", cache.FormatCount(len(entries)), cache.FormatBytes(total))
+	}
+	return nil
+}
+
+// shortAge formats an age in its largest whole unit of minutes, hours or
+// days
+func shortAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", max(d, 0)/time.Minute)
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var measureIndex string
+
+// measureReport is the document written by measure
+type measureReport struct {
+	Command  []string `json:"command"`
+	ExitCode int      `json:"exit_code"`
+	// WallSeconds is how long the command ran
+	WallSeconds float64 `json:"wall_seconds"`
+	cache.CacheEffect
+}
+
+var measureCmd = &cobra.Command{
+	Use:   "measure -- <command> [args...]",
+	Short: "Measure how well one go command used the build and test cache",
+	Long: `Run a command, usually a go build or go test, and report how well it used
+the build and test cache: how many packages were found compiled in the
+cache and how many had to be compiled, how many test results were replayed
+as "(cached)", and how many entries and bytes were added to the cache.
+
+The command runs with GODEBUG=gocachehash=1, which makes the go command
+print the action ID of every package it builds; measure reads those from
+the command's stderr, so they are not shown. The cache is snapshotted before
+and after the command to find what was added. The command's output is
+passed through, to stderr when --output selects a machine format so that
+the report stays parseable.
+
+With --index, the action ID of every package the command built, and the
+inputs hashed into it, are appended to an action index file, which thrash
+reads to find packages rebuilt on every run and the inputs that vary. The
+index keeps the last 30 runs.
+
+measure exits with the command's status, after reporting.`,
+	Example: `  gocachectl measure -- go test ./...
+  gocachectl measure -- go build ./cmd/app
+  gocachectl measure --json -- go test ./... > cache-effect.json   # Track hit rates in CI
+  gocachectl measure -o template='{{.Hits}}/{{.Actions}}' -- go build ./...
+  gocachectl measure --index ci-actions.jsonl -- go build ./...   # Record for thrash`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMeasure,
+}
+
+func init() {
+	rootCmd.AddCommand(measureCmd)
+
+	// Flags after the command belong to it
+	measureCmd.Flags().SetInterspersed(false)
+	measureCmd.Flags().StringVar(&measureIndex, "index", "", "append the action IDs of the command's builds to this action index")
+}
+
+func runMeasure(cmd *cobra.Command, args []string) error {
+	build, err := cache.NewBuildManager("")
+	if err != nil {
+		return fmt.Errorf("failed to initialize build cache manager: %w", err)
+	}
+	before, err := build.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+
+	report := measureReport{Command: args}
+	stdout := cmd.OutOrStdout()
+	if output.machine() {
+		stdout = cmd.ErrOrStderr()
+	}
+
+	child := exec.Command(args[0], args[1:]...)
+	child.Stdin = cmd.InOrStdin()
+	godebug := "gocachehash=1"
+	if current := os.Getenv("GODEBUG"); current != "" {
+		godebug = current + "," + godebug
+	}
+	child.Env = append(os.Environ(), "GODEBUG="+godebug)
+
+	// Test results are counted from stdout as it passes through; hash lines
+	// are kept from stderr and the rest passed through
+	var hashes bytes.Buffer
+	var wg sync.WaitGroup
+	outPipe, err := child.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	errPipe, err := child.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	start := time.Now()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		scanLines(outPipe, func(line string) {
+			report.CountTestResult(line)
+			fmt.Fprintln(stdout, line)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		scanLines(errPipe, func(line string) {
+			if strings.HasPrefix(line, "HASH") {
+				hashes.WriteString(line + "\n")
+				return
+			}
+			fmt.Fprintln(cmd.ErrOrStderr(), line)
+		})
+	}()
+	wg.Wait()
+	runErr := child.Wait()
+	report.WallSeconds = time.Since(start).Seconds()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(runErr, &exitErr):
+		report.ExitCode = exitErr.ExitCode()
+	case runErr != nil:
+		return fmt.Errorf("failed to run %s: %w", args[0], runErr)
+	}
+
+	trace, err := cache.ParseHashTrace(&hashes)
+	if err != nil {
+		return err
+	}
+	after, err := build.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+	build.CountEffect(&report.CacheEffect, before, after, trace)
+	if measureIndex != "" {
+		if err := cache.AppendIndex(measureIndex, args, trace); err != nil {
+			return err
+		}
+	}
+
+	err = render(cmd.OutOrStdout(), output, view{
+		Data:  report,
+		Table: func(w io.Writer) error { return outputMeasureTable(w, report) },
+	})
+	if err != nil {
+		return err
+	}
+	if report.ExitCode != 0 {
+		// The command has already shown why it failed
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%s exited with status %d", strings.Join(args, " "), report.ExitCode)
+	}
+	return nil
+}
+
+// scanLines calls fn with every line read from r
+func scanLines(r io.Reader, fn func(line string)) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
+
+func outputMeasureTable(w io.Writer, report measureReport) error {
+	if quiet {
+		fmt.Fprintf(w, "%.1f%% hit rate\n", report.HitRate*100)
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Cache Effectiveness")
+	fmt.Fprintln(w, "===================")
+	fmt.Fprintf(w, "Command:        %s\n", strings.Join(report.Command, " "))
+	fmt.Fprintf(w, "Wall Time:      %s\n", time.Duration(report.WallSeconds*float64(time.Second)).Round(time.Millisecond))
+	fmt.Fprintf(w, "Packages:       %s (%s hits, %s misses, %.1f%% hit rate)\n", cache.FormatCount(report.Actions),
+		cache.FormatCount(report.Hits), cache.FormatCount(report.Misses), report.HitRate*100)
+	if report.TestsRun+report.TestsCached > 0 {
+		fmt.Fprintf(w, "Tests:          %s run, %s cached\n", cache.FormatCount(report.TestsRun), cache.FormatCount(report.TestsCached))
+	}
+	fmt.Fprintf(w, "Build Entries:  %s new (%s)\n", cache.FormatCount(report.NewBuildEntries), cache.FormatBytes(report.BuildBytesAdded))
+	fmt.Fprintf(w, "Test Entries:   %s new (%s)\n", cache.FormatCount(report.NewTestEntries), cache.FormatBytes(report.TestBytesAdded))
+	return nil
+}
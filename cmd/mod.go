@@ -0,0 +1,527 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/muhammadali7768/gocachectl/internal/query"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modRoots []string
+
+	modLsWhere string
+	modLsSort  string
+	modLsLimit int
+
+	modRmForce  bool
+	modRmDryRun bool
+	// modRmQuarantine is read through cfg.Clear.Quarantine, which it
+	// overrides
+	modRmQuarantine bool
+)
+
+// modLsFields are the fields mod ls --where and --sort can use
+var modLsFields = query.Fields{
+	"root":      query.String,
+	"path":      query.String,
+	"version":   query.Semver,
+	"age":       query.Age,
+	"size":      query.Size,
+	"zip_size":  query.Size,
+	"versions":  query.Number,
+	"vcs":       query.String,
+	"url":       query.String,
+	"ref":       query.String,
+	"hash":      query.String,
+	"has_zip":   query.String,
+	"has_mod":   query.String,
+	"extracted": query.String,
+	"complete":  query.String,
+}
+
+var modCmd = &cobra.Command{
+	Use:   "mod",
+	Short: "Inspect and manage the module cache",
+	Long: `Inspect and manage the module cache version by version: the zips, go.mod
+and .info files the go command downloads, and the trees it extracts from
+the zips.`,
+}
+
+var modLsCmd = &cobra.Command{
+	Use:   "ls [path prefix]",
+	Short: "List cached module versions with their metadata",
+	Long: `List every module version in the module cache, with its module path and
+version decoded from the cache's escaping of upper-case letters. Each is
+shown with its release time and VCS origin from its .info file, the size of
+its extracted tree and of its zip, whether its zip, go.mod and extracted
+tree are all present, and how many versions of its module path are cached.
+
+Versions the go command only resolved while selecting versions have a
+go.mod alone; versions extracted by an older go command or copied between
+caches may lack their downloads. Give a module path prefix such as k8s.io
+to list only the modules under it.
+
+--where keeps the versions an expression matches, as in 'gocachectl ls'.
+Fields are root, path, version, age, size, zip_size, versions, vcs, url,
+ref, hash, has_zip, has_mod, extracted and complete. Versions compare by
+semantic versioning, age counts from the release time (0 when unknown),
+and the last four are true or false. --sort orders by a comma-separated
+list of fields, each descending when prefixed with -, and --limit keeps the
+first versions after sorting.`,
+	Example: `  gocachectl mod ls                                  # Every cached version
+  gocachectl mod ls github.com/spf13                 # Modules under a prefix
+  gocachectl mod ls --where 'complete==false'        # Partial downloads
+  gocachectl mod ls --where 'versions>3' --sort path,-version
+  gocachectl mod ls --where 'age>52w' --sort -size --limit 20
+  gocachectl mod ls --where 'vcs==git && url=~"github.com"' --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runModLs,
+}
+
+// modRmReport is the document written by mod rm
+type modRmReport struct {
+	DryRun     bool                    `json:"dry_run"`
+	Patterns   []string                `json:"patterns"`
+	Versions   []cachemgr.ListedModule `json:"versions"` // the versions selected for removal
+	Deleted    int                     `json:"deleted"`  // 0 on a dry run
+	TotalFreed int64                   `json:"total_freed"`
+	Errors     []cache.ClearFailure    `json:"errors"`
+	TrashID    string                  `json:"trash_id,omitempty"` // set when versions were quarantined
+}
+
+var modRmCmd = &cobra.Command{
+	Use:   "rm <pattern>...",
+	Short: "Remove module versions matching a pattern",
+	Long: `Remove module versions from the module cache: their extracted tree, and
+the zip, go.mod and .info files the go command downloaded for them. Their
+lines are dropped from the @v/list file of their module, so the go command
+downloads them again instead of trusting a corrupted or leaked copy.
+Extracted trees are read-only and are made writable before removal.
+
+A pattern is a module path, optionally followed by @ and a version. In
+the path, * matches any run of characters but /, and ... any run of
+characters, as in go package patterns. The version is exact, or prefixed
+with <, <=, >, >=, == or != to compare by semantic versioning:
+
+  github.com/acme/tool@v1.4.2     one version
+  github.com/acme/*@<v1.5.0       every acme module older than v1.5.0
+  github.com/acme/...             every version of every module below acme
+
+The matching versions are listed and removed after a confirmation unless
+--force is given; --dry-run only lists them.
+
+With --quarantine, or clear.quarantine in the config file, the versions
+are moved into the trash instead, together with a copy of the @v/list
+files they are dropped from, and 'gocachectl undo' restores both.`,
+	Example: `  gocachectl mod rm 'github.com/acme/*@<v1.5.0'        # Remove with confirmation
+  gocachectl mod rm 'example.com/private/...' --dry-run  # Show what would be removed
+  gocachectl mod rm golang.org/x/net@v0.30.0 -f --json
+  gocachectl mod rm 'github.com/acme/...' --quarantine  # Move to the trash, undoable`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runModRm,
+}
+
+func init() {
+	rootCmd.AddCommand(modCmd)
+	modCmd.AddCommand(modLsCmd)
+	modCmd.AddCommand(modRmCmd)
+
+	modCmd.PersistentFlags().StringArrayVar(&modRoots, "root", nil, "use only module cache roots with this label (repeatable)")
+	modLsCmd.Flags().StringVar(&modLsWhere, "where", "", "list only versions matching this expression, e.g. 'complete==false'")
+	modLsCmd.Flags().StringVar(&modLsSort, "sort", "path,version", "sort by these comma-separated fields, - for descending, e.g. -size")
+	modLsCmd.Flags().IntVar(&modLsLimit, "limit", 0, "list at most this many versions (0 for all)")
+	modRmCmd.Flags().BoolVarP(&modRmForce, "force", "f", false, "skip confirmation prompt")
+	modRmCmd.Flags().BoolVar(&modRmDryRun, "dry-run", false, "show what would be removed")
+	modRmCmd.Flags().BoolVar(&modRmQuarantine, "quarantine", false, "move versions into the trash instead of deleting them")
+}
+
+// modRecord exposes the fields of a module version to expressions
+type modRecord struct {
+	module *cachemgr.ListedModule
+	now    time.Time
+}
+
+func (r modRecord) Field(name string) any {
+	m := r.module
+	var origin cache.ModuleOrigin
+	if m.Origin != nil {
+		origin = *m.Origin
+	}
+	switch name {
+	case "root":
+		return m.Root
+	case "path":
+		return m.Path
+	case "version":
+		return m.Version
+	case "age":
+		if m.Time.IsZero() {
+			return time.Duration(0)
+		}
+		return r.now.Sub(m.Time)
+	case "size":
+		return m.Size
+	case "zip_size":
+		return m.ZipSize
+	case "versions":
+		return int64(m.Versions)
+	case "vcs":
+		return origin.VCS
+	case "url":
+		return origin.URL
+	case "ref":
+		return origin.Ref
+	case "hash":
+		return origin.Hash
+	case "has_zip":
+		return strconv.FormatBool(m.HasZip)
+	case "has_mod":
+		return strconv.FormatBool(m.HasMod)
+	case "extracted":
+		return strconv.FormatBool(m.Extracted)
+	case "complete":
+		return strconv.FormatBool(m.Complete)
+	}
+	return nil
+}
+
+func runModLs(cmd *cobra.Command, args []string) error {
+	if modLsLimit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	now := time.Now()
+	var where *query.Expr
+	if modLsWhere != "" {
+		var err error
+		if where, err = query.Parse(modLsWhere, modLsFields); err != nil {
+			return fmt.Errorf("invalid --where: %w", err)
+		}
+	}
+	order, err := queryOrder(modLsFields, modLsSort)
+	if err != nil {
+		return err
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	if err := checkRoots(manager, modRoots); err != nil {
+		return err
+	}
+	modules, err := manager.ModuleVersions(modRoots)
+	if err != nil {
+		return err
+	}
+
+	modules = slices.DeleteFunc(modules, func(m cachemgr.ListedModule) bool {
+		if len(args) > 0 && m.Path != args[0] && !strings.HasPrefix(m.Path, strings.TrimSuffix(args[0], "/")+"/") {
+			return true
+		}
+		return where != nil && !where.Match(modRecord{&m, now})
+	})
+	slices.SortStableFunc(modules, func(a, b cachemgr.ListedModule) int {
+		return order(modRecord{&a, now}, modRecord{&b, now})
+	})
+	if modLsLimit > 0 && len(modules) > modLsLimit {
+		modules = modules[:modLsLimit]
+	}
+	if modules == nil {
+		modules = []cachemgr.ListedModule{}
+	}
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data:  modules,
+		Table: func(w io.Writer) error { return outputModTable(w, modules) },
+	})
+}
+
+func runModRm(cmd *cobra.Command, args []string) error {
+	var patterns []*modulePattern
+	for _, arg := range args {
+		pattern, err := parseModulePattern(arg)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, pattern)
+	}
+	if output.machine() && !modRmForce && !modRmDryRun {
+		return fmt.Errorf("mod rm cannot prompt for confirmation with %s output: use --force or --dry-run", output.name)
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	if err := checkRoots(manager, modRoots); err != nil {
+		return err
+	}
+	modules, err := manager.ModuleVersions(modRoots)
+	if err != nil {
+		return err
+	}
+	modules = slices.DeleteFunc(modules, func(m cachemgr.ListedModule) bool {
+		return !slices.ContainsFunc(patterns, func(p *modulePattern) bool { return p.match(m.Path, m.Version) })
+	})
+
+	report := modRmReport{
+		DryRun:   modRmDryRun,
+		Patterns: args,
+		Versions: append([]cachemgr.ListedModule{}, modules...),
+		Errors:   []cache.ClearFailure{},
+	}
+	w := cmd.OutOrStdout()
+	if !output.machine() {
+		if len(modules) == 0 {
+			if !quiet {
+				fmt.Fprintln(w, "No module versions match")
+			}
+			return nil
+		}
+		if !quiet {
+			fmt.Fprintln(w, "Module versions to be removed:")
+			fmt.Fprintln(w, "==============================")
+			fmt.Fprintln(w)
+			if err := outputModTable(w, modules); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if modRmDryRun || len(modules) == 0 {
+		if output.machine() {
+			return render(w, output, view{Data: report, Records: report.Versions})
+		}
+		if !quiet {
+			fmt.Fprintln(w, "[DRY RUN] No module versions were removed")
+		}
+		return nil
+	}
+
+	if !modRmForce && !output.machine() {
+		if !confirm(cmd, fmt.Sprintf("Are you sure you want to remove these %s module versions?", cache.FormatCount(len(modules)))) {
+			if !quiet {
+				fmt.Fprintln(w, "Operation cancelled")
+			}
+			return nil
+		}
+	}
+
+	// Errors from RemoveModules, like a failure to write the audit log, come
+	// after removal and are returned once the result is shown
+	result, rmErr := manager.RemoveModules(modules, cfg.Clear.Quarantine)
+	if result == nil {
+		return rmErr
+	}
+	for _, kindResult := range result.Caches {
+		report.Deleted += kindResult.Deleted
+	}
+	report.TotalFreed = result.TotalFreed
+	report.TrashID = result.TrashID
+	report.Errors = append(report.Errors, result.Failures...)
+	if len(report.Errors) > 0 {
+		rmErr = errors.Join(rmErr, fmt.Errorf("%d paths could not be removed", len(report.Errors)))
+	}
+	if rmErr != nil {
+		cmd.SilenceUsage = true
+	}
+
+	if output.machine() {
+		if err := render(w, output, view{Data: report, Records: report.Versions}); err != nil {
+			return err
+		}
+		return rmErr
+	}
+	if !quiet {
+		if report.TrashID != "" {
+			fmt.Fprintf(w, "Quarantined %s module versions (%s)\n", cache.FormatCount(report.Deleted), cache.FormatBytes(report.TotalFreed))
+			fmt.Fprintln(w, "Run 'gocachectl undo' to restore them or 'gocachectl trash empty' to free the space")
+		} else {
+			fmt.Fprintf(w, "Removed %s module versions, %s freed\n", cache.FormatCount(report.Deleted), cache.FormatBytes(report.TotalFreed))
+		}
+		if len(report.Errors) > 0 && (verbose || len(report.Errors) <= maxShownFailures) {
+			for _, failure := range report.Errors {
+				fmt.Fprintf(w, "   %s: %s\n", failure.Path, failure.Error)
+			}
+		}
+	}
+	return rmErr
+}
+
+// modulePattern selects module versions by a module path pattern and an
+// optional version or version comparison
+type modulePattern struct {
+	path    *regexp.Regexp
+	version string      // exact version, when given without an operator
+	compare *query.Expr // version comparison, when given with one
+}
+
+// parseModulePattern parses a mod rm pattern: a module path where * matches
+// within a path element and ... across them, then optionally @ and a
+// version, exact or prefixed with a comparison operator
+func parseModulePattern(s string) (*modulePattern, error) {
+	modulePath, version, hasVersion := s, "", false
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		modulePath, version, hasVersion = s[:i], s[i+1:], true
+	}
+	if modulePath == "" {
+		return nil, fmt.Errorf("invalid pattern %q: missing module path", s)
+	}
+	if hasVersion && version == "" {
+		return nil, fmt.Errorf("invalid pattern %q: missing version after @", s)
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for rest := modulePath; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "..."):
+			expr.WriteString(".*")
+			rest = rest[3:]
+		case rest[0] == '*':
+			expr.WriteString("[^/]*")
+			rest = rest[1:]
+		default:
+			expr.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+		}
+	}
+	expr.WriteString("$")
+	pattern := &modulePattern{path: regexp.MustCompile(expr.String())}
+
+	if !hasVersion {
+		return pattern, nil
+	}
+	op := ""
+	for _, candidate := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+		if strings.HasPrefix(version, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		if strings.ContainsAny(version[:1], "<>=!") {
+			return nil, fmt.Errorf("invalid pattern %q: unknown version operator in %q", s, version)
+		}
+		pattern.version = version
+		if '0' <= version[0] && version[0] <= '9' {
+			pattern.version = "v" + version
+		}
+		return pattern, nil
+	}
+	v := strings.TrimSpace(version[len(op):])
+	if v == "" {
+		return nil, fmt.Errorf("invalid pattern %q: missing version after %s", s, op)
+	}
+	cmp, err := query.Parse(fmt.Sprintf("version %s %q", op, v), query.Fields{"version": query.Semver})
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	pattern.compare = cmp
+	return pattern, nil
+}
+
+// match reports whether the pattern selects the version of a module path
+func (p *modulePattern) match(modulePath, version string) bool {
+	if !p.path.MatchString(modulePath) {
+		return false
+	}
+	switch {
+	case p.version != "":
+		return version == p.version
+	case p.compare != nil:
+		return p.compare.Match(versionRecord(version))
+	}
+	return true
+}
+
+// versionRecord exposes a module version to a version comparison
+type versionRecord string
+
+func (r versionRecord) Field(string) any {
+	return string(r)
+}
+
+// outputModTable writes one line per module version, with a ROOT column
+// when they come from more than one root
+func outputModTable(w io.Writer, modules []cachemgr.ListedModule) error {
+	if len(modules) == 0 {
+		fmt.Fprintln(w, "No matching module versions")
+		return nil
+	}
+	multiRoot := slices.ContainsFunc(modules, func(m cachemgr.ListedModule) bool { return m.Root != modules[0].Root })
+
+	pathWidth, versionWidth := len("PATH"), len("VERSION")
+	for _, m := range modules {
+		pathWidth = max(pathWidth, len(m.Path))
+		versionWidth = max(versionWidth, len(m.Version))
+	}
+	pathWidth = min(pathWidth, 60)
+	line := func(root, path, version, released, size, zip, versions, files, origin string) {
+		if multiRoot {
+			fmt.Fprintf(w, "%-10s ", root)
+		}
+		fmt.Fprintf(w, "%-*s %-*s %-10s %10s %10s %8s  %-16s %s\n",
+			pathWidth, path, versionWidth, version, released, size, zip, versions, files, origin)
+	}
+
+	line("ROOT", "PATH", "VERSION", "RELEASED", "SIZE", "ZIP", "VERSIONS", "FILES", "ORIGIN")
+	var size, zipSize int64
+	paths := make(map[string]bool)
+	complete := 0
+	for _, m := range modules {
+		size += m.Size
+		zipSize += m.ZipSize
+		paths[m.Path] = true
+		if m.Complete {
+			complete++
+		}
+		released := "-"
+		if !m.Time.IsZero() {
+			released = m.Time.Format("2006-01-02")
+		}
+		origin := "-"
+		if m.Origin != nil && m.Origin.URL != "" {
+			origin = m.Origin.URL
+		}
+		line(m.Root, m.Path, m.Version, released, cache.FormatBytes(m.Size), cache.FormatBytes(m.ZipSize),
+			strconv.Itoa(m.Versions), moduleFiles(m.ModuleVersion), origin)
+	}
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s versions of %s modules, %s complete; %s extracted, %s in zips\n",
+			cache.FormatCount(len(modules)), cache.FormatCount(len(paths)), cache.FormatCount(complete),
+			cache.FormatBytes(size), cache.FormatBytes(zipSize))
+	}
+	return nil
+}
+
+// moduleFiles describes which of the zip, go.mod and extracted tree of a
+// module version are present
+func moduleFiles(m cache.ModuleVersion) string {
+	if m.Complete {
+		return "complete"
+	}
+	var missing []string
+	if !m.HasZip {
+		missing = append(missing, "zip")
+	}
+	if !m.HasMod {
+		missing = append(missing, "mod")
+	}
+	if !m.Extracted {
+		missing = append(missing, "tree")
+	}
+	return "no " + strings.Join(missing, ",")
+}
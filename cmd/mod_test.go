@@ -0,0 +1,43 @@
+package cmd
+
+import "testing"
+
+func TestParseModulePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		path    string
+		version string
+		want    bool
+	}{
+		{"github.com/acme/*@<v1.5.0", "github.com/acme/tool", "v1.4.9", true},
+		{"github.com/acme/*@<v1.5.0", "github.com/acme/tool", "v1.5.0-rc.1", true},
+		{"github.com/acme/*@<v1.5.0", "github.com/acme/tool", "v1.5.0", false},
+		{"github.com/acme/*@<v1.5.0", "github.com/acme/tool/v2", "v2.0.0", false},
+		{"github.com/acme/*@<v1.5.0", "github.com/acmeco/tool", "v1.0.0", false},
+		{"github.com/acme/...@>=1.5", "github.com/acme/tool/v2", "v2.0.0", true},
+		{"github.com/acme/...", "github.com/acme/tool", "v0.0.0-20240101000000-abcdef123456", true},
+		{"github.com/acme/tool@v1.4.2", "github.com/acme/tool", "v1.4.2", true},
+		{"github.com/acme/tool@1.4.2", "github.com/acme/tool", "v1.4.2", true},
+		{"github.com/acme/tool@v1.4.2", "github.com/acme/tool", "v1.4.20", false},
+		{"github.com/acme/tool@!=v1.4.2", "github.com/acme/tool", "v1.4.3", true},
+		{"github.com/acme/tool", "github.com/acme/tool/v2", "v2.0.0", false},
+		{"golang.org/x/n?t", "golang.org/x/n?t", "v0.1.0", true},
+		{"golang.org/x/n?t", "golang.org/x/net", "v0.1.0", false},
+	} {
+		p, err := parseModulePattern(tc.pattern)
+		if err != nil {
+			t.Errorf("parseModulePattern(%q) failed: %v", tc.pattern, err)
+			continue
+		}
+		if got := p.match(tc.path, tc.version); got != tc.want {
+			t.Errorf("%q on %s@%s: expected %v, got %v", tc.pattern, tc.path, tc.version, tc.want, got)
+		}
+	}
+
+	for _, pattern := range []string{"", "@v1.0.0", "example.com/a@", "example.com/a@<", "example.com/a@=<v1",
+		"github.com/acme/*@<vfoo", "github.com/acme/*@<latest", "github.com/acme/*@>=1.x"} {
+		if _, err := parseModulePattern(pattern); err == nil {
+			t.Errorf("Expected parseModulePattern(%q) to fail", pattern)
+		}
+	}
+}
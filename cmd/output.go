@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats selected with --output
+const (
+	formatTable    = "table"
+	formatJSON     = "json"
+	formatYAML     = "yaml"
+	formatCSV      = "csv"
+	formatNDJSON   = "ndjson"
+	formatTemplate = "template"
+)
+
+// outputFormat is a parsed --output value
+type outputFormat struct {
+	name     string
+	template *template.Template // set for the template format
+}
+
+// machine reports whether the format is meant for programs rather than
+// people, so commands must not mix prompts or prose into it
+func (f outputFormat) machine() bool {
+	return f.name != formatTable
+}
+
+// templateFuncs are available to --output template=...
+var templateFuncs = template.FuncMap{
+	"bytes": cache.FormatBytes,
+	"count": cache.FormatCount,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseOutput parses "table", "json", "yaml", "csv", "ndjson" or
+// "template=<text>"
+func parseOutput(s string) (outputFormat, error) {
+	name, text, hasText := strings.Cut(s, "=")
+	switch name {
+	case formatTable, formatJSON, formatYAML, formatCSV, formatNDJSON:
+		if hasText {
+			return outputFormat{}, fmt.Errorf("output format %s takes no argument", name)
+		}
+		return outputFormat{name: name}, nil
+	case formatTemplate:
+		if text == "" {
+			return outputFormat{}, fmt.Errorf("template output needs a template, e.g. template='{{.Size}}'")
+		}
+		tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return outputFormat{}, fmt.Errorf("invalid output template: %w", err)
+		}
+		return outputFormat{name: name, template: tmpl}, nil
+	}
+	return outputFormat{}, fmt.Errorf("unknown output format %q (want table, json, yaml, csv, ndjson or template=...)", s)
+}
+
+// view is a command's result, printable in every output format
+type view struct {
+	// Data is what the json, yaml, ndjson and template formats encode.
+	// Templates and ndjson lines are written once per element when it is a
+	// slice.
+	Data any
+	// Records are the csv rows, usually a slice of flat structs; Data is
+	// used when nil
+	Records any
+	// Table writes the human-readable format
+	Table func(w io.Writer) error
+}
+
+// render writes v to w in the given format
+func render(w io.Writer, format outputFormat, v view) error {
+	switch format.name {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v.Data)
+	case formatYAML:
+		return writeYAML(w, v.Data)
+	case formatCSV:
+		records := v.Records
+		if records == nil {
+			records = v.Data
+		}
+		return writeCSV(w, records)
+	case formatNDJSON:
+		return writeNDJSON(w, v.Data)
+	case formatTemplate:
+		return writeTemplate(w, format.template, v.Data)
+	}
+	return v.Table(w)
+}
+
+// toNode converts data to a YAML node through its JSON encoding, so every
+// format uses the same field names and keeps their order
+func toNode(data any) (*yaml.Node, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(encoded, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert output: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return doc.Content[0], nil
+}
+
+func writeYAML(w io.Writer, data any) error {
+	node, err := toNode(data)
+	if err != nil {
+		return err
+	}
+	resetStyle(node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return encoder.Close()
+}
+
+// resetStyle drops the quoting and flow style nodes decoded from JSON
+// carry, leaving the encoder to pick block style and quote only as needed
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// writeCSV writes one row per element of records, or a single row when it
+// is not a list. Nested objects become dotted columns, lists of scalars
+// are joined with ";" and anything else is written as JSON.
+func writeCSV(w io.Writer, records any) error {
+	node, err := toNode(records)
+	if err != nil {
+		return err
+	}
+
+	rows := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		rows = node.Content
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	var flat []map[string]string
+	for _, row := range rows {
+		cells := make(map[string]string)
+		var order []string
+		if err := flattenNode(row, "", cells, &order); err != nil {
+			return err
+		}
+		for _, column := range order {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+		flat = append(flat, cells)
+	}
+
+	writer := csv.NewWriter(w)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	for _, cells := range flat {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = cells[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// flattenNode stores the cells of node under dotted column names
+func flattenNode(node *yaml.Node, prefix string, cells map[string]string, order *[]string) error {
+	set := func(column, value string) {
+		if column == "" {
+			column = "value"
+		}
+		if _, ok := cells[column]; !ok {
+			*order = append(*order, column)
+		}
+		cells[column] = value
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			column := node.Content[i].Value
+			if prefix != "" {
+				column = prefix + "." + column
+			}
+			if err := flattenNode(node.Content[i+1], column, cells, order); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				var v any
+				if err := node.Decode(&v); err != nil {
+					return fmt.Errorf("failed to convert output: %w", err)
+				}
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return fmt.Errorf("failed to encode output: %w", err)
+				}
+				set(prefix, string(encoded))
+				return nil
+			}
+			values = append(values, item.Value)
+		}
+		set(prefix, strings.Join(values, ";"))
+	default:
+		if node.Tag == "!!null" {
+			set(prefix, "")
+			return nil
+		}
+		set(prefix, node.Value)
+	}
+	return nil
+}
+
+// elements returns the elements of data when it is a slice, or data alone
+func elements(data any) []any {
+	items := []any{data}
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice {
+		items = items[:0]
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	}
+	return items
+}
+
+// writeNDJSON writes data as one line of compact JSON, or one line per
+// element when it is a slice
+func writeNDJSON(w io.Writer, data any) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range elements(data) {
+		if err := encoder.Encode(item); err != nil {
+			return fmt.Errorf("failed to write NDJSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeTemplate executes tmpl for data, or for each element when data is a
+// slice, ending every execution with a newline like go list -f
+func writeTemplate(w io.Writer, tmpl *template.Template, data any) error {
+	for _, item := range elements(data) {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("failed to execute output template: %w", err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+)
+
+type testEntry struct {
+	Name  string            `json:"name"`
+	Size  int64             `json:"size"`
+	Tags  []string          `json:"tags"`
+	Meta  map[string]string `json:"meta,omitempty"`
+	Notes *string           `json:"notes"`
+}
+
+var testEntries = []testEntry{
+	{Name: "alpha", Size: 2048, Tags: []string{"a", "b"}, Meta: map[string]string{"os": "linux"}},
+	{Name: "beta, gamma", Size: 1},
+}
+
+func renderString(t *testing.T, spec string, v view) string {
+	t.Helper()
+
+	format, err := parseOutput(spec)
+	if err != nil {
+		t.Fatalf("parseOutput(%q) failed: %v", spec, err)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf, format, v); err != nil {
+		t.Fatalf("render(%q) failed: %v", spec, err)
+	}
+	return buf.String()
+}
+
+func TestParseOutput(t *testing.T) {
+	for _, spec := range []string{"table", "json", "yaml", "csv", "ndjson", "template={{.Size}}"} {
+		if _, err := parseOutput(spec); err != nil {
+			t.Errorf("parseOutput(%q) failed: %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"xml", "json=x", "template=", "template={{.Size"} {
+		if _, err := parseOutput(spec); err == nil {
+			t.Errorf("Expected parseOutput(%q) to fail", spec)
+		}
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	got := renderString(t, "table", view{
+		Data:  testEntries,
+		Table: func(w io.Writer) error { _, err := io.WriteString(w, "human\n"); return err },
+	})
+	if got != "human\n" {
+		t.Errorf("Expected the table writer to be used, got %q", got)
+	}
+}
+
+func TestRender_YAML(t *testing.T) {
+	got := renderString(t, "yaml", view{Data: testEntries})
+	want := `- name: alpha
+  size: 2048
+  tags:
+    - a
+    - b
+  meta:
+    os: linux
+  notes: null
+- name: beta, gamma
+  size: 1
+  tags: null
+  notes: null
+`
+	if got != want {
+		t.Errorf("Unexpected YAML:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRender_CSV(t *testing.T) {
+	got := renderString(t, "csv", view{Data: testEntries})
+	want := `name,size,tags,meta.os,notes
+alpha,2048,a;b,linux,
+"beta, gamma",1,,,
+`
+	if got != want {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Records replace the data when given
+	got = renderString(t, "csv", view{Data: testEntries, Records: []testEntry{{Name: "only"}}})
+	if !strings.HasPrefix(got, "name,size,tags,notes\nonly,0,,\n") {
+		t.Errorf("Expected the records to be written, got:\n%s", got)
+	}
+}
+
+func TestRender_NDJSON(t *testing.T) {
+	got := renderString(t, "ndjson", view{Data: testEntries})
+	want := `{"name":"alpha","size":2048,"tags":["a","b"],"meta":{"os":"linux"},"notes":null}
+{"name":"beta, gamma","size":1,"tags":null,"notes":null}
+`
+	if got != want {
+		t.Errorf("Unexpected NDJSON:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = renderString(t, "ndjson", view{Data: testEntries[1]})
+	if strings.Count(got, "\n") != 1 {
+		t.Errorf("Expected a single line, got %q", got)
+	}
+}
+
+func TestRender_Template(t *testing.T) {
+	got := renderString(t, "template={{.Name}}={{bytes .Size}}", view{Data: testEntries})
+	if got != "alpha=2.0 KB\nbeta, gamma=1 B\n" {
+		t.Errorf("Expected one line per element, got %q", got)
+	}
+
+	got = renderString(t, "template={{.Name}}", view{Data: testEntries[0]})
+	if got != "alpha\n" {
+		t.Errorf("Expected a single line, got %q", got)
+	}
+}
+
+func TestOutputAllStats_Writer(t *testing.T) {
+	stats := []cachemgr.RootStats{{
+		Kind:  cachemgr.Lookup("build"),
+		Root:  cachemgr.Root{Label: "ci", Path: "/srv/cache/ci"},
+		Stats: &cache.BuildCacheStats{Location: "/srv/cache/ci", Size: 3 * 1024 * 1024, EntryCount: 7},
+	}}
+
+	var buf bytes.Buffer
+	if err := outputAllStats(&buf, stats); err != nil {
+		t.Fatalf("outputAllStats failed: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{"Build Cache [ci]", "/srv/cache/ci", "3.0 MB", "Total Items:  7"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in output:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	goversion "go/version"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneDryRun bool
+	pruneForce  bool
+	pruneWait   time.Duration
+	pruneRoots  []string
+
+	pruneToolchainOlderThan string
+	prunePlatforms          []string
+
+	// pruneKinds holds the per-kind selection flags, keyed by kind name
+	pruneKinds map[string]*bool
+)
+
+// pruneReport is the document written by prune
+type pruneReport struct {
+	DryRun       bool   `json:"dry_run"`
+	GoCompatible bool   `json:"go_compatible"`
+	OlderThan    string `json:"older_than,omitempty"`
+	// ToolchainOlderThan is the Go version whose predecessors' objects
+	// were pruned, e.g. go1.24
+	ToolchainOlderThan string `json:"toolchain_older_than,omitempty"`
+	// Platforms are the targets whose objects were pruned, e.g. windows/amd64
+	Platforms  []string                           `json:"platforms,omitempty"`
+	Targets    []string                           `json:"targets"`
+	Roots      []string                           `json:"roots"`  // labels given with --root; empty means every root
+	Caches     map[string]*cache.CacheClearResult `json:"caches"` // keyed by kind; what would be removed on a dry run
+	TotalFreed int64                              `json:"total_freed"`
+	Errors     []cache.ClearFailure               `json:"errors"`
+}
+
+// pruneRecord is the csv row of one pruned kind
+type pruneRecord struct {
+	Kind    string `json:"kind"`
+	Deleted int    `json:"deleted"`
+	Freed   int64  `json:"freed"`
+	Errors  int    `json:"errors"`
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old cache entries",
+	Long: `Remove the cache entries older than --older-than, or prune.max_age in the
+config file, from the build, test and module caches. Build and test cache
+entries count from when they were last used, module versions from when
+they were downloaded.
+
+With --go-compatible, or prune.go_compatible in the config file, prune
+applies the go command's own trim to the build cache instead: it removes
+the entries unused for more than five days (plus an hour for the
+imprecision of their times), test entries included, and records the trim
+in trim.txt so that the go command does not trim again for a day.
+"gocachectl stats --build" shows when the go command trimmed last and how
+much it would trim next.
+
+With --toolchain-older-than, prune removes from the build cache the
+package archives and binaries compiled by Go releases older than the given
+version, together with the action files naming them. Combined with
+--older-than, only those entries that are also old enough are removed.
+"gocachectl stats --build --by-toolchain" shows how much each toolchain
+left behind.
+
+With --platform, prune removes from the build cache the package archives
+and binaries built for a target, given as GOOS/GOARCH or as a GOOS for
+every architecture, with the action files naming them. The target is read
+from the object headers of archives and the ELF, Mach-O or PE headers of
+binaries. "gocachectl stats --build --by-platform" shows the size per
+target.
+
+Like clear, prune refuses while go commands use the build or test cache
+unless given --wait or --force, and --wait takes precedence.`,
+	Example: `  gocachectl prune --older-than 30d          # Prune every cache
+  gocachectl prune --older-than 2w --modules # Prune only the module cache
+  gocachectl prune --go-compatible           # Trim as the go command does
+  gocachectl prune --go-compatible --dry-run # Show what would be trimmed
+  gocachectl prune --toolchain-older-than go1.24 # Remove objects of Go 1.23 and older
+  gocachectl prune --platform windows/amd64  # Remove objects cross-compiled for windows/amd64
+  gocachectl prune --platform darwin --dry-run # Show what was built for any darwin target`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().String("older-than", "", "remove entries older than this, e.g. 30d")
+	pruneCmd.Flags().Bool("go-compatible", false, "trim the build cache exactly as the go command does")
+	pruneCmd.Flags().StringVar(&pruneToolchainOlderThan, "toolchain-older-than", "", "remove build cache objects compiled by Go releases older than this, e.g. go1.24")
+	pruneCmd.Flags().StringSliceVar(&prunePlatforms, "platform", nil, "remove build cache objects built for this GOOS/GOARCH or GOOS (repeatable)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "show what would be removed")
+	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "prune even while go commands use the cache")
+	pruneCmd.Flags().DurationVar(&pruneWait, "wait", 0, "wait up to this long for go commands to stop using the cache")
+	pruneCmd.Flags().StringArrayVar(&pruneRoots, "root", nil, "prune only caches under the root with this label (repeatable)")
+
+	configFlags["older-than"] = "prune.max_age"
+	configFlags["go-compatible"] = "prune.go_compatible"
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	opts := cache.PruneOptions{
+		Targets:      selectedKinds(pruneKinds),
+		Roots:        pruneRoots,
+		OlderThan:    time.Duration(cfg.Prune.MaxAge),
+		GoCompatible: cfg.Prune.GoCompatible,
+		DryRun:       pruneDryRun,
+		Force:        pruneForce,
+		Wait:         pruneWait,
+	}
+	if pruneToolchainOlderThan != "" {
+		opts.ToolchainOlderThan = pruneToolchainOlderThan
+		if !strings.HasPrefix(opts.ToolchainOlderThan, "go") {
+			opts.ToolchainOlderThan = "go" + opts.ToolchainOlderThan
+		}
+		if !goversion.IsValid(opts.ToolchainOlderThan) {
+			return fmt.Errorf("invalid Go version %q: want a release such as go1.24", pruneToolchainOlderThan)
+		}
+		if opts.GoCompatible {
+			return fmt.Errorf("--toolchain-older-than cannot be combined with --go-compatible")
+		}
+	}
+	for _, platform := range prunePlatforms {
+		if err := cache.ValidatePlatform(platform); err != nil {
+			return err
+		}
+		if opts.GoCompatible {
+			return fmt.Errorf("--platform cannot be combined with --go-compatible")
+		}
+		opts.Platforms = append(opts.Platforms, platform)
+	}
+	if !opts.GoCompatible && opts.OlderThan == 0 && opts.ObjectFilter() == nil {
+		return fmt.Errorf("no prune policy: use --older-than, --toolchain-older-than, --platform or --go-compatible, or prune.max_age or prune.go_compatible in the config file")
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	if err := checkRoots(manager, pruneRoots); err != nil {
+		return err
+	}
+
+	// The go command only trims the build cache, which alone holds
+	// compiled objects; otherwise prune every cache made of entries
+	buildOnly := ""
+	switch {
+	case opts.GoCompatible:
+		buildOnly = "--go-compatible"
+	case opts.ToolchainOlderThan != "":
+		buildOnly = "--toolchain-older-than"
+	case len(opts.Platforms) > 0:
+		buildOnly = "--platform"
+	}
+	switch {
+	case buildOnly != "" && len(opts.Targets) == 0:
+		opts.Targets = []string{"build"}
+	case buildOnly != "":
+		if opts.Targets = slices.DeleteFunc(opts.Targets, func(kind string) bool { return kind != "build" }); len(opts.Targets) == 0 {
+			return fmt.Errorf("%s only prunes the build cache", buildOnly)
+		}
+	case len(opts.Targets) == 0:
+		for _, browsable := range manager.Browsable(allKinds()) {
+			if !slices.Contains(opts.Targets, browsable.Kind.Name) {
+				opts.Targets = append(opts.Targets, browsable.Kind.Name)
+			}
+		}
+	}
+
+	result, pruneErr := manager.Prune(opts)
+	if pruneErr != nil {
+		cmd.SilenceUsage = true
+	}
+
+	report := pruneReport{
+		DryRun:             opts.DryRun,
+		GoCompatible:       opts.GoCompatible,
+		ToolchainOlderThan: opts.ToolchainOlderThan,
+		Platforms:          opts.Platforms,
+		Targets:            opts.Targets,
+		Roots:              append([]string{}, pruneRoots...),
+		Caches:             result.Caches,
+		TotalFreed:         result.TotalFreed,
+		Errors:             append([]cache.ClearFailure{}, result.Failures...),
+	}
+	if !opts.GoCompatible && opts.OlderThan > 0 {
+		report.OlderThan = cache.FormatAge(opts.OlderThan)
+	}
+
+	w := cmd.OutOrStdout()
+	if output.machine() {
+		if err := outputPruneReport(w, report); err != nil {
+			return err
+		}
+		return pruneErr
+	}
+	if !quiet {
+		printPruneReport(w, report)
+	}
+	return pruneErr
+}
+
+// printPruneReport writes the prune report for people
+func printPruneReport(w io.Writer, report pruneReport) {
+	verb := "removed"
+	if report.DryRun {
+		verb = "would be removed"
+	}
+	for _, kind := range cachemgr.Kinds() {
+		result, ok := report.Caches[kind.Name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%-21s %s %s %s (%s)\n", kind.Title+":",
+			cache.FormatCount(result.Deleted), kind.Unit, verb, cache.FormatBytes(result.Freed))
+	}
+
+	fmt.Fprintln(w)
+	if report.DryRun {
+		fmt.Fprintf(w, "[DRY RUN] %s would be freed\n", cache.FormatBytes(report.TotalFreed))
+	} else {
+		fmt.Fprintf(w, "Total space freed: %s\n", cache.FormatBytes(report.TotalFreed))
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(w, "\n Warning: %d errors occurred during pruning\n", len(report.Errors))
+		if verbose || len(report.Errors) <= maxShownFailures {
+			for _, failure := range report.Errors {
+				fmt.Fprintf(w, "   %s: %s\n", failure.Path, failure.Error)
+			}
+		}
+	}
+}
+
+// outputPruneReport writes the prune report in a machine-readable format
+func outputPruneReport(w io.Writer, report pruneReport) error {
+	var records []pruneRecord
+	for _, kind := range cachemgr.Kinds() {
+		result, ok := report.Caches[kind.Name]
+		if !ok {
+			continue
+		}
+		record := pruneRecord{Kind: kind.Name, Deleted: result.Deleted, Freed: result.Freed}
+		for _, failure := range report.Errors {
+			if failure.Kind == kind.Name {
+				record.Errors++
+			}
+		}
+		records = append(records, record)
+	}
+	return render(w, output, view{Data: report, Records: records})
+}
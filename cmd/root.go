@@ -1,19 +1,44 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/muhammadali7768/gocachectl/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	cfgFile    string
+	profile    string
 	verbose    bool
 	jsonOutput bool
+	outputFlag string
 	quiet      bool
 
+	// output is the effective output format, from --output, --json or the
+	// config file
+	output outputFormat
+
+	// cfg is the effective configuration, loaded before any command runs
+	cfg        *config.Config
+	cfgSources config.Sources
+	cfgErr     error
+
+	// configFlags maps command flags to the config keys they override,
+	// e.g. check's --max-total to thresholds.max_total
+	configFlags = make(map[string]string)
+
+	// Cache roots given on the command line, as label=path or path
+	gocacheRoots    []string
+	gomodcacheRoots []string
+
 	// Version info
 	version string
 	commit  string
@@ -34,21 +59,74 @@ Examples:
   gocachectl stats --modules     Show only module cache stats
   gocachectl clear --all         Clear all caches
   gocachectl info                Show cache locations`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		return cfg.Validate()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
+	// Cache kinds may be registered from any imported package's init,
+	// so their flags are only added once all of them have run
+	statsKinds = addKindFlags(statsCmd, "show only %s statistics")
+	clearKinds = addKindFlags(clearCmd, "clear %s")
+	pruneKinds = addKindFlags(pruneCmd, "prune %s")
+	lsKinds = addKindFlags(lsCmd, "list the %s")
+
 	return rootCmd.Execute()
 }
 
+// addKindFlags adds a boolean flag per registered cache kind to cmd and
+// returns them keyed by kind name
+func addKindFlags(cmd *cobra.Command, usage string) map[string]*bool {
+	flags := make(map[string]*bool)
+	for _, kind := range cachemgr.Kinds() {
+		flags[kind.Name] = cmd.Flags().Bool(kind.Flag, false, fmt.Sprintf(usage, kind.Description))
+	}
+	return flags
+}
+
+// selectedKinds returns the names of the kinds whose flag is set, in
+// registration order
+func selectedKinds(flags map[string]*bool) []string {
+	var kinds []string
+	for _, kind := range cachemgr.Kinds() {
+		if set, ok := flags[kind.Name]; ok && *set {
+			kinds = append(kinds, kind.Name)
+		}
+	}
+	return kinds
+}
+
+// allKinds returns the names of all registered kinds
+func allKinds() []string {
+	var kinds []string
+	for _, kind := range cachemgr.Kinds() {
+		kinds = append(kinds, kind.Name)
+	}
+	return kinds
+}
+
+// underline returns a rule as wide as a heading
+func underline(heading string) string {
+	return strings.Repeat("=", utf8.RuneCountInString(heading))
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gocachectl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "config profile to apply (default $GOCACHECTL_PROFILE)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "output format: table, json, yaml, csv, ndjson or template=<Go template> (default table)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format (same as --output json)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "minimal output")
+	rootCmd.PersistentFlags().StringArrayVar(&gocacheRoots, "gocache", nil, "build cache root as label=path (repeatable, default from go env)")
+	rootCmd.PersistentFlags().StringArrayVar(&gomodcacheRoots, "gomodcache", nil, "module cache root as label=path (repeatable, default from go env)")
 
 	// Version command
 	rootCmd.AddCommand(versionCmd)
@@ -56,6 +134,8 @@ func init() {
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	config.SetDefaults(viper.GetViper())
+
 	if cfgFile != "" {
 		// Use config file from the flag
 		viper.SetConfigFile(cfgFile)
@@ -75,12 +155,109 @@ func initConfig() {
 		viper.SetConfigName(".gocachectl")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// If a config file is found, read it in
-	if err := viper.ReadInConfig(); err == nil && verbose {
+	// If a config file is found, read it in. A missing default file is
+	// fine; an unreadable or malformed one is reported by loadConfig.
+	err := viper.ReadInConfig()
+	var notFound viper.ConfigFileNotFoundError
+	switch {
+	case err == nil && verbose:
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	case err != nil && !errors.As(err, &notFound):
+		cfgErr = fmt.Errorf("failed to read config file: %w", err)
+	}
+}
+
+// loadConfig resolves the effective configuration from the config file,
+// the selected profile, the environment and the command line
+func loadConfig(cmd *cobra.Command) error {
+	if cfgErr != nil {
+		return cfgErr
+	}
+
+	opts := config.Options{
+		Profile: profile,
+		Flags:   make(map[string]config.Flag),
+	}
+	if opts.Profile == "" {
+		opts.Profile = os.Getenv(config.EnvPrefix + "_PROFILE")
+		profile = opts.Profile
+	}
+
+	switch {
+	case cmd.Flags().Changed("output"):
+		opts.Flags["output"] = config.Flag{Name: "output", Value: outputFlag}
+	case cmd.Flags().Changed("json") && jsonOutput:
+		opts.Flags["output"] = config.Flag{Name: "json", Value: formatJSON}
+	}
+	for name, key := range configFlags {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			opts.Flags[key] = config.Flag{Name: name, Value: flag.Value.String()}
+		}
+	}
+	if len(gocacheRoots) > 0 {
+		opts.Flags[config.RootsKey("GOCACHE")] = config.Flag{Name: "gocache", Value: gocacheRoots}
+	}
+	if len(gomodcacheRoots) > 0 {
+		opts.Flags[config.RootsKey("GOMODCACHE")] = config.Flag{Name: "gomodcache", Value: gomodcacheRoots}
+	}
+
+	var err error
+	cfg, cfgSources, err = config.Load(viper.GetViper(), opts)
+	if err != nil {
+		return err
+	}
+
+	output, err = parseOutput(cfg.Output)
+	return err
+}
+
+// newUnifiedManager creates a unified manager for the configured cache roots
+func newUnifiedManager() (*cachemgr.UnifiedManager, error) {
+	log, err := auditLog()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := cachemgr.Options{
+		Roots:      make(map[string][]cachemgr.Root),
+		Audit:      log,
+		AgeBuckets: cfg.Stats.Buckets(),
+	}
+	for _, kind := range cachemgr.Kinds() {
+		opts.Roots[kind.Env] = cfg.Roots[strings.ToLower(kind.Env)]
+	}
+
+	manager, err := cachemgr.NewUnifiedManager(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+	return manager, nil
+}
+
+// auditLog returns the configured audit log, or nil when auditing is
+// disabled
+func auditLog() (*audit.Log, error) {
+	if !cfg.Audit.Enabled {
+		return nil, nil
+	}
+	path := cfg.Audit.Log
+	if path == "" {
+		var err error
+		if path, err = audit.DefaultPath(); err != nil {
+			return nil, fmt.Errorf("%w: set audit.log or disable audit.enabled", err)
+		}
+	}
+	return audit.New(path, os.Args), nil
+}
+
+// checkRoots verifies that every label names a registered cache root
+func checkRoots(manager *cachemgr.UnifiedManager, labels []string) error {
+	for _, label := range labels {
+		if !manager.HasRoot(label) {
+			return fmt.Errorf("no cache root labelled %q", label)
+		}
 	}
+	return nil
 }
 
 // SetVersionInfo sets version information
@@ -94,10 +271,11 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("gocachectl %s\n", version)
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "gocachectl %s\n", version)
 		if verbose {
-			fmt.Printf("commit: %s\n", commit)
-			fmt.Printf("built: %s\n", date)
+			fmt.Fprintf(w, "commit: %s\n", commit)
+			fmt.Fprintf(w, "built: %s\n", date)
 		}
 	},
 }
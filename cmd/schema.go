@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/cobra"
+)
+
+// jsonSchemaDialect is the JSON Schema version the output schemas follow
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [command]",
+	Short: "Print the JSON Schema of command output",
+	Long: `Print the JSON Schema describing what a command writes with --json.
+The yaml output has the same structure.
+
+Without a command, the schemas of every command are printed, keyed by
+command. The schemas are generated from the same types the commands
+encode, so they always match the installed version.`,
+	Example: `  gocachectl schema                # Schemas of all commands
+  gocachectl schema clear          # Schema of clear --json
+  gocachectl schema toolchain list # Schema of toolchain list --json`,
+	RunE: runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	schemas := outputSchemas()
+
+	var data any = schemas
+	if len(args) > 0 {
+		name := strings.Join(args, " ")
+		schema, ok := schemas[name]
+		if !ok {
+			var names []string
+			for name := range schemas {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("no JSON output schema for %q (want one of: %s)", name, strings.Join(names, ", "))
+		}
+		data = schema
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// outputSchemas returns the JSON Schema of each command's --json output,
+// keyed by command path
+func outputSchemas() map[string]map[string]any {
+	// stats writes one document per cache, or a single one when a single
+	// cache is selected
+	stats := kindStatsSchema()
+
+	clear := cachemgr.SchemaOf(clearReport{})
+	before := clear["properties"].(map[string]any)["before"].(map[string]any)
+	before["items"].(map[string]any)["properties"].(map[string]any)["stats"] = stats
+
+	schemas := map[string]map[string]any{
+		"stats":          {"anyOf": []any{stats, map[string]any{"type": "array", "items": stats}}},
+		"info":           cachemgr.SchemaOf(cache.CacheInfo{}),
+		"clear":          clear,
+		"check":          cachemgr.SchemaOf(checkReport{}),
+		"prune":          cachemgr.SchemaOf(pruneReport{}),
+		"du":             cachemgr.SchemaOf(cache.UsageNode{}),
+		"explain":        cachemgr.SchemaOf(explainReport{}),
+		"measure":        cachemgr.SchemaOf(measureReport{}),
+		"bench":          cachemgr.SchemaOf(benchReport{}),
+		"thrash":         cachemgr.SchemaOf(cache.ThrashReport{}),
+		"ls":             {"anyOf": []any{cachemgr.SchemaOf([]cachemgr.ListedEntry{}), cachemgr.SchemaOf(lsDeleteReport{})}},
+		"warm":           cachemgr.SchemaOf(warmReport{}),
+		"audit":          cachemgr.SchemaOf([]audit.Record{}),
+		"mod ls":         cachemgr.SchemaOf([]cachemgr.ListedModule{}),
+		"mod rm":         cachemgr.SchemaOf(modRmReport{}),
+		"toolchain list": cachemgr.SchemaOf([]cache.ToolchainInfo{}),
+		"trash list":     cachemgr.SchemaOf([]trashRecord{}),
+		"trash empty":    cachemgr.SchemaOf(trashEmptyReport{}),
+		"undo":           cachemgr.SchemaOf(undoReport{}),
+		"config show":    cachemgr.SchemaOf(configDocument{}),
+	}
+	for name, schema := range schemas {
+		schema["$schema"] = jsonSchemaDialect
+		schema["title"] = "gocachectl " + name + " output"
+	}
+	return schemas
+}
+
+// kindStatsSchema matches the stats document of any registered kind
+func kindStatsSchema() map[string]any {
+	var kinds []any
+	for _, kind := range cachemgr.Kinds() {
+		kinds = append(kinds, kind.Schema)
+	}
+	return map[string]any{"anyOf": kinds}
+}
@@ -1,8 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/muhammadali7768/gocachectl/internal/cache"
 	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
@@ -10,9 +11,12 @@ import (
 )
 
 var (
-	showBuild   bool
-	showModules bool
-	showTest    bool
+	// statsKinds holds the per-kind selection flags, keyed by kind name
+	statsKinds map[string]*bool
+	statsRoots []string
+
+	statsByToolchain bool
+	statsByPlatform  bool
 )
 
 var statsCmd = &cobra.Command{
@@ -22,257 +26,172 @@ var statsCmd = &cobra.Command{
 - Build cache size and entries
 - Module cache size and module count
 - Test cache size and entries
+- Downloaded Go toolchains
+- gopls and golangci-lint caches, where present
 - Total size across all caches
 
-Use flags to show specific cache statistics.`,
+Use flags to show specific cache statistics. When several cache roots are
+configured (see --gocache and --gomodcache), each is shown and counted in
+the total; use --root to show one of them.
+
+Build, test and module cache entries are counted in age buckets, by
+default <1d, 1d-7d, 7d-30d and >30d; set others with --age-buckets or
+stats.age_buckets in the config file. With --verbose, each age bucket is
+split by entry size, to help choose a prune cutoff.
+
+With --by-toolchain, build cache entries are grouped by the Go version that
+compiled them, read from the header of every package archive and binary.
+Entries left by toolchains no longer in use can then be removed with
+"gocachectl prune --toolchain-older-than".
+
+With --by-platform, they are grouped by target GOOS/GOARCH instead, read
+from the same headers or, for binaries, their ELF, Mach-O or PE headers.
+Cross-compiled entries can then be removed with "gocachectl prune
+--platform".`,
 	Example: `  gocachectl stats              # Show all cache stats
   gocachectl stats --build      # Show only build cache
   gocachectl stats --modules    # Show only module cache
-  gocachectl stats --json       # Output as JSON`,
+  gocachectl stats --toolchains # Show only downloaded toolchains
+  gocachectl stats --gopls      # Show only gopls cache
+  gocachectl stats --gocache ci=/var/cache/go-ci --gocache local=$HOME/.cache/go-build --build
+  gocachectl stats --root ci    # Show only caches under the root labelled ci
+  gocachectl stats --build -v --age-buckets 1h,1d,1w,4w
+  gocachectl stats --build --by-toolchain
+  gocachectl stats --build --by-platform
+  gocachectl stats --json       # Output as JSON
+  gocachectl stats -o csv       # One CSV row per cache
+  gocachectl stats --build -o template='{{bytes .Size}}'`,
 	RunE: runStats,
 }
 
 func init() {
 	rootCmd.AddCommand(statsCmd)
 
-	statsCmd.Flags().BoolVar(&showBuild, "build", false, "show only build cache statistics")
-	statsCmd.Flags().BoolVar(&showModules, "modules", false, "show only module cache statistics")
-	statsCmd.Flags().BoolVar(&showTest, "test", false, "show only test cache statistics")
+	statsCmd.Flags().StringArrayVar(&statsRoots, "root", nil, "show only caches under the root with this label (repeatable)")
+	statsCmd.Flags().BoolVar(&statsByToolchain, "by-toolchain", false, "group build cache entries by the Go version that produced them")
+	statsCmd.Flags().BoolVar(&statsByPlatform, "by-platform", false, "group build cache entries by the GOOS/GOARCH they were built for")
+	statsCmd.Flags().String("age-buckets", "", "upper bounds of the age buckets, e.g. 1d,7d,30d")
+	configFlags["age-buckets"] = "stats.age_buckets"
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
 	// Create unified manager
-	manager, err := cachemgr.NewUnifiedManager()
+	manager, err := newUnifiedManager()
 	if err != nil {
-		return fmt.Errorf("failed to initialize cache manager: %w", err)
+		return err
 	}
-
-	// Determine what to show
-	showAll := !showBuild && !showModules && !showTest
-
-	if jsonOutput {
-		return outputStatsJSON(cmd, manager, showAll)
+	if err := checkRoots(manager, statsRoots); err != nil {
+		return err
 	}
+	manager.SetBreakdown(cache.Breakdown{ByToolchain: statsByToolchain, ByPlatform: statsByPlatform})
 
-	return outputStatsHuman(manager, showAll)
-}
-
-func outputStatsJSON(cmd *cobra.Command, manager *cachemgr.UnifiedManager, showAll bool) error {
-	var data interface{}
-
+	// Determine what to show
+	kinds := selectedKinds(statsKinds)
+	showAll := len(kinds) == 0
 	if showAll {
-		stats, err := manager.GetAllStats()
-		if err != nil {
-			return err
-		}
-		data = stats
-	} else if showBuild {
-		stats, err := manager.GetStatsByType("build")
-		if err != nil {
-			return err
-		}
-		data = stats
-	} else if showModules {
-		stats, err := manager.GetStatsByType("module")
-		if err != nil {
-			return err
-		}
-		data = stats
-	} else if showTest {
-		stats, err := manager.GetStatsByType("tests")
-		if err != nil {
-			return err
-		}
-		data = stats
+		kinds = allKinds()
 	}
 
-	encoder := json.NewEncoder(cmd.OutOrStdout())
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
-}
-
-func outputStatsHuman(manager *cachemgr.UnifiedManager, showAll bool) error {
-	if showAll {
-		return outputAllStats(manager)
+	stats, err := manager.GetStatsFor(kinds, statsRoots)
+	if err != nil {
+		return err
 	}
-
-	if showBuild {
-		return outputBuildStats(manager)
+	if len(stats) == 0 {
+		return fmt.Errorf("no stats found for type: %s", strings.Join(kinds, ", "))
 	}
 
-	if showModules {
-		return outputModuleStats(manager)
+	var data any = statsData(stats)
+	if len(stats) == 1 && !showAll {
+		data = stats[0].Stats
 	}
 
-	if showTest {
-		return outputTestStats(manager)
-	}
+	return render(cmd.OutOrStdout(), output, view{
+		Data:    data,
+		Records: statsRecords(stats),
+		Table: func(w io.Writer) error {
+			if len(stats) == 1 && !showAll {
+				return outputKindStats(w, stats[0])
+			}
+			return outputAllStats(w, stats)
+		},
+	})
+}
 
-	return nil
+// statsRecord is the csv row of one cache
+type statsRecord struct {
+	Kind     string `json:"kind"`
+	Root     string `json:"root"`
+	Location string `json:"location"`
+	Size     int64  `json:"size"`
+	Count    int    `json:"count"`
 }
 
-func outputAllStats(manager *cachemgr.UnifiedManager) error {
-	all, err := manager.GetAllStats()
-	if err != nil {
-		return err
+func statsData(stats []cachemgr.RootStats) []cache.Stats {
+	all := make([]cache.Stats, 0, len(stats))
+	for _, s := range stats {
+		all = append(all, s.Stats)
 	}
+	return all
+}
+
+func statsRecords(stats []cachemgr.RootStats) []statsRecord {
+	records := make([]statsRecord, 0, len(stats))
+	for _, s := range stats {
+		summary := s.Kind.Summary(s.Stats)
+		records = append(records, statsRecord{
+			Kind:     s.Kind.Name,
+			Root:     s.Root.Label,
+			Location: s.Root.Path,
+			Size:     summary.Size,
+			Count:    summary.Count,
+		})
+	}
+	return records
+}
 
+func outputAllStats(w io.Writer, all []cachemgr.RootStats) error {
 	if !quiet {
-		fmt.Println("Go Cache Statistics")
-		fmt.Println("===================")
-		fmt.Println()
+		fmt.Fprintln(w, "Go Cache Statistics")
+		fmt.Fprintln(w, "===================")
+		fmt.Fprintln(w)
 	}
 
 	var totalSize int64
 	var totalCount int
 	for _, stats := range all {
+		summary := stats.Kind.Summary(stats.Stats)
+		totalSize += summary.Size
+		totalCount += summary.Count
 
-		switch stat := stats.(type) {
-		case *cache.BuildCacheStats:
-			totalCount += stat.EntryCount
-			totalSize += stat.Size
-			// Build Cache
-			fmt.Println("Build Cache")
-			fmt.Printf("   Location:     %s\n", stat.Location)
-			fmt.Printf("   Size:         %s\n", cache.FormatBytes(stat.Size))
-			fmt.Printf("   Entries:      %s\n", cache.FormatCount(stat.EntryCount))
-			if !stat.OldestEntry.IsZero() {
-				fmt.Printf("   Oldest:       %s\n", stat.OldestEntry.Format("2006-01-02 15:04:05"))
-				fmt.Printf("   Newest:       %s\n", stat.NewestEntry.Format("2006-01-02 15:04:05"))
-			}
-			fmt.Println()
-		case *cache.ModCacheStats:
-			totalCount += stat.ModuleCount
-			totalSize += stat.Size
-			// Module Cache
-			fmt.Println("Module Cache")
-			fmt.Printf("   Location:     %s\n", stat.Location)
-			fmt.Printf("   Size:         %s\n", cache.FormatBytes(stat.Size))
-			fmt.Printf("   Modules:      %s\n", cache.FormatCount(stat.ModuleCount))
-			fmt.Println()
-		case *cache.TestCacheStats:
-			totalCount += stat.EntryCount
-			totalSize += stat.Size
-			// Test Cache
-			fmt.Println("Test Cache")
-			fmt.Printf("   Location:     %s\n", stat.Location)
-			fmt.Printf("   Size:         %s\n", cache.FormatBytes(stat.Size))
-			fmt.Printf("   Entries:      %s\n", cache.FormatCount(stat.EntryCount))
-			if !stat.OldestEntry.IsZero() {
-				fmt.Printf("   Oldest:       %s\n", stat.OldestEntry.Format("2006-01-02 15:04:05"))
-				fmt.Printf("   Newest:       %s\n", stat.NewestEntry.Format("2006-01-02 15:04:05"))
-			}
-			fmt.Println()
-		}
-	}
-	// Total
-	fmt.Println("Total")
-	fmt.Printf("   Total Size:   %s\n", cache.FormatBytes(totalSize))
-	fmt.Printf("   Total Items:  %s\n", cache.FormatCount(totalCount))
-
-	// Verbose output
-	if verbose {
-		fmt.Println()
-		fmt.Println("Size Distribution (Build Cache):")
-		for _, stats := range all {
-			switch stat := stats.(type) {
-			case *cache.BuildCacheStats:
-				fmt.Printf("   Small (<1MB):    %d entries (%s)\n",
-					stat.Distribution.Small, cache.FormatBytes(stat.Distribution.SmallSize))
-				fmt.Printf("   Medium (1-10MB): %d entries (%s)\n",
-					stat.Distribution.Medium, cache.FormatBytes(stat.Distribution.MediumSize))
-				fmt.Printf("   Large (>10MB):   %d entries (%s)\n",
-					stat.Distribution.Large, cache.FormatBytes(stat.Distribution.LargeSize))
-			case *cache.ModCacheStats:
-				if len(stat.TopModules) > 0 {
-					fmt.Println()
-					fmt.Println("Top Modules by Size:")
-					for i, mod := range stat.TopModules {
-						if i >= 5 {
-							break
-						}
-						fmt.Printf("   %d. %s (%s)\n", i+1, mod.Path, cache.FormatBytes(mod.Size))
-					}
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
-func outputBuildStats(manager *cachemgr.UnifiedManager) error {
-	stats, err := manager.GetStatsByType("build")
-	if err != nil {
-		return err
-	}
-
-	if !quiet {
-		fmt.Println("Build Cache Statistics")
-		fmt.Println("======================")
-		fmt.Println()
+		fmt.Fprintln(w, sectionTitle(stats))
+		stats.Kind.Render(w, stats.Stats, cachemgr.RenderOptions{Indent: "   ", Verbose: verbose})
+		fmt.Fprintln(w)
 	}
 
-	buildCacheStats := stats.(*cache.BuildCacheStats)
-
-	fmt.Printf("Location:     %s\n", buildCacheStats.Location)
-	fmt.Printf("Size:         %s\n", cache.FormatBytes(buildCacheStats.Size))
-	fmt.Printf("Entries:      %s\n", cache.FormatCount(buildCacheStats.EntryCount))
-	if !buildCacheStats.OldestEntry.IsZero() {
-		fmt.Printf("Oldest Entry: %s\n", buildCacheStats.OldestEntry.Format("2006-01-02 15:04:05"))
-		fmt.Printf("Newest Entry: %s\n", buildCacheStats.NewestEntry.Format("2006-01-02 15:04:05"))
-	}
+	// Total
+	fmt.Fprintln(w, "Total")
+	fmt.Fprintf(w, "   Total Size:   %s\n", cache.FormatBytes(totalSize))
+	fmt.Fprintf(w, "   Total Items:  %s\n", cache.FormatCount(totalCount))
 
 	return nil
 }
 
-func outputModuleStats(manager *cachemgr.UnifiedManager) error {
-	stats, err := manager.GetStatsByType("module")
-	if err != nil {
-		return err
-	}
-
+func outputKindStats(w io.Writer, stats cachemgr.RootStats) error {
 	if !quiet {
-		fmt.Println("Module Cache Statistics")
-		fmt.Println("=======================")
-		fmt.Println()
-	}
-
-	moduleCacheStats := stats.(*cache.ModCacheStats)
-	fmt.Printf("Location:     %s\n", moduleCacheStats.Location)
-	fmt.Printf("Size:         %s\n", cache.FormatBytes(moduleCacheStats.Size))
-	fmt.Printf("Modules:      %s\n", cache.FormatCount(moduleCacheStats.ModuleCount))
-
-	if verbose && len(moduleCacheStats.TopModules) > 0 {
-		fmt.Println()
-		fmt.Println("Top Modules by Size:")
-		for i, mod := range moduleCacheStats.TopModules {
-			fmt.Printf("   %d. %s (%s)\n", i+1, mod.Path, cache.FormatBytes(mod.Size))
-		}
+		header := sectionTitle(stats) + " Statistics"
+		fmt.Fprintln(w, header)
+		fmt.Fprintln(w, underline(header))
+		fmt.Fprintln(w)
 	}
 
+	stats.Kind.Render(w, stats.Stats, cachemgr.RenderOptions{Verbose: verbose})
 	return nil
 }
 
-func outputTestStats(manager *cachemgr.UnifiedManager) error {
-	stats, err := manager.GetStatsByType("test")
-	if err != nil {
-		return err
-	}
-
-	if !quiet {
-		fmt.Println("Test Cache Statistics")
-		fmt.Println("=====================")
-		fmt.Println()
+// sectionTitle names a cache, adding the root label unless it is the default
+func sectionTitle(stats cachemgr.RootStats) string {
+	if stats.Root.Label == cachemgr.DefaultLabel {
+		return stats.Kind.Title
 	}
-	testCacheStats := stats.(*cache.TestCacheStats)
-	fmt.Printf("Location:     %s\n", testCacheStats.Location)
-	fmt.Printf("Size:         %s\n", cache.FormatBytes(testCacheStats.Size))
-	fmt.Printf("Entries:      %s\n", cache.FormatCount(testCacheStats.EntryCount))
-	if !testCacheStats.OldestEntry.IsZero() {
-		fmt.Printf("Oldest Entry: %s\n", testCacheStats.OldestEntry.Format("2006-01-02 15:04:05"))
-		fmt.Printf("Newest Entry: %s\n", testCacheStats.NewestEntry.Format("2006-01-02 15:04:05"))
-	}
-
-	return nil
+	return fmt.Sprintf("%s [%s]", stats.Kind.Title, stats.Root.Label)
 }
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	thrashIndex     string
+	thrashBuilds    int
+	thrashMinBuilds int
+	thrashMinRate   float64
+	thrashRoots     []string
+)
+
+var thrashCmd = &cobra.Command{
+	Use:   "thrash",
+	Short: "Find packages rebuilt on every build instead of reused from the cache",
+	Long: `Find packages whose build cache entries are written anew on nearly every
+build and never reused, the sign of a build that is not reproducible: a
+timestamp passed with -ldflags, a generated file that always differs or a
+hashed environment variable that changes between runs. Such packages cost a
+full compile on every run and fill the cache with entries nobody reads.
+
+Without --index, the entries of the build cache are analyzed: action files
+record when they were written, mtimes whether they were used again, and the
+compiled outputs which package they hold. Writes separated by a pause of ten
+minutes or more are taken to be different builds. This needs no setup, but
+cannot tell why a package was rebuilt, and a package under active
+development looks the same as one that thrashes. When several build caches
+are configured, choose the one to analyze with --root.
+
+With --index, the builds recorded by 'gocachectl measure --index' are
+analyzed instead. Each records the action ID of every package and the
+inputs hashed into it, so thrash also reports the inputs that changed each
+time. A package rebuilt only because a dependency changed points at that
+dependency.`,
+	Example: `  gocachectl thrash                                 # From the build cache's entries
+  gocachectl measure --index ci-actions.jsonl -- go build ./...   # In every CI run
+  gocachectl thrash --index ci-actions.jsonl        # Which inputs vary?
+  gocachectl thrash --builds 50 --min-rate 0.5 --json`,
+	Args: cobra.NoArgs,
+	RunE: runThrash,
+}
+
+func init() {
+	rootCmd.AddCommand(thrashCmd)
+
+	thrashCmd.Flags().StringVar(&thrashIndex, "index", "", "analyze the builds recorded in this action index")
+	thrashCmd.Flags().IntVar(&thrashBuilds, "builds", 20, "number of latest builds to analyze")
+	thrashCmd.Flags().IntVar(&thrashMinBuilds, "min-builds", 3, "builds a package needs to be judged")
+	thrashCmd.Flags().Float64Var(&thrashMinRate, "min-rate", 0.8, "share of builds rewriting a package from which it is reported")
+	thrashCmd.Flags().StringArrayVar(&thrashRoots, "root", nil, "analyze the build cache under the root with this label")
+}
+
+func runThrash(cmd *cobra.Command, args []string) error {
+	if thrashBuilds < 2 || thrashMinBuilds < 2 {
+		return fmt.Errorf("--builds and --min-builds must be at least 2")
+	}
+	if thrashMinRate <= 0 || thrashMinRate > 1 {
+		return fmt.Errorf("--min-rate must be above 0 and at most 1, got %g", thrashMinRate)
+	}
+	opts := cache.ThrashOptions{Builds: thrashBuilds, MinBuilds: thrashMinBuilds, MinRate: thrashMinRate}
+
+	var report *cache.ThrashReport
+	if thrashIndex != "" {
+		records, err := cache.ReadIndex(thrashIndex)
+		if err != nil {
+			return err
+		}
+		report = cache.AnalyzeIndex(records, opts)
+	} else {
+		build, err := thrashBuildManager()
+		if err != nil {
+			return err
+		}
+		if report, err = build.Thrash(opts); err != nil {
+			return err
+		}
+	}
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data:    report,
+		Records: report.Packages,
+		Table:   func(w io.Writer) error { return outputThrashTable(w, report) },
+	})
+}
+
+// thrashBuildManager returns the manager of the configured build cache
+// selected by --root, which must select exactly one
+func thrashBuildManager() (*cache.BuildManager, error) {
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoots(manager, thrashRoots); err != nil {
+		return nil, err
+	}
+	managers := manager.Managers("build", thrashRoots)
+	switch {
+	case len(managers) == 0:
+		return nil, fmt.Errorf("no build cache under the selected roots")
+	case len(managers) > 1:
+		var labels []string
+		for _, m := range managers {
+			labels = append(labels, m.Root.Label)
+		}
+		return nil, fmt.Errorf("thrash analyzes one build cache at a time: choose one of %s with --root", strings.Join(labels, ", "))
+	}
+	build, ok := managers[0].Manager.(*cache.BuildManager)
+	if !ok {
+		return nil, fmt.Errorf("the build cache at %s cannot be analyzed", managers[0].Root.Label)
+	}
+	return build, nil
+}
+
+func outputThrashTable(w io.Writer, report *cache.ThrashReport) error {
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Cache Thrash")
+		fmt.Fprintln(w, "============")
+		source := "entries of the build cache"
+		if report.Source == cache.ThrashFromIndex {
+			source = "action index " + thrashIndex
+		}
+		fmt.Fprintf(w, "Source:  %s\n", source)
+		builds := fmt.Sprintf("%d", report.Builds)
+		if report.Builds > 0 {
+			builds += fmt.Sprintf(", %s to %s", report.First.Format("2006-01-02 15:04"), report.Last.Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(w, "Builds:  %s\n", builds)
+		fmt.Fprintln(w)
+	}
+
+	if len(report.Packages) == 0 {
+		fmt.Fprintf(w, "No package built at least %d times was rewritten on %.0f%% of its builds\n", thrashMinBuilds, thrashMinRate*100)
+		return nil
+	}
+	for _, p := range report.Packages {
+		fmt.Fprintf(w, "%s: rewritten on %d of %d builds (%.0f%%), reused %d times\n",
+			p.Package, p.Rewrites, p.Builds-1, p.RewriteRate*100, p.Reused)
+		for i, in := range p.Varying {
+			if i == 3 && !verbose {
+				fmt.Fprintf(w, "   ... and %d more inputs\n", len(p.Varying)-i)
+				break
+			}
+			change := cache.InputChange{Kind: in.Kind, Key: in.Key, Old: in.Old, New: in.New}
+			fmt.Fprintf(w, "   %-10s %-32s %d times, last %s\n", in.Kind, in.Key, in.Changes, describeChange(change))
+		}
+	}
+	if !quiet && report.Source == cache.ThrashFromMtime {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Record builds with 'gocachectl measure --index <file> -- go build ...' and")
+		fmt.Fprintln(w, "analyze them with --index <file> to find which inputs vary")
+	}
+	return nil
+}
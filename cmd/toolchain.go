@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	toolchainRoots  []string
+	toolchainForce  bool
+	toolchainDryRun bool
+)
+
+var toolchainCmd = &cobra.Command{
+	Use:   "toolchain",
+	Short: "Manage Go toolchains downloaded by GOTOOLCHAIN",
+	Long: `Manage Go toolchains the go command downloads into the module cache
+when GOTOOLCHAIN=auto selects a newer release.
+
+Projects below the --root directories are scanned for go.mod and go.work
+files to tell which toolchains are still needed: the one a toolchain line
+names or, without one, the release a go line names.`,
+}
+
+var toolchainListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List downloaded toolchains",
+	Example: `  gocachectl toolchain list
+  gocachectl toolchain list --root ~/src --json`,
+	RunE: runToolchainList,
+}
+
+var toolchainPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove toolchains no project needs",
+	Example: `  gocachectl toolchain prune --root ~/src            # Remove unused toolchains (with confirmation)
+  gocachectl toolchain prune --root ~/src --dry-run  # Show what would be removed`,
+	RunE: runToolchainPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(toolchainCmd)
+	toolchainCmd.AddCommand(toolchainListCmd)
+	toolchainCmd.AddCommand(toolchainPruneCmd)
+
+	toolchainCmd.PersistentFlags().StringArrayVar(&toolchainRoots, "root", nil, "project directory to scan for toolchain requirements (repeatable)")
+	toolchainPruneCmd.Flags().BoolVarP(&toolchainForce, "force", "f", false, "skip confirmation prompt")
+	toolchainPruneCmd.Flags().BoolVar(&toolchainDryRun, "dry-run", false, "show what would be removed")
+}
+
+func runToolchainList(cmd *cobra.Command, args []string) error {
+	roots, err := toolchainManagers()
+	if err != nil {
+		return err
+	}
+
+	toolchains := []cache.ToolchainInfo{}
+	byRoot := make([][]cache.ToolchainInfo, len(roots))
+	for i, root := range roots {
+		if byRoot[i], err = root.Manager.List(); err != nil {
+			return fmt.Errorf("failed to list toolchains at %s: %w", root.Root.Label, err)
+		}
+		toolchains = append(toolchains, byRoot[i]...)
+	}
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data: toolchains,
+		Table: func(w io.Writer) error {
+			if len(toolchains) == 0 {
+				if !quiet {
+					fmt.Fprintln(w, "No downloaded toolchains found")
+				}
+				return nil
+			}
+			printToolchainRoots(w, roots, byRoot)
+			return nil
+		},
+	})
+}
+
+func runToolchainPrune(cmd *cobra.Command, args []string) error {
+	if len(toolchainRoots) == 0 {
+		return fmt.Errorf("must specify at least one --root to check toolchain usage against")
+	}
+
+	roots, err := toolchainManagers()
+	if err != nil {
+		return err
+	}
+	log, err := auditLog()
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	byRoot := make([][]cache.ToolchainInfo, len(roots))
+	count := 0
+	for i, root := range roots {
+		if byRoot[i], err = root.Manager.Unused(); err != nil {
+			return fmt.Errorf("failed to find unused toolchains at %s: %w", root.Root.Label, err)
+		}
+		count += len(byRoot[i])
+	}
+
+	if count == 0 {
+		if !quiet {
+			fmt.Fprintln(w, "No unused toolchains found")
+		}
+		return nil
+	}
+
+	if !quiet {
+		fmt.Fprintln(w, "Toolchains to be removed:")
+		fmt.Fprintln(w, "=========================")
+		fmt.Fprintln(w)
+		printToolchainRoots(w, roots, byRoot)
+		fmt.Fprintln(w)
+	}
+
+	if toolchainDryRun {
+		if !quiet {
+			fmt.Fprintln(w, "[DRY RUN] No toolchains were removed")
+		}
+		return nil
+	}
+
+	if !toolchainForce {
+		if !confirm(cmd, "Are you sure you want to remove these toolchains?") {
+			if !quiet {
+				fmt.Fprintln(w, "Operation cancelled")
+			}
+			return nil
+		}
+	}
+
+	record := audit.Record{Operation: "toolchain prune", Targets: []string{"toolchain"}}
+	for i, root := range roots {
+		if len(byRoot[i]) == 0 {
+			continue
+		}
+		record.Roots = append(record.Roots, root.Root.Label)
+		for _, tc := range byRoot[i] {
+			size, err := root.Manager.Remove(tc)
+			record.Freed += size
+			if err != nil {
+				fmt.Fprintf(w, " Warning: %v\n", err)
+				record.AddFailure(err.Error())
+				continue
+			}
+			record.Deleted++
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "Removed %d toolchains, freed %s\n", record.Deleted, cache.FormatBytes(record.Freed))
+	}
+
+	return log.Append(record)
+}
+
+// toolchainManagers returns the toolchain managers of the configured module
+// caches, scanning the --root projects for the toolchains they require
+func toolchainManagers() ([]cachemgr.ToolchainRoot, error) {
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return nil, err
+	}
+	return manager.Toolchains(toolchainRoots)
+}
+
+// printToolchainRoots prints the toolchains of each module cache root,
+// headed by the root's label when there is more than one
+func printToolchainRoots(w io.Writer, roots []cachemgr.ToolchainRoot, byRoot [][]cache.ToolchainInfo) {
+	for i, root := range roots {
+		if len(byRoot[i]) == 0 {
+			continue
+		}
+		if len(roots) > 1 {
+			fmt.Fprintf(w, "%s (%s):\n", root.Root.Label, root.Root.Path)
+		}
+		printToolchains(w, byRoot[i])
+	}
+}
+
+// printToolchains prints one line per toolchain with its size and the last
+// time a scanned project needed it
+func printToolchains(w io.Writer, toolchains []cache.ToolchainInfo) {
+	for _, tc := range toolchains {
+		lastNeeded := "-"
+		if !tc.LastNeeded.IsZero() {
+			lastNeeded = "needed " + tc.LastNeeded.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "   %-12s %-14s %10s   %s\n", tc.Version, tc.Platform, cache.FormatBytes(tc.Size), lastNeeded)
+	}
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashOlderThan string
+	trashForce     bool
+)
+
+// trashGracePeriod is how long after it last changed an operation without
+// a manifest is taken to be a clear still running, which trash empty and
+// undo leave alone
+const trashGracePeriod = time.Hour
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage entries quarantined by clear --quarantine",
+	Long: `Manage the trash that clear --quarantine moves entries into.
+
+Each cache directory keeps its own trash in a .gocachectl-trash directory,
+so quarantining is a rename on the same filesystem. Quarantined entries
+still take up disk space until the trash is emptied.
+
+A clear that was interrupted leaves its operation without a manifest;
+undo restores it from the journal of the entries it moved. trash empty
+and undo leave such operations alone for an hour after they last changed,
+as a clear in another process may still be filling them.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List quarantined clear operations",
+	Example: `  gocachectl trash list
+  gocachectl trash list --json`,
+	RunE: runTrashList,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Delete quarantined entries for good",
+	Example: `  gocachectl trash empty                       # Purge everything (with confirmation)
+  gocachectl trash empty --older-than 1d --force # Purge clears older than a day`,
+	RunE: runTrashEmpty,
+}
+
+// trashRecord describes one operation in one trash
+type trashRecord struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Root    string    `json:"root"` // cache directory
+	Kinds   []string  `json:"kinds"`
+	Entries int       `json:"entries"`
+	Size    int64     `json:"size"`
+	Status  string    `json:"status"` // quarantined, interrupted, restored or incomplete
+}
+
+// trashEmptyReport is the document written by trash empty
+type trashEmptyReport struct {
+	Purged int   `json:"purged"` // operations
+	Freed  int64 `json:"freed"`
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "purge only operations older than this, e.g. 1d or 12h")
+	trashEmptyCmd.Flags().BoolVarP(&trashForce, "force", "f", false, "skip confirmation prompt")
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	ops, err := trashOperations(manager)
+	if err != nil {
+		return err
+	}
+
+	records := make([]trashRecord, 0, len(ops))
+	for _, op := range ops {
+		records = append(records, newTrashRecord(op))
+	}
+
+	return render(cmd.OutOrStdout(), output, view{
+		Data: records,
+		Table: func(w io.Writer) error {
+			if len(records) == 0 {
+				if !quiet {
+					fmt.Fprintln(w, "The trash is empty")
+				}
+				return nil
+			}
+			var total int64
+			for _, r := range records {
+				total += r.Size
+				fmt.Fprintf(w, "%s  %-11s %10s  %s entries  %-16s %s\n", r.Time.Local().Format("2006-01-02 15:04"),
+					r.Status, cache.FormatBytes(r.Size), cache.FormatCount(r.Entries), joinKinds(r.Kinds), r.Root)
+			}
+			if !quiet {
+				fmt.Fprintf(w, "\nTotal in trash: %s\n", cache.FormatBytes(total))
+			}
+			return nil
+		},
+	})
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	var olderThan time.Duration
+	if trashOlderThan != "" {
+		age, err := cache.ParseAge(trashOlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		olderThan = age
+	}
+	if output.machine() && !trashForce {
+		return fmt.Errorf("trash empty cannot prompt for confirmation with %s output: use --force", output.name)
+	}
+
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	ops, err := trashOperations(manager)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var purge []*cache.TrashOperation
+	var size int64
+	for _, op := range ops {
+		if olderThan > 0 && now.Sub(op.Time) <= olderThan || op.InProgress(now, trashGracePeriod) {
+			continue
+		}
+		purge = append(purge, op)
+		size += op.Size()
+	}
+
+	w := cmd.OutOrStdout()
+	report := &trashEmptyReport{}
+	if len(purge) == 0 {
+		if output.machine() {
+			return render(w, output, view{Data: report})
+		}
+		if !quiet {
+			fmt.Fprintln(w, "Nothing to purge")
+		}
+		return nil
+	}
+
+	if !trashForce {
+		message := fmt.Sprintf("Are you sure you want to delete %d trash operations (%s)?", len(purge), cache.FormatBytes(size))
+		if !confirm(cmd, message) {
+			if !quiet {
+				fmt.Fprintln(w, "Operation cancelled")
+			}
+			return nil
+		}
+	}
+
+	record := audit.Record{Operation: "trash empty"}
+	for _, op := range purge {
+		freed, err := op.Purge()
+		report.Freed += freed
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), " Warning: %v\n", err)
+			record.AddFailure(err.Error())
+			continue
+		}
+		report.Purged++
+		for _, entry := range op.Entries {
+			if !slices.Contains(record.Targets, entry.Kind) {
+				record.Targets = append(record.Targets, entry.Kind)
+			}
+		}
+	}
+	record.Deleted, record.Freed = report.Purged, report.Freed
+	if err := manager.Audit(record); err != nil {
+		return err
+	}
+
+	return render(w, output, view{
+		Data: report,
+		Table: func(w io.Writer) error {
+			if !quiet {
+				fmt.Fprintf(w, "Purged %d trash operations, freed %s\n", report.Purged, cache.FormatBytes(report.Freed))
+			}
+			return nil
+		},
+	})
+}
+
+// trashOperations returns the operations in the trash of every cache
+// directory, oldest first
+func trashOperations(manager *cachemgr.UnifiedManager) ([]*cache.TrashOperation, error) {
+	var ops []*cache.TrashOperation
+	for _, trash := range manager.Trashes() {
+		found, err := trash.Operations()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, found...)
+	}
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+	return ops, nil
+}
+
+func newTrashRecord(op *cache.TrashOperation) trashRecord {
+	record := trashRecord{
+		ID:      op.ID,
+		Time:    op.Time,
+		Root:    op.Root,
+		Kinds:   []string{},
+		Entries: len(op.Entries),
+		Size:    op.Size(),
+		Status:  "quarantined",
+	}
+	for _, entry := range op.Entries {
+		if !slices.Contains(record.Kinds, entry.Kind) {
+			record.Kinds = append(record.Kinds, entry.Kind)
+		}
+	}
+	switch {
+	case op.Incomplete:
+		record.Status = "incomplete"
+	case !op.Restored.IsZero():
+		record.Status = "restored"
+	case op.Interrupted:
+		record.Status = "interrupted"
+	}
+	return record
+}
+
+// joinKinds lists kinds for a table cell
+func joinKinds(kinds []string) string {
+	if len(kinds) == 0 {
+		return "-"
+	}
+	return strings.Join(kinds, ",")
+}
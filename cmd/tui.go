@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/muhammadali7768/gocachectl/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and delete cache contents interactively",
+	Long: `Open a full-screen browser with a tab per cache, like ncdu for Go caches.
+
+Lists can be sorted by size, age or name. The module cache lists modules
+with their total size; enter opens a module's versions. Mark entries with
+space and delete them with d after confirming. Caches are scanned in the
+background, so the browser stays responsive on large caches.
+
+Keys:
+  tab, left/right   switch cache        up/down, pgup/pgdown  move
+  enter, esc        open/close module   space, a              mark entry, all
+  s                 change sort order   d                     delete marked
+  r                 rescan              q                     quit`,
+	Example: `  gocachectl tui
+  gocachectl tui --gocache ci=/srv/cache/ci`,
+	RunE: runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+
+	var tabs []tui.Tab
+	for _, c := range manager.Browsable(allKinds()) {
+		tabs = append(tabs, tui.Tab{
+			Title:  sectionTitle(cachemgr.RootStats{Kind: c.Kind, Root: c.Root}),
+			Lister: c.Lister,
+		})
+	}
+
+	if err := tui.Run(tabs); err != nil {
+		return fmt.Errorf("failed to run cache browser: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/audit"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore the entries of the last quarantining clear or mod rm",
+	Long: `Move the entries of the most recent clear --quarantine or
+mod rm --quarantine back from the trash into their caches. Each undo steps
+one operation further back.
+
+Entries the go command has recreated since are never overwritten: they
+stay in the trash until it is emptied.`,
+	Example: `  gocachectl clear --modules --force --quarantine
+  gocachectl undo`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+// undoReport is the document written by undo
+type undoReport struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"` // when the entries were quarantined
+	Restored int       `json:"restored"`
+	Size     int64     `json:"size"`
+	Skipped  []string  `json:"skipped"` // recreated since, left in the trash
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	manager, err := newUnifiedManager()
+	if err != nil {
+		return err
+	}
+	ops, err := trashOperations(manager)
+	if err != nil {
+		return err
+	}
+
+	// The last operation not yet restored, in every root it touched
+	var id string
+	now := time.Now()
+	for _, op := range ops {
+		if !op.Incomplete && op.Restored.IsZero() && !op.InProgress(now, trashGracePeriod) {
+			id = op.ID
+		}
+	}
+	if id == "" {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("nothing to undo: the trash holds no quarantined clear")
+	}
+
+	report := &undoReport{ID: id, Skipped: []string{}}
+	var errs []error
+	for _, op := range ops {
+		if op.ID != id {
+			continue
+		}
+		report.Time = op.Time
+		result, err := op.Restore(now)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if result != nil {
+			report.Restored += result.Restored
+			report.Size += result.Size
+			report.Skipped = append(report.Skipped, result.Skipped...)
+		}
+	}
+
+	record := audit.Record{Operation: "undo", Restored: report.Restored, TrashID: id}
+	for _, err := range errs {
+		record.AddFailure(err.Error())
+	}
+	if err := manager.Audit(record); err != nil {
+		errs = append(errs, err)
+	}
+
+	w := cmd.OutOrStdout()
+	if err := render(w, output, view{
+		Data: report,
+		Table: func(w io.Writer) error {
+			if quiet {
+				return nil
+			}
+			fmt.Fprintf(w, "Restored %s entries (%s) cleared at %s\n", cache.FormatCount(report.Restored),
+				cache.FormatBytes(report.Size), report.Time.Local().Format("2006-01-02 15:04:05"))
+			if len(report.Skipped) > 0 {
+				fmt.Fprintf(w, "Left %s entries in the trash that the go command has recreated since\n",
+					cache.FormatCount(len(report.Skipped)))
+				if verbose {
+					for _, path := range report.Skipped {
+						fmt.Fprintf(w, "   %s\n", path)
+					}
+				}
+			}
+			return nil
+		},
+	}); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
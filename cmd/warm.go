@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	warmPlatforms []string
+	warmTests     bool
+	warmProxy     string
+	warmExport    string
+)
+
+// warmReport is the document written by warm
+type warmReport struct {
+	Packages []string `json:"packages"`
+	Tests    bool     `json:"tests"`
+	Proxy    string   `json:"proxy,omitempty"` // directory modules were downloaded from
+	// Modules is what downloading modules added to the module cache
+	Modules   cache.WarmResult `json:"modules"`
+	Platforms []warmPlatform   `json:"platforms"`
+	// Export is the archive the warmed caches were written to
+	Export     string `json:"export,omitempty"`
+	ExportSize int64  `json:"export_size,omitempty"`
+}
+
+// warmPlatform is what compiling for one platform added to the build cache
+type warmPlatform struct {
+	Platform string `json:"platform"` // GOOS/GOARCH
+	cache.WarmResult
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+var warmCmd = &cobra.Command{
+	Use:   "warm [packages]",
+	Short: "Fill the caches by downloading modules and compiling packages",
+	Long: `Warm the module and build caches for fresh CI images and new machines:
+download every module the main module needs, then compile the packages
+(./... by default) for each platform given with --platforms, and with
+--tests their test binaries too, without running any test. warm reports how
+much each platform added to the build cache.
+
+With --proxy, modules are downloaded from a local directory in GOPROXY
+layout instead of the network, such as the cache/download directory of a
+module cache from another machine, so warming works offline. The checksum
+database is not consulted then; go.sum still verifies every module.
+
+With --export, the warmed build and module caches are written to a gzipped
+tar archive, as gocache/ and gomodcache/, to ship to runners: extract it and
+point GOCACHE and GOMODCACHE at the two directories.
+
+warm fills the GOCACHE and GOMODCACHE the go command uses in the current
+directory; set those variables to warm other caches.`,
+	Example: `  gocachectl warm                                        # Host platform, ./...
+  gocachectl warm ./... --platforms linux/amd64,linux/arm64 --tests
+  gocachectl warm --proxy /mnt/seed/mod/cache/download   # Offline
+  GOCACHE=/tmp/ci/gocache GOMODCACHE=/tmp/ci/gomodcache gocachectl warm --tests --export ci-cache.tar.gz`,
+	RunE: runWarm,
+}
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+
+	warmCmd.Flags().StringSliceVar(&warmPlatforms, "platforms", nil, "GOOS/GOARCH targets to compile for (default the host)")
+	warmCmd.Flags().BoolVar(&warmTests, "tests", false, "also compile test binaries, without running them")
+	warmCmd.Flags().StringVar(&warmProxy, "proxy", "", "download modules from this GOPROXY-layout directory instead of the network")
+	warmCmd.Flags().StringVar(&warmExport, "export", "", "write the warmed caches to this .tar.gz archive")
+}
+
+func runWarm(cmd *cobra.Command, args []string) error {
+	patterns := args
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	platforms := warmPlatforms
+	for _, platform := range platforms {
+		if err := cache.ValidatePlatform(platform); err != nil {
+			return err
+		}
+		if !strings.Contains(platform, "/") {
+			return fmt.Errorf("invalid platform %q: want GOOS/GOARCH such as linux/arm64", platform)
+		}
+	}
+	if len(platforms) == 0 {
+		goos, err := cache.GetGoEnv("GOOS")
+		if err != nil {
+			return err
+		}
+		goarch, err := cache.GetGoEnv("GOARCH")
+		if err != nil {
+			return err
+		}
+		platforms = []string{goos + "/" + goarch}
+	}
+	if warmProxy != "" {
+		if info, err := os.Stat(warmProxy); err != nil || !info.IsDir() {
+			return fmt.Errorf("proxy directory %s does not exist", warmProxy)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	// The go command's output would break machine-readable reports
+	progress := cmd.ErrOrStderr()
+	if !output.machine() && !quiet {
+		progress = cmd.OutOrStdout()
+	}
+	warmer, err := cache.NewWarmer(dir, warmProxy, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	report := warmReport{Packages: patterns, Tests: warmTests, Proxy: warmProxy}
+	if !quiet {
+		fmt.Fprintln(progress, "Downloading modules...")
+	}
+	if report.Modules, err = warmer.DownloadModules(); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, platform := range platforms {
+		if !quiet {
+			fmt.Fprintf(progress, "Compiling for %s...\n", platform)
+		}
+		start := time.Now()
+		result, err := warmer.Compile(platform, patterns, warmTests)
+		warmed := warmPlatform{Platform: platform, WarmResult: result, Seconds: time.Since(start).Seconds()}
+		if err != nil {
+			warmed.Error = err.Error()
+			failed++
+		}
+		report.Platforms = append(report.Platforms, warmed)
+	}
+
+	if warmExport != "" && failed == 0 {
+		if !quiet {
+			fmt.Fprintf(progress, "Exporting to %s...\n", warmExport)
+		}
+		gocache, gomodcache := warmer.Caches()
+		report.Export = warmExport
+		report.ExportSize, err = cache.ExportArchive(warmExport, map[string]string{"gocache": gocache, "gomodcache": gomodcache})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = render(cmd.OutOrStdout(), output, view{
+		Data:    report,
+		Records: report.Platforms,
+		Table:   func(w io.Writer) error { return outputWarmTable(w, report) },
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to warm %d of %d platforms", failed, len(platforms))
+	}
+	return nil
+}
+
+func outputWarmTable(w io.Writer, report warmReport) error {
+	if !quiet {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Cache Warming")
+		fmt.Fprintln(w, "=============")
+		fmt.Fprintf(w, "%-16s %s files (%s)\n", "Modules:", cache.FormatCount(report.Modules.NewEntries),
+			cache.FormatBytes(report.Modules.BytesAdded))
+	}
+	for _, platform := range report.Platforms {
+		status := fmt.Sprintf("%s entries (%s) in %s", cache.FormatCount(platform.NewEntries),
+			cache.FormatBytes(platform.BytesAdded), time.Duration(platform.Seconds*float64(time.Second)).Round(time.Second))
+		if platform.Error != "" {
+			status += ", FAILED: " + platform.Error
+		}
+		fmt.Fprintf(w, "%-16s %s\n", platform.Platform+":", status)
+	}
+	if report.Export != "" {
+		fmt.Fprintf(w, "%-16s %s (%s)\n", "Exported:", report.Export, cache.FormatBytes(report.ExportSize))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package audit keeps a log of gocachectl's destructive operations: one
+// JSON record per line, appended by every clear, prune and removal, and
+// queried by the audit command.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// MaxFailures caps the failures a record lists; Errors still counts all
+const MaxFailures = 20
+
+// Record describes one destructive operation
+type Record struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	Command   []string  `json:"command"`           // command line
+	Operation string    `json:"operation"`         // e.g. "clear" or "toolchain prune"
+	Targets   []string  `json:"targets,omitempty"` // cache kinds
+	Roots     []string  `json:"roots,omitempty"`   // root labels; empty means every root
+	Deleted   int       `json:"deleted"`
+	Freed     int64     `json:"freed"`
+	Restored  int       `json:"restored,omitempty"`
+	Errors    int       `json:"errors"`
+	Failures  []string  `json:"failures,omitempty"` // the first MaxFailures errors
+	TrashID   string    `json:"trash_id,omitempty"` // set when entries were quarantined
+}
+
+// AddFailure counts an error, listing it while under MaxFailures
+func (r *Record) AddFailure(failure string) {
+	r.Errors++
+	if len(r.Failures) < MaxFailures {
+		r.Failures = append(r.Failures, failure)
+	}
+}
+
+// Log is an append-only audit log file. A nil *Log records nothing, so
+// callers need not check whether auditing is enabled.
+type Log struct {
+	path    string
+	user    string
+	host    string
+	command []string
+}
+
+// DefaultPath returns the log location used when none is configured:
+// gocachectl/audit.jsonl under the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate audit log: %w", err)
+	}
+	return filepath.Join(dir, "gocachectl", "audit.jsonl"), nil
+}
+
+// New returns the log at path, recording operations as run by the
+// current user with the given command line
+func New(path string, command []string) *Log {
+	l := &Log{path: path, command: command}
+	if u, err := user.Current(); err == nil {
+		l.user = u.Username
+	} else {
+		l.user = os.Getenv("USER")
+	}
+	l.host, _ = os.Hostname()
+	return l
+}
+
+// Path returns the log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes a record, filling in the time, user, host and command
+// line. Each record is a single write to a file opened for appending, so
+// concurrent gocachectl processes do not interleave records.
+func (l *Log) Append(r Record) error {
+	if l == nil {
+		return nil
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.User == "" {
+		r.User = l.user
+	}
+	if r.Host == "" {
+		r.Host = l.host
+	}
+	if r.Command == nil {
+		r.Command = l.command
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Filter selects records; zero fields match everything
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	User      string
+	Operation string
+}
+
+// Match reports whether r passes the filter. Since is inclusive, Until
+// exclusive.
+func (f Filter) Match(r Record) bool {
+	switch {
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	case f.User != "" && r.User != f.User:
+		return false
+	case f.Operation != "" && r.Operation != f.Operation:
+		return false
+	}
+	return true
+}
+
+// Query returns the records matching f, oldest first. A missing log has
+// no records; lines that do not decode, e.g. one cut short by a full disk,
+// are skipped.
+func (l *Log) Query(f Filter) ([]Record, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if f.Match(r) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return records, nil
+}
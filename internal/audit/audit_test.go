@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := New(path, []string{"gocachectl", "clear", "--build"})
+
+	day := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Time: day, User: "alice", Operation: "clear", Targets: []string{"build"}, Deleted: 10, Freed: 1000},
+		{Time: day.Add(24 * time.Hour), User: "bob", Operation: "toolchain prune", Deleted: 1, Freed: 500},
+		{Time: day.Add(48 * time.Hour), Operation: "clear", Targets: []string{"test"}},
+	}
+	for _, r := range records {
+		if err := log.Append(r); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	all, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(all))
+	}
+	if all[0].Command[1] != "clear" || all[0].Host == "" {
+		t.Errorf("Expected the command line and host to be filled in, got %+v", all[0])
+	}
+	if all[2].User == "" {
+		t.Errorf("Expected the current user to be filled in")
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"user", Filter{User: "alice"}, 1},
+		{"since", Filter{Since: day.Add(24 * time.Hour)}, 2},
+		{"until", Filter{Until: day.Add(24 * time.Hour)}, 1},
+		{"operation", Filter{Operation: "clear"}, 2},
+		{"range", Filter{Since: day, Until: day.Add(48 * time.Hour), Operation: "clear"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := log.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Expected %d records, got %d: %+v", tt.want, len(got), got)
+			}
+		})
+	}
+}
+
+func TestLog_QuerySkipsBrokenLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	data := `{"time":"2025-06-01T00:00:00Z","user":"alice","operation":"clear"}
+{"time":"2025-06-02T00:00:00Z","us
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := New(path, nil).Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 || records[0].User != "alice" {
+		t.Errorf("Expected the complete record only, got %+v", records)
+	}
+}
+
+func TestRecord_AddFailure(t *testing.T) {
+	var r Record
+	for range MaxFailures + 5 {
+		r.AddFailure("permission denied")
+	}
+	if r.Errors != MaxFailures+5 || len(r.Failures) != MaxFailures {
+		t.Errorf("Expected %d errors and %d listed, got %d and %d", MaxFailures+5, MaxFailures, r.Errors, len(r.Failures))
+	}
+}
+
+func TestLog_Nil(t *testing.T) {
+	var log *Log
+	if err := log.Append(Record{Operation: "clear"}); err != nil {
+		t.Errorf("Expected a nil log to record nothing, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Benchmark cases
+const (
+	BenchCold = "cold" // an empty build cache
+	BenchWarm = "warm" // the current build cache, holding the last build
+	BenchEdit = "edit" // the current build cache after a one-file edit
+)
+
+// BenchRun is one timed build
+type BenchRun struct {
+	Seconds float64 `json:"seconds"`
+	// NewEntries and BytesWritten are what the build added to the cache
+	NewEntries   int   `json:"new_entries"`
+	BytesWritten int64 `json:"bytes_written"`
+}
+
+// BenchSummary sums up the runs of one case
+type BenchSummary struct {
+	Case string     `json:"case"`
+	Runs []BenchRun `json:"runs"`
+	// Seconds over the runs
+	MinSeconds    float64 `json:"min_seconds"`
+	MedianSeconds float64 `json:"median_seconds"`
+	MeanSeconds   float64 `json:"mean_seconds"`
+	// MedianBytesWritten is the median of what each run added to the cache
+	MedianBytesWritten int64 `json:"median_bytes_written"`
+}
+
+// Summarize sums up the runs of a case
+func Summarize(name string, runs []BenchRun) BenchSummary {
+	summary := BenchSummary{Case: name, Runs: runs}
+	if len(runs) == 0 {
+		return summary
+	}
+	seconds := make([]float64, len(runs))
+	written := make([]int64, len(runs))
+	var total float64
+	for i, run := range runs {
+		seconds[i], written[i] = run.Seconds, run.BytesWritten
+		total += run.Seconds
+	}
+	slices.Sort(seconds)
+	slices.Sort(written)
+
+	summary.MinSeconds = seconds[0]
+	summary.MeanSeconds = total / float64(len(runs))
+	mid := len(runs) / 2
+	if len(runs)%2 == 1 {
+		summary.MedianSeconds, summary.MedianBytesWritten = seconds[mid], written[mid]
+	} else {
+		summary.MedianSeconds = (seconds[mid-1] + seconds[mid]) / 2
+		summary.MedianBytesWritten = (written[mid-1] + written[mid]) / 2
+	}
+	return summary
+}
+
+// Bencher times builds of one package with an empty build cache, with the
+// current one and after an edit
+type Bencher struct {
+	// Dir is the directory the go command runs in
+	Dir string
+	// Package is the package pattern built, naming a single package
+	Package string
+	// Flags are passed to go build
+	Flags []string
+
+	build  *BuildManager
+	source string // the file edits are simulated in
+	edits  int
+}
+
+// NewBencher creates a bencher of pkg with the GOCACHE the go command uses
+// in dir
+func NewBencher(dir, pkg string, flags []string) (*Bencher, error) {
+	build, err := NewBuildManager("")
+	if err != nil {
+		return nil, err
+	}
+	source, err := editableFile(dir, pkg)
+	if err != nil {
+		return nil, err
+	}
+	return &Bencher{Dir: dir, Package: pkg, Flags: flags, build: build, source: source}, nil
+}
+
+// editableFile returns the first Go file of the single package pkg
+func editableFile(dir, pkg string) (string, error) {
+	cmd := exec.Command("go", "list", "-json=Dir,GoFiles", pkg)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run 'go list %s': %w\n%s", pkg, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	type listedPackage struct {
+		Dir     string
+		GoFiles []string
+	}
+	var packages []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var p listedPackage
+		if err := decoder.Decode(&p); err != nil {
+			return "", fmt.Errorf("failed to parse 'go list %s': %w", pkg, err)
+		}
+		packages = append(packages, p)
+	}
+	if len(packages) != 1 {
+		return "", fmt.Errorf("%s matches %d packages, not one", pkg, len(packages))
+	}
+	if len(packages[0].GoFiles) == 0 {
+		return "", fmt.Errorf("%s has no Go files to edit", pkg)
+	}
+	return filepath.Join(packages[0].Dir, packages[0].GoFiles[0]), nil
+}
+
+// Run times one build of the case
+func (b *Bencher) Run(name string) (BenchRun, error) {
+	switch name {
+	case BenchCold:
+		return b.cold()
+	case BenchWarm:
+		return b.measure(b.build, nil, nil)
+	case BenchEdit:
+		return b.edit()
+	}
+	return BenchRun{}, fmt.Errorf("unknown benchmark case %q", name)
+}
+
+// Prime builds the package once with the current cache, so that the warm
+// case finds everything the build needs
+func (b *Bencher) Prime() error {
+	return b.goBuild(nil, nil)
+}
+
+// cold builds with a new, empty GOCACHE, removed afterwards
+func (b *Bencher) cold() (BenchRun, error) {
+	dir, err := os.MkdirTemp("", "gocachectl-bench-")
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to create empty cache: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	build, err := NewBuildManager(dir)
+	if err != nil {
+		return BenchRun{}, err
+	}
+	return b.measure(build, []string{"GOCACHE=" + dir}, nil)
+}
+
+// edit builds with the package's first file changed, through an overlay so
+// the source is left alone. Each edit appends a different comment, so that
+// every run misses the cache for that package alone.
+func (b *Bencher) edit() (BenchRun, error) {
+	original, err := os.ReadFile(b.source)
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to read %s: %w", b.source, err)
+	}
+	dir, err := os.MkdirTemp("", "gocachectl-bench-")
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to create overlay: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	b.edits++
+	edited := filepath.Join(dir, filepath.Base(b.source))
+	comment := fmt.Sprintf("\n// gocachectl bench edit %d %d\n", b.edits, time.Now().UnixNano())
+	if err := os.WriteFile(edited, append(original, comment...), 0o644); err != nil {
+		return BenchRun{}, fmt.Errorf("failed to write overlay: %w", err)
+	}
+	overlay, err := json.Marshal(map[string]any{"Replace": map[string]string{b.source: edited}})
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to encode overlay: %w", err)
+	}
+	overlayFile := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o644); err != nil {
+		return BenchRun{}, fmt.Errorf("failed to write overlay: %w", err)
+	}
+
+	return b.measure(b.build, nil, []string{"-overlay", overlayFile})
+}
+
+// measure times a build with the extra environment env and flags and
+// counts what it wrote to the build cache it uses
+func (b *Bencher) measure(build *BuildManager, env, flags []string) (BenchRun, error) {
+	before, err := build.Snapshot()
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+	start := time.Now()
+	if err := b.goBuild(env, flags); err != nil {
+		return BenchRun{}, err
+	}
+	run := BenchRun{Seconds: time.Since(start).Seconds()}
+	after, err := build.Snapshot()
+	if err != nil {
+		return BenchRun{}, fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+	run.NewEntries, run.BytesWritten = growth(before, after)
+	return run, nil
+}
+
+func (b *Bencher) goBuild(env, flags []string) error {
+	args := append([]string{"build", "-o", os.DevNull}, flags...)
+	args = append(args, b.Flags...)
+	cmd := exec.Command("go", append(args, b.Package)...)
+	cmd.Dir = b.Dir
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run 'go build %s': %w\n%s", b.Package, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
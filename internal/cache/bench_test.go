@@ -0,0 +1,31 @@
+package cache
+
+import "testing"
+
+func TestSummarize(t *testing.T) {
+	runs := []BenchRun{
+		{Seconds: 4, BytesWritten: 300},
+		{Seconds: 1, BytesWritten: 100},
+		{Seconds: 2, BytesWritten: 200},
+		{Seconds: 3, BytesWritten: 900},
+	}
+	summary := Summarize(BenchWarm, runs)
+	if summary.Case != BenchWarm || len(summary.Runs) != 4 {
+		t.Errorf("Expected the 4 warm runs, got %s with %d", summary.Case, len(summary.Runs))
+	}
+	if summary.MinSeconds != 1 || summary.MedianSeconds != 2.5 || summary.MeanSeconds != 2.5 {
+		t.Errorf("Expected min 1, median 2.5 and mean 2.5, got %v, %v and %v",
+			summary.MinSeconds, summary.MedianSeconds, summary.MeanSeconds)
+	}
+	if summary.MedianBytesWritten != 250 {
+		t.Errorf("Expected a median of 250 bytes written, got %d", summary.MedianBytesWritten)
+	}
+
+	summary = Summarize(BenchCold, runs[:3])
+	if summary.MedianSeconds != 2 || summary.MedianBytesWritten != 200 {
+		t.Errorf("Expected the middle run, got %v seconds and %d bytes", summary.MedianSeconds, summary.MedianBytesWritten)
+	}
+	if summary := Summarize(BenchEdit, nil); summary.MedianSeconds != 0 {
+		t.Errorf("Expected no times without runs, got %v", summary.MedianSeconds)
+	}
+}
@@ -5,15 +5,29 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 )
 
 // Manager manages the Go build cache
 type BuildManager struct {
-	cacheDir string
+	cacheDir  string
+	breakdown Breakdown
+	remover
+	guard
+	ageBuckets
 }
 
-var _ CacheManager = (*BuildManager)(nil)
+var (
+	_ CacheManager      = (*BuildManager)(nil)
+	_ EntryLister       = (*BuildManager)(nil)
+	_ RemoverSetter     = (*BuildManager)(nil)
+	_ ConcurrencySetter = (*BuildManager)(nil)
+	_ Trimmer           = (*BuildManager)(nil)
+	_ AgeBucketsSetter  = (*BuildManager)(nil)
+	_ BreakdownSetter   = (*BuildManager)(nil)
+	_ ObjectSelector    = (*BuildManager)(nil)
+)
 
 // NewManager creates a new build cache manager
 func NewBuildManager(cacheDir string) (*BuildManager, error) {
@@ -37,10 +51,15 @@ func NewBuildManager(cacheDir string) (*BuildManager, error) {
 
 // GetStats retrieves build cache statistics
 func (m *BuildManager) GetStats() (Stats, error) {
+	now := time.Now()
 	stats := &BuildCacheStats{
 		Location:    m.cacheDir,
-		OldestEntry: time.Now(),
+		OldestEntry: now,
+		LastTrim:    lastTrim(m.cacheDir),
+		Ages:        m.ageHistogram(),
 	}
+	stats.NextTrim = nextTrim(stats.LastTrim, now)
+	cutoff := trimCutoff(stats.NextTrim)
 
 	// Walk the cache directory
 	err := filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
@@ -48,13 +67,11 @@ func (m *BuildManager) GetStats() (Stats, error) {
 			return nil // Skip errors, continue walking
 		}
 
-		// Skip directories
-		if d.IsDir() {
-			return nil
+		// Skip directories, and quarantined entries altogether
+		if isTrashDir(d) {
+			return filepath.SkipDir
 		}
-
-		// Exclude test entries
-		if isTestEntry(path) {
+		if d.IsDir() {
 			return nil
 		}
 
@@ -64,6 +81,17 @@ func (m *BuildManager) GetStats() (Stats, error) {
 			return nil // Skip files we can't read
 		}
 
+		// The go command trims test entries along with build entries
+		if isTrimmable(m.cacheDir, path) && info.ModTime().Before(cutoff) {
+			stats.TrimmableEntries++
+			stats.TrimmableSize += info.Size()
+		}
+
+		// Exclude test entries and the go command's bookkeeping
+		if isTestEntry(path) || isCacheMetadata(m.cacheDir, path) {
+			return nil
+		}
+
 		// Update statistics
 		stats.EntryCount++
 		stats.Size += info.Size()
@@ -77,23 +105,9 @@ func (m *BuildManager) GetStats() (Stats, error) {
 			stats.NewestEntry = modTime
 		}
 
-		// Size distribution
-		const (
-			MB          = 1024 * 1024
-			smallLimit  = MB
-			mediumLimit = 10 * MB
-		)
-
-		if info.Size() < smallLimit {
-			stats.Distribution.Small++
-			stats.Distribution.SmallSize += info.Size()
-		} else if info.Size() < mediumLimit {
-			stats.Distribution.Medium++
-			stats.Distribution.MediumSize += info.Size()
-		} else {
-			stats.Distribution.Large++
-			stats.Distribution.LargeSize += info.Size()
-		}
+		// Size and age distribution
+		stats.Distribution.add(info.Size())
+		stats.Ages.add(now.Sub(modTime), info.Size())
 
 		return nil
 	})
@@ -107,25 +121,66 @@ func (m *BuildManager) GetStats() (Stats, error) {
 		stats.OldestEntry = time.Time{}
 	}
 
+	if m.breakdown.ByToolchain || m.breakdown.ByPlatform {
+		index, err := indexObjects(m.cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		if m.breakdown.ByToolchain {
+			stats.Toolchains = index.groups(func(o ObjectInfo) string { return o.GoVersion }, stats.EntryCount, stats.Size)
+			sortByVersion(stats.Toolchains)
+		}
+		if m.breakdown.ByPlatform {
+			stats.Platforms = index.groups(ObjectInfo.Platform, stats.EntryCount, stats.Size)
+			sortBySize(stats.Platforms)
+		}
+	}
+
 	return stats, nil
 }
 
-// Clear removes all build cache entries
+// SetBreakdown sets the groupings GetStats adds
+func (m *BuildManager) SetBreakdown(b Breakdown) {
+	m.breakdown = b
+}
+
+// SelectObjects returns the outputs whose producer satisfies keep, with
+// the action files naming them
+func (m *BuildManager) SelectObjects(keep func(ObjectInfo) bool) ([]Entry, error) {
+	index, err := indexObjects(m.cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return index.selectObjects(keep), nil
+}
+
+// Clear removes all build cache entries, once no go command is using the
+// cache
 func (m *BuildManager) Clear() (int, int64, error) {
+	release, err := m.acquire(m.cacheDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to clear build cache: %w", err)
+	}
+	defer release()
+
 	var deletedCount int
 	var freedSpace int64
+	failures := &ClearError{}
 
-	err := filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		// Skip the root directory
-		if path == m.cacheDir {
+		// Skip the root directory and the go command's bookkeeping
+		if path == m.cacheDir || isCacheMetadata(m.cacheDir, path) {
 			return nil
 		}
 
-		// Skip directories (will be removed if empty)
+		// Skip directories (will be removed if empty) and the trash
+		if isTrashDir(d) {
+			return filepath.SkipDir
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -135,20 +190,25 @@ func (m *BuildManager) Clear() (int, int64, error) {
 			return nil
 		}
 
-		// Get file size before deletion
 		info, err := d.Info()
-		if err == nil {
-			freedSpace += info.Size()
+		if err != nil {
+			return nil
 		}
 
-		// Delete file
-		if err := os.Remove(path); err == nil {
-			deletedCount++
+		// Delete file, counting it only once it is gone
+		if err := m.remove(path); err != nil {
+			failures.add(path, err)
+			return nil
 		}
+		deletedCount++
+		freedSpace += info.Size()
 
 		return nil
 	})
 
+	if err == nil {
+		err = failures.err()
+	}
 	if err != nil {
 		return deletedCount, freedSpace, fmt.Errorf("failed to clear build cache: %w", err)
 	}
@@ -156,6 +216,67 @@ func (m *BuildManager) Clear() (int, int64, error) {
 	return deletedCount, freedSpace, nil
 }
 
+// ListEntries returns every build cache file, excluding test entries
+func (m *BuildManager) ListEntries() ([]Entry, error) {
+	entries, err := listFiles(m.cacheDir, func(path string) bool {
+		return !isTestEntry(path) && !isCacheMetadata(m.cacheDir, path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk build cache: %w", err)
+	}
+	return entries, nil
+}
+
+// RemoveEntries removes build cache files, once no go command is using the
+// cache
+func (m *BuildManager) RemoveEntries(entries []Entry) (int, int64, error) {
+	release, err := m.acquire(m.cacheDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to remove build cache entries: %w", err)
+	}
+	defer release()
+
+	deleted, freed, err := m.removeFiles(m.cacheDir, entries)
+	if err != nil {
+		return deleted, freed, fmt.Errorf("failed to remove build cache entries: %w", err)
+	}
+	return deleted, freed, nil
+}
+
+// TrimEntries returns the entries, build and test alike, that the go
+// command's trim would remove at now
+func (m *BuildManager) TrimEntries(now time.Time) ([]Entry, error) {
+	cutoff := trimCutoff(now)
+	entries, err := listFiles(m.cacheDir, func(path string) bool { return isTrimmable(m.cacheDir, path) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk build cache: %w", err)
+	}
+	return slices.DeleteFunc(entries, func(e Entry) bool { return !e.ModTime.Before(cutoff) }), nil
+}
+
+// Trim trims the cache as the go command would at now, whenever it last
+// did, and records the trim in trim.txt
+func (m *BuildManager) Trim(now time.Time) (int, int64, error) {
+	release, err := m.acquire(m.cacheDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to trim build cache: %w", err)
+	}
+	defer release()
+
+	entries, err := m.TrimEntries(now)
+	if err != nil {
+		return 0, 0, err
+	}
+	deleted, freed, err := m.removeFiles(m.cacheDir, entries)
+
+	// Like the go command, record the trim even if some entries remain:
+	// the next trim retries them
+	if recordErr := writeTrimTime(m.cacheDir, now); recordErr != nil && err == nil {
+		err = recordErr
+	}
+	return deleted, freed, err
+}
+
 // GetLocation returns the cache directory path
 func (m *BuildManager) GetLocation() string {
 	return m.cacheDir
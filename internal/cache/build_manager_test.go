@@ -91,3 +91,46 @@ func TestBuildManager_Clear(t *testing.T) {
 		t.Error("t1-d should still exist")
 	}
 }
+
+func TestBuildManager_Entries(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"00/t1-d": "ok \ttest1",
+		"00/b1-d": "\x7fELFbuild",
+		"01/b2-a": "v1 action",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr, err := NewBuildManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+
+	entries, err := mgr.ListEntries()
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "00/b1-d" || entries[1].Name != "01/b2-a" {
+		t.Fatalf("Expected the two build entries, got %+v", entries)
+	}
+
+	deleted, freed, err := mgr.RemoveEntries(entries[:1])
+	if err != nil {
+		t.Fatalf("RemoveEntries failed: %v", err)
+	}
+	if deleted != 1 || freed != int64(len(files["00/b1-d"])) {
+		t.Errorf("Expected 1 entry and %d bytes removed, got %d and %d", len(files["00/b1-d"]), deleted, freed)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "00", "t1-d")); err != nil {
+		t.Errorf("Expected the test entry to be kept: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// trimFile records when the go command last trimmed the build cache. The
+// go command locks it while reading and writing it, so clears lock it too.
+const trimFile = "trim.txt"
+
+// pollInterval is how often a waiting clear checks the cache again
+var pollInterval = time.Second
+
+// Process is a running process using a cache
+type Process struct {
+	PID     int    `json:"pid"`
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// ConcurrencyMode decides what removing entries does while go commands
+// use the cache
+type ConcurrencyMode int
+
+const (
+	// Refuse fails while the cache is in use; the default
+	Refuse ConcurrencyMode = iota
+	// Wait waits until the cache is no longer in use
+	Wait
+	// Force removes entries regardless
+	Force
+)
+
+// Concurrency configures how a manager coordinates with go commands
+type Concurrency struct {
+	Mode ConcurrencyMode
+	// Timeout bounds a Wait; zero waits as long as it takes
+	Timeout time.Duration
+}
+
+// ConcurrencySetter is implemented by managers of caches the go command
+// writes while it runs
+type ConcurrencySetter interface {
+	SetConcurrency(c Concurrency)
+}
+
+// BusyError reports a cache in use by running processes, or locked by a
+// go command reading or writing trim.txt
+type BusyError struct {
+	Dir       string
+	Processes []Process
+}
+
+func (e *BusyError) Error() string {
+	if len(e.Processes) == 0 {
+		return fmt.Sprintf("%s is locked by a running go command", e.Dir)
+	}
+
+	var procs []string
+	for _, p := range e.Processes {
+		procs = append(procs, fmt.Sprintf("%s (pid %d)", p.Name, p.PID))
+	}
+	return fmt.Sprintf("%s is in use by %s", e.Dir, strings.Join(procs, ", "))
+}
+
+// guard keeps removals from racing go commands that use the cache.
+// Managers of caches the go command writes embed it.
+type guard struct {
+	concurrency Concurrency
+	// users finds the processes using a cache; CacheUsers unless a test
+	// simulates some
+	users func(dir string) ([]Process, error)
+}
+
+// SetConcurrency sets how the manager coordinates with go commands
+func (g *guard) SetConcurrency(c Concurrency) {
+	g.concurrency = c
+}
+
+// acquire refuses or waits, as configured, while processes use the cache
+// in dir, then locks its trim.txt. The caller must call release when done
+// removing entries.
+func (g *guard) acquire(dir string) (release func(), err error) {
+	if g.concurrency.Mode == Force {
+		return func() {}, nil
+	}
+
+	var deadline time.Time
+	if g.concurrency.Timeout > 0 {
+		deadline = time.Now().Add(g.concurrency.Timeout)
+	}
+	for {
+		release, busy, err := g.tryAcquire(dir)
+		if err != nil || busy == nil {
+			return release, err
+		}
+		if g.concurrency.Mode == Refuse || !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, busy
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// tryAcquire locks trim.txt unless the cache is busy. The lock file is
+// created if missing, which at worst makes the next go command trim early.
+func (g *guard) tryAcquire(dir string) (func(), *BusyError, error) {
+	users := g.users
+	if users == nil {
+		users = CacheUsers
+	}
+	// Without /proc only the lock protects the cache
+	if procs, err := users(dir); err == nil && len(procs) > 0 {
+		return nil, &BusyError{Dir: dir, Processes: procs}, nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, trimFile), os.O_RDONLY|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open cache lock: %w", err)
+	}
+	locked, err := tryLock(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to lock cache: %w", err)
+	}
+	if !locked {
+		f.Close()
+		return nil, &BusyError{Dir: dir}, nil
+	}
+
+	return func() {
+		_ = unlock(f)
+		f.Close()
+	}, nil, nil
+}
+
+// isCacheMetadata reports whether path is one of the files the go command
+// keeps at the top of the build cache rather than a cache entry. Like
+// go clean -cache, clearing leaves them alone.
+func isCacheMetadata(cacheDir, path string) bool {
+	return filepath.Dir(path) == filepath.Clean(cacheDir) && (filepath.Base(path) == trimFile || filepath.Base(path) == "README")
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newGuardedCache creates a build cache with a single entry, whose manager
+// sees the running processes users returns
+func newGuardedCache(t *testing.T, users ...Process) (*BuildManager, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	entry := filepath.Join(tmpDir, "00", "b1-d")
+	if err := os.MkdirAll(filepath.Dir(entry), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry, []byte("\x7fELFbuild"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewBuildManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	mgr.users = func(string) ([]Process, error) { return users, nil }
+	return mgr, entry
+}
+
+// lockCache simulates a go command writing trim.txt in dir, returning a
+// function that finishes the write
+func lockCache(t *testing.T, dir string) func() {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(dir, trimFile), os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := tryLock(f); !locked || err != nil {
+		f.Close()
+		t.Skipf("advisory locks unavailable: %v", err)
+	}
+	return func() {
+		unlock(f)
+		f.Close()
+	}
+}
+
+func TestBuildManager_ClearRefusesWhileLocked(t *testing.T) {
+	mgr, entry := newGuardedCache(t)
+	unlock := lockCache(t, mgr.GetLocation())
+	defer unlock()
+
+	deleted, _, err := mgr.Clear()
+	var busy *BusyError
+	if !errors.As(err, &busy) {
+		t.Fatalf("Expected a BusyError, got %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("Expected nothing deleted, got %d", deleted)
+	}
+	if _, err := os.Stat(entry); err != nil {
+		t.Errorf("Expected the entry to be kept: %v", err)
+	}
+}
+
+func TestBuildManager_ClearRefusesWhileInUse(t *testing.T) {
+	mgr, entry := newGuardedCache(t, Process{PID: 42, Name: "go", Command: "go build ./..."})
+
+	_, _, err := mgr.Clear()
+	var busy *BusyError
+	if !errors.As(err, &busy) || len(busy.Processes) != 1 || busy.Processes[0].PID != 42 {
+		t.Fatalf("Expected a BusyError naming pid 42, got %v", err)
+	}
+	if _, err := os.Stat(entry); err != nil {
+		t.Errorf("Expected the entry to be kept: %v", err)
+	}
+}
+
+func TestBuildManager_ClearWaitsForLock(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+
+	mgr, entry := newGuardedCache(t)
+	unlock := lockCache(t, mgr.GetLocation())
+	time.AfterFunc(50*time.Millisecond, unlock)
+
+	mgr.SetConcurrency(Concurrency{Mode: Wait, Timeout: 10 * time.Second})
+	deleted, _, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 entry deleted, got %d", deleted)
+	}
+	if _, err := os.Stat(entry); !os.IsNotExist(err) {
+		t.Errorf("Expected the entry to be deleted, got %v", err)
+	}
+	// The lock file outlives the clear, as with go clean -cache
+	if _, err := os.Stat(filepath.Join(mgr.GetLocation(), trimFile)); err != nil {
+		t.Errorf("Expected trim.txt to be kept: %v", err)
+	}
+}
+
+func TestBuildManager_ClearWaitTimesOut(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+
+	mgr, _ := newGuardedCache(t)
+	unlock := lockCache(t, mgr.GetLocation())
+	defer unlock()
+
+	mgr.SetConcurrency(Concurrency{Mode: Wait, Timeout: 50 * time.Millisecond})
+	var busy *BusyError
+	if _, _, err := mgr.Clear(); !errors.As(err, &busy) {
+		t.Fatalf("Expected a BusyError after the timeout, got %v", err)
+	}
+}
+
+func TestTestManager_ClearForce(t *testing.T) {
+	tmpDir := t.TempDir()
+	entry := filepath.Join(tmpDir, "t1-d")
+	if err := os.WriteFile(entry, []byte("ok \ttest1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := NewTestManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewTestManager failed: %v", err)
+	}
+	mgr.users = func(string) ([]Process, error) {
+		return []Process{{PID: 42, Name: "go"}}, nil
+	}
+	unlock := lockCache(t, tmpDir)
+	defer unlock()
+
+	var busy *BusyError
+	if _, _, err := mgr.Clear(); !errors.As(err, &busy) {
+		t.Fatalf("Expected a BusyError, got %v", err)
+	}
+
+	mgr.SetConcurrency(Concurrency{Mode: Force})
+	deleted, _, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 entry deleted, got %d", deleted)
+	}
+}
+
+func TestClearOptions_Concurrency(t *testing.T) {
+	tests := []struct {
+		opts ClearOptions
+		want Concurrency
+	}{
+		{ClearOptions{}, Concurrency{Mode: Refuse}},
+		{ClearOptions{Wait: time.Minute}, Concurrency{Mode: Wait, Timeout: time.Minute}},
+		{ClearOptions{Force: true}, Concurrency{Mode: Force}},
+		{ClearOptions{Force: true, Wait: time.Minute}, Concurrency{Mode: Wait, Timeout: time.Minute}},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Concurrency(); got != tt.want {
+			t.Errorf("%+v.Concurrency() = %+v, want %+v", tt.opts, got, tt.want)
+		}
+	}
+}
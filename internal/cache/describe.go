@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// Kinds of cache entries
+const (
+	EntryAction  = "action"  // an action file of the build cache
+	EntryArchive = "archive" // a compiled package
+	EntryBinary  = "binary"  // a linked executable
+	EntryOutput  = "output"  // any other build cache output
+	EntryTest    = "test"    // a cached test result
+	EntryModule  = "module"  // an extracted module version
+)
+
+// testTail is how much of a test result is read to find the package line
+// go test ends it with
+const testTail = 4096
+
+// EntryAttrs describes what a cache entry holds, as far as can be told
+// from its content
+type EntryAttrs struct {
+	Kind      string `json:"kind"`
+	Toolchain string `json:"toolchain,omitempty"` // e.g. go1.24.1
+	Platform  string `json:"platform,omitempty"`  // e.g. linux/amd64
+	Package   string `json:"package,omitempty"`   // import path of the package compiled or tested
+}
+
+// EntryDescriber is implemented by managers that can tell what their
+// entries hold
+type EntryDescriber interface {
+	DescribeEntry(e Entry) EntryAttrs
+}
+
+var (
+	_ EntryDescriber = (*BuildManager)(nil)
+	_ EntryDescriber = (*TestManager)(nil)
+	_ EntryDescriber = (*ModManager)(nil)
+)
+
+// DescribeEntry tells action files from outputs, and reads who produced
+// compiled packages and binaries and which package they hold
+func (m *BuildManager) DescribeEntry(e Entry) EntryAttrs {
+	if strings.HasSuffix(e.Path, "-a") {
+		return EntryAttrs{Kind: EntryAction}
+	}
+	attrs := EntryAttrs{Kind: EntryOutput}
+	info, ok := readObjectInfo(e.Path)
+	if !ok {
+		return attrs
+	}
+	attrs.Toolchain = info.GoVersion
+	if info.GOOS != "" {
+		attrs.Platform = info.GOOS + "/" + info.GOARCH
+	}
+	if pkg, ok := objectPackage(e.Path); ok {
+		attrs.Package = pkg
+		attrs.Kind = EntryBinary
+		if isArchive(e.Path) {
+			attrs.Kind = EntryArchive
+		}
+	}
+	return attrs
+}
+
+// isArchive reports whether the file at path is an archive
+func isArchive(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(archiveMagic))
+	_, err = io.ReadFull(f, magic)
+	return err == nil && string(magic) == archiveMagic
+}
+
+// DescribeEntry reads the tested package from the last line of the result
+func (m *TestManager) DescribeEntry(e Entry) EntryAttrs {
+	return EntryAttrs{Kind: EntryTest, Package: testPackage(e.Path)}
+}
+
+// testPackage returns the package of the test result at path, from the
+// "ok" or "FAIL" line go test ends it with
+func testPackage(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	tail := make([]byte, min(info.Size(), testTail))
+	if _, err := f.ReadAt(tail, info.Size()-int64(len(tail))); err != nil {
+		return ""
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		status, rest, ok := strings.Cut(string(lines[i]), "\t")
+		if status = strings.TrimSpace(status); !ok || status != "ok" && status != "FAIL" {
+			continue
+		}
+		pkg, _, _ := strings.Cut(rest, "\t")
+		return pkg
+	}
+	return ""
+}
+
+// DescribeEntry describes a module version, whose module path and version
+// the entry already holds
+func (m *ModManager) DescribeEntry(e Entry) EntryAttrs {
+	return EntryAttrs{Kind: EntryModule}
+}
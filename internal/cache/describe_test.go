@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildManager_DescribeEntry(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) Entry {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return Entry{Name: name, Path: path}
+	}
+	mgr := &BuildManager{cacheDir: dir}
+
+	got := mgr.DescribeEntry(write("0a01-d", fakeArchive("example.com/foo")))
+	want := EntryAttrs{Kind: EntryArchive, Toolchain: "go1.24.1", Platform: "linux/amd64", Package: "example.com/foo"}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := mgr.DescribeEntry(write("0b01-a", []byte("v1 0b01 0a01 10 0\n"))); got != (EntryAttrs{Kind: EntryAction}) {
+		t.Errorf("Expected an action, got %+v", got)
+	}
+	if got := mgr.DescribeEntry(write("0c01-d", []byte(`{"ImportPath":"x"}`))); got != (EntryAttrs{Kind: EntryOutput}) {
+		t.Errorf("Expected an unknown output, got %+v", got)
+	}
+}
+
+func TestTestManager_DescribeEntry(t *testing.T) {
+	dir := t.TempDir()
+	mgr := &TestManager{cacheDir: dir}
+	for content, want := range map[string]string{
+		"=== RUN   TestFoo\n--- PASS: TestFoo (0.00s)\nPASS\nok  \texample.com/foo\t0.01s\n": "example.com/foo",
+		"--- FAIL: TestBar (0.00s)\nFAIL\nFAIL\texample.com/bar\t0.02s\n":                    "example.com/bar",
+		"PASS\n": "",
+	} {
+		path := filepath.Join(dir, "0d01-d")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got := mgr.DescribeEntry(Entry{Path: path})
+		if got.Kind != EntryTest || got.Package != want {
+			t.Errorf("Expected a test of %q, got %+v", want, got)
+		}
+	}
+}
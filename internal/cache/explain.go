@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of inputs to an action ID
+const (
+	InputToolchain  = "toolchain"
+	InputSource     = "source"
+	InputEnv        = "env"
+	InputFlag       = "flag"
+	InputDependency = "dependency"
+	InputConfig     = "config"
+)
+
+// hashLine matches the lines GODEBUG=gocachehash=1 prints for an action,
+// `HASH[build example.com/foo]: "file foo.go Wl8y..."`, and the final
+// action ID, `HASH[build example.com/foo]: 6281...`
+var hashLine = regexp.MustCompile(`^HASH\[(.+?)\]: (".*"|[0-9a-f]{64})$`)
+
+// envLine matches environment inputs, e.g. "GOAMD64=v1"
+var envLine = regexp.MustCompile(`^[A-Z][A-Z0-9_]*=`)
+
+// HashInput is one line the go command hashed into an action ID
+type HashInput struct {
+	Kind  string `json:"kind"`  // toolchain, source, env, flag, dependency or config
+	Key   string `json:"key"`   // e.g. foo.go, GOAMD64 or example.com/bar
+	Value string `json:"value"` // the rest of the line, e.g. a file's content hash
+}
+
+// ActionHash is the action ID of one step of a build and what went into it
+type ActionHash struct {
+	Action string      `json:"action"` // e.g. "build example.com/foo"
+	ID     string      `json:"id"`
+	Inputs []HashInput `json:"inputs"`
+}
+
+// HashTrace holds the actions hashed during a build, keyed by action
+type HashTrace map[string]*ActionHash
+
+// HashBaseline is a recorded trace to explain later builds against
+type HashBaseline struct {
+	Package  string    `json:"package"`
+	Recorded time.Time `json:"recorded"`
+	Actions  HashTrace `json:"actions"`
+}
+
+// InputChange is an input of an action that differs between two builds
+type InputChange struct {
+	Action string `json:"action"` // e.g. "build example.com/foo"
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Old    string `json:"old,omitempty"` // empty when added
+	New    string `json:"new,omitempty"` // empty when removed
+}
+
+// ParseHashTrace reads the output of a build run with
+// GODEBUG=gocachehash=1, ignoring everything else the build printed
+func ParseHashTrace(r io.Reader) (HashTrace, error) {
+	trace := make(HashTrace)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		m := hashLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		action := trace[m[1]]
+		if action == nil {
+			action = &ActionHash{Action: m[1]}
+			trace[m[1]] = action
+		}
+
+		if !strings.HasPrefix(m[2], `"`) {
+			action.ID = m[2]
+			continue
+		}
+		line, err := strconv.Unquote(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse hash input %s: %w", m[2], err)
+		}
+		action.Inputs = append(action.Inputs, classifyInput(strings.TrimSuffix(line, "\n"), len(action.Inputs) == 0))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hash trace: %w", err)
+	}
+	return trace, nil
+}
+
+// classifyInput names and keys a hashed line, so that it can be matched
+// with the same input of another build. The first input of every action is
+// the toolchain version.
+func classifyInput(line string, first bool) HashInput {
+	if first {
+		return HashInput{Kind: InputToolchain, Key: "go", Value: line}
+	}
+	word, rest, _ := strings.Cut(line, " ")
+	switch {
+	case envLine.MatchString(line):
+		key, value, _ := strings.Cut(line, "=")
+		return HashInput{Kind: InputEnv, Key: key, Value: value}
+	case word == "file" || word == "embed" || word == "embedfile":
+		name, hash, _ := strings.Cut(rest, " ")
+		return HashInput{Kind: InputSource, Key: name, Value: hash}
+	case word == "packagefile" && strings.Contains(rest, "="):
+		path, id, _ := strings.Cut(rest, "=")
+		return HashInput{Kind: InputDependency, Key: path, Value: id}
+	case word == "import" && !strings.HasPrefix(rest, `"`):
+		path, id, _ := strings.Cut(rest, " ")
+		return HashInput{Kind: InputDependency, Key: path, Value: id}
+	case word == "compile" || word == "link" || word == "asm" || word == "cgo" || strings.HasSuffix(word, "flags"):
+		return HashInput{Kind: InputFlag, Key: word, Value: rest}
+	}
+	return HashInput{Kind: InputConfig, Key: word, Value: rest}
+}
+
+// inputKeys identifies each input within its action. Inputs sharing a
+// key, like the "compile" mode and tool lines, are told apart by position.
+func inputKeys(inputs []HashInput) []string {
+	keys := make([]string, len(inputs))
+	seen := make(map[string]int)
+	for i, in := range inputs {
+		key := in.Kind + " " + in.Key
+		keys[i] = fmt.Sprintf("%s#%d", key, seen[key])
+		seen[key]++
+	}
+	return keys
+}
+
+// toolID returns the tool version a compile or link line starts with,
+// before its flags, e.g. "compile version go1.24.1"
+func toolID(value string) string {
+	id, _, _ := strings.Cut(value, " [")
+	return id
+}
+
+// changeKind is the kind of a changed input: a tool line whose version
+// changed is a toolchain change rather than a flag change
+func changeKind(old, new HashInput) string {
+	if new.Kind == InputFlag && old.Value != "" && new.Value != "" && toolID(old.Value) != toolID(new.Value) {
+		return InputToolchain
+	}
+	return new.Kind
+}
+
+// Diff returns the inputs of action that differ between the old and new
+// trace. A changed dependency is followed into the dependency's own action,
+// so the changes that caused it are listed too.
+func Diff(old, new HashTrace, action string) []InputChange {
+	var changes []InputChange
+	seen := make(map[string]bool)
+	var walk func(action string)
+	walk = func(action string) {
+		if seen[action] {
+			return
+		}
+		seen[action] = true
+		before, after := old[action], new[action]
+		if before == nil || after == nil || before.ID == after.ID && before.ID != "" {
+			return
+		}
+
+		beforeInputs := make(map[string]HashInput)
+		beforeKeys := inputKeys(before.Inputs)
+		for i, in := range before.Inputs {
+			beforeInputs[beforeKeys[i]] = in
+		}
+		var deps []string
+		for i, key := range inputKeys(after.Inputs) {
+			in := after.Inputs[i]
+			prev, ok := beforeInputs[key]
+			delete(beforeInputs, key)
+			if ok && prev.Value == in.Value {
+				continue
+			}
+			changes = append(changes, InputChange{Action: action, Kind: changeKind(prev, in), Key: in.Key, Old: prev.Value, New: in.Value})
+			if in.Kind == InputDependency {
+				deps = append(deps, in.Key)
+			}
+		}
+		for i, in := range before.Inputs {
+			if _, ok := beforeInputs[beforeKeys[i]]; ok {
+				changes = append(changes, InputChange{Action: action, Kind: in.Kind, Key: in.Key, Old: in.Value})
+			}
+		}
+
+		for _, dep := range deps {
+			walk("build " + dep)
+		}
+	}
+	walk(action)
+	return changes
+}
+
+// TraceBuild builds pkg with the go command under GODEBUG=gocachehash=1 in
+// dir, discarding the result, and returns the actions it hashed. flags are
+// passed on to go build.
+func TraceBuild(dir, pkg string, flags []string) (HashTrace, error) {
+	args := append([]string{"build", "-o", os.DevNull}, flags...)
+	cmd := exec.Command("go", append(args, pkg)...)
+	cmd.Dir = dir
+	godebug := "gocachehash=1"
+	if current := os.Getenv("GODEBUG"); current != "" {
+		godebug = current + "," + godebug
+	}
+	cmd.Env = append(os.Environ(), "GODEBUG="+godebug)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run 'go build %s': %w\n%s", pkg, err, buildErrors(stderr.Bytes()))
+	}
+	return ParseHashTrace(&stderr)
+}
+
+// buildErrors returns what a failed build printed besides its hash trace
+func buildErrors(output []byte) string {
+	var lines []string
+	for line := range strings.Lines(string(output)) {
+		if !strings.HasPrefix(line, "HASH") {
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ImportPath returns the import path of the package pattern pkg, which must
+// name a single package, as seen from dir
+func ImportPath(dir, pkg string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("failed to run 'go list %s': %s", pkg, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to run 'go list %s': %w", pkg, err)
+	}
+	paths := strings.Fields(string(output))
+	if len(paths) != 1 {
+		return "", fmt.Errorf("%s matches %d packages, not one", pkg, len(paths))
+	}
+	return paths[0], nil
+}
+
+// HasAction reports whether the build cache holds the action id together
+// with the output it names, so that a build with that action ID would hit
+func (m *BuildManager) HasAction(id string) bool {
+	if len(id) < 2 {
+		return false
+	}
+	output, ok := actionOutput(filepath.Join(m.cacheDir, id[:2], id+"-a"))
+	if !ok {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(m.cacheDir, output[:min(2, len(output))], output+"-d"))
+	return err == nil
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// actionID derives a fake action ID from the inputs of an action
+func actionID(inputs string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(inputs)))
+}
+
+// traceOf returns the hash trace of a build of example.com/app importing
+// example.com/lib, with lib's foo.go and GOAMD64 as given
+func traceOf(t *testing.T, fooHash, goamd64 string) HashTrace {
+	t.Helper()
+	lib := fooHash[:4] + goamd64
+	output := strings.Join([]string{
+		`HASH[moduleIndex]`,
+		`HASH[moduleIndex]: "go1.24.1"`,
+		`HASH[build example.com/lib]: "go1.24.1"`,
+		`HASH[build example.com/lib]: "compile\n"`,
+		`HASH[build example.com/lib]: "compile compile version go1.24.1 [] []\n"`,
+		`HASH[build example.com/lib]: "GOAMD64=` + goamd64 + `\n"`,
+		`HASH[build example.com/lib]: "file foo.go ` + fooHash + `\n"`,
+		`HASH[build example.com/lib]: ` + actionID("lib "+lib),
+		`HASH /src/lib/foo.go: 5a5f32666683b974eaa02b580447d0be61cd820d391880b245bee1d5025620d0`,
+		`HASH[build example.com/app]: "go1.24.1"`,
+		`HASH[build example.com/app]: "compile\n"`,
+		`HASH[build example.com/app]: "GOAMD64=` + goamd64 + `\n"`,
+		`HASH[build example.com/app]: "file main.go m4xzW9ykvU4Ly_pPoLjl\n"`,
+		`HASH[build example.com/app]: "import example.com/lib ` + lib + `\n"`,
+		`HASH[build example.com/app]: ` + actionID("app "+lib),
+		`# example.com/app`,
+	}, "\n")
+
+	trace, err := ParseHashTrace(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ParseHashTrace failed: %v", err)
+	}
+	return trace
+}
+
+func TestParseHashTrace(t *testing.T) {
+	trace := traceOf(t, "aaaa1111", "v1")
+	app := trace["build example.com/app"]
+	if app == nil || app.ID != actionID("app aaaav1") {
+		t.Fatalf("Expected the app action and its ID, got %+v", app)
+	}
+	want := []HashInput{
+		{Kind: InputToolchain, Key: "go", Value: "go1.24.1"},
+		{Kind: InputFlag, Key: "compile"},
+		{Kind: InputEnv, Key: "GOAMD64", Value: "v1"},
+		{Kind: InputSource, Key: "main.go", Value: "m4xzW9ykvU4Ly_pPoLjl"},
+		{Kind: InputDependency, Key: "example.com/lib", Value: "aaaav1"},
+	}
+	if !slices.Equal(app.Inputs, want) {
+		t.Errorf("Expected inputs %+v, got %+v", want, app.Inputs)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := traceOf(t, "aaaa1111", "v1")
+
+	if changes := Diff(old, traceOf(t, "aaaa1111", "v1"), "build example.com/app"); len(changes) != 0 {
+		t.Errorf("Expected no changes between identical builds, got %+v", changes)
+	}
+
+	changes := Diff(old, traceOf(t, "bbbb2222", "v1"), "build example.com/app")
+	want := []InputChange{
+		{Action: "build example.com/app", Kind: InputDependency, Key: "example.com/lib", Old: "aaaav1", New: "bbbbv1"},
+		{Action: "build example.com/lib", Kind: InputSource, Key: "foo.go", Old: "aaaa1111", New: "bbbb2222"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Expected the dependency traced to its source file, got %+v", changes)
+	}
+
+	changes = Diff(old, traceOf(t, "aaaa1111", "v3"), "build example.com/app")
+	var envChanges int
+	for _, change := range changes {
+		if change.Kind == InputEnv && change.Key == "GOAMD64" && change.Old == "v1" && change.New == "v3" {
+			envChanges++
+		}
+	}
+	if envChanges != 2 {
+		t.Errorf("Expected GOAMD64 to change in both packages, got %+v", changes)
+	}
+}
+
+func TestChangeKind_Toolchain(t *testing.T) {
+	old := HashInput{Kind: InputFlag, Key: "compile", Value: "compile version go1.24.1 [] []"}
+	flags := HashInput{Kind: InputFlag, Key: "compile", Value: "compile version go1.24.1 [] [-N]"}
+	upgrade := HashInput{Kind: InputFlag, Key: "compile", Value: "compile version go1.25.0 [] []"}
+	if kind := changeKind(old, flags); kind != InputFlag {
+		t.Errorf("Expected new flags to be a flag change, got %s", kind)
+	}
+	if kind := changeKind(old, upgrade); kind != InputToolchain {
+		t.Errorf("Expected a new compiler to be a toolchain change, got %s", kind)
+	}
+}
+
+func TestBuildManager_HasAction(t *testing.T) {
+	dir := t.TempDir()
+	action := strings.Repeat("ab", 32)
+	output := strings.Repeat("cd", 32)
+	for name, content := range map[string]string{
+		"ab/" + action + "-a": "v1 " + action + " " + output + " 10 1700000000\n",
+		"cd/" + output + "-d": "!<arch>\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+
+	if !mgr.HasAction(action) {
+		t.Error("Expected the action to be cached")
+	}
+	if mgr.HasAction(strings.Repeat("ef", 32)) {
+		t.Error("Expected an unknown action not to be cached")
+	}
+	if err := os.Remove(filepath.Join(dir, "cd", output+"-d")); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.HasAction(action) {
+		t.Error("Expected an action without its output not to be cached")
+	}
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GoplsManager manages the gopls file cache
+type GoplsManager struct {
+	cacheDir string
+	remover
+}
+
+var (
+	_ CacheManager  = (*GoplsManager)(nil)
+	_ RemoverSetter = (*GoplsManager)(nil)
+)
+
+// NewGoplsManager creates a new gopls cache manager
+func NewGoplsManager(cacheDir string) (*GoplsManager, error) {
+	if cacheDir == "" {
+		dir, err := GoplsCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheDir = dir
+	}
+
+	// Verify cache directory exists
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("gopls %w: %s", ErrCacheNotFound, cacheDir)
+	}
+
+	return &GoplsManager{
+		cacheDir: cacheDir,
+	}, nil
+}
+
+// GoplsCacheDir returns the gopls cache location: $GOPLSCACHE if set,
+// otherwise gopls under the user cache directory
+func GoplsCacheDir() (string, error) {
+	if dir := os.Getenv("GOPLSCACHE"); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate gopls cache: %w", err)
+	}
+	return filepath.Join(dir, "gopls"), nil
+}
+
+// GetStats retrieves gopls cache statistics
+func (m *GoplsManager) GetStats() (Stats, error) {
+	files, err := scanFiles(m.cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk gopls cache: %w", err)
+	}
+
+	return &GoplsCacheStats{
+		Location:    m.cacheDir,
+		Size:        files.size,
+		EntryCount:  files.count,
+		OldestEntry: files.oldest,
+		NewestEntry: files.newest,
+	}, nil
+}
+
+// Clear removes all gopls cache entries
+func (m *GoplsManager) Clear() (int, int64, error) {
+	deletedCount, freedSpace, err := m.clearFiles(m.cacheDir)
+	if err != nil {
+		return deletedCount, freedSpace, fmt.Errorf("failed to clear gopls cache: %w", err)
+	}
+
+	return deletedCount, freedSpace, nil
+}
+
+// GetLocation returns the cache directory path
+func (m *GoplsManager) GetLocation() string {
+	return m.cacheDir
+}
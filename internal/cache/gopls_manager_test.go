@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoplsManager_GetStatsAndClear(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gocachectl-gopls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	entryDir := filepath.Join(tmpDir, "v20", "ab")
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"ab01-analysis", "ab02-xrefs"} {
+		if err := os.WriteFile(filepath.Join(entryDir, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("GOPLSCACHE", tmpDir)
+	mgr, err := NewGoplsManager("")
+	if err != nil {
+		t.Fatalf("NewGoplsManager failed: %v", err)
+	}
+	if mgr.GetLocation() != tmpDir {
+		t.Errorf("Expected location %s from GOPLSCACHE, got %s", tmpDir, mgr.GetLocation())
+	}
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	goplsStats := stats.(*GoplsCacheStats)
+	if goplsStats.EntryCount != 2 || goplsStats.Size != 8 {
+		t.Errorf("Expected 2 entries of 8 bytes, got %d entries of %d bytes", goplsStats.EntryCount, goplsStats.Size)
+	}
+
+	deleted, freed, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if deleted != 2 || freed != 8 {
+		t.Errorf("Expected 2 deleted and 8 freed, got %d and %d", deleted, freed)
+	}
+}
+
+func TestGoplsManager_Missing(t *testing.T) {
+	_, err := NewGoplsManager(filepath.Join(os.TempDir(), "gocachectl-no-such-gopls"))
+	if !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("Expected ErrCacheNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultAgeBuckets are the upper bounds of the age buckets stats count
+// entries in unless configured otherwise
+var DefaultAgeBuckets = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// AgeHistogram counts entries by age, youngest bucket first. Entries older
+// than the last bound fall in a final, open-ended bucket.
+type AgeHistogram []AgeBucket
+
+// AgeBucket counts the entries last modified within an age range, split by
+// size to help choose a prune cutoff
+type AgeBucket struct {
+	Label  string           `json:"label"` // e.g. "<1d", "1d-7d" or ">30d"
+	Count  int              `json:"count"`
+	Size   int64            `json:"size"`
+	BySize SizeDistribution `json:"by_size"`
+
+	// maxAge is the bucket's upper bound; zero for the last bucket
+	maxAge time.Duration
+}
+
+// NewAgeHistogram returns an empty histogram with buckets up to each of
+// bounds, which must be ascending
+func NewAgeHistogram(bounds []time.Duration) AgeHistogram {
+	h := make(AgeHistogram, 0, len(bounds)+1)
+	var lower time.Duration
+	for _, bound := range bounds {
+		label := "<" + FormatAge(bound)
+		if lower > 0 {
+			label = FormatAge(lower) + "-" + FormatAge(bound)
+		}
+		h = append(h, AgeBucket{Label: label, maxAge: bound})
+		lower = bound
+	}
+	label := "all"
+	if lower > 0 {
+		label = ">" + FormatAge(lower)
+	}
+	return append(h, AgeBucket{Label: label})
+}
+
+// add counts an entry of the given age and size
+func (h AgeHistogram) add(age time.Duration, size int64) {
+	i := 0
+	for i < len(h)-1 && age >= h[i].maxAge {
+		i++
+	}
+	h[i].Count++
+	h[i].Size += size
+	h[i].BySize.add(size)
+}
+
+// add counts an entry of the given size
+func (d *SizeDistribution) add(size int64) {
+	const (
+		MB          = 1024 * 1024
+		smallLimit  = MB
+		mediumLimit = 10 * MB
+	)
+
+	switch {
+	case size < smallLimit:
+		d.Small++
+		d.SmallSize += size
+	case size < mediumLimit:
+		d.Medium++
+		d.MediumSize += size
+	default:
+		d.Large++
+		d.LargeSize += size
+	}
+}
+
+// AgeBucketsSetter is implemented by managers whose stats include an
+// AgeHistogram
+type AgeBucketsSetter interface {
+	// SetAgeBuckets sets the upper bounds of the age buckets, which must
+	// be ascending; nil restores DefaultAgeBuckets
+	SetAgeBuckets(bounds []time.Duration)
+}
+
+// ageBuckets holds the age buckets of a manager's stats. Managers whose
+// entries have ages embed it.
+type ageBuckets struct {
+	bounds []time.Duration
+}
+
+// SetAgeBuckets sets the upper bounds of the age buckets in stats
+func (a *ageBuckets) SetAgeBuckets(bounds []time.Duration) {
+	a.bounds = bounds
+}
+
+// ageHistogram returns an empty histogram with the configured buckets
+func (a *ageBuckets) ageHistogram() AgeHistogram {
+	if a.bounds == nil {
+		return NewAgeHistogram(DefaultAgeBuckets)
+	}
+	return NewAgeHistogram(a.bounds)
+}
+
+// ValidateAgeBuckets checks that bounds are positive and ascending
+func ValidateAgeBuckets(bounds []time.Duration) error {
+	for i, bound := range bounds {
+		if bound <= 0 {
+			return fmt.Errorf("age bucket %s is not positive", FormatAge(bound))
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return fmt.Errorf("age buckets must be ascending: %s follows %s", FormatAge(bound), FormatAge(bounds[i-1]))
+		}
+	}
+	return nil
+}
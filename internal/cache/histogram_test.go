@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAgeHistogram(t *testing.T) {
+	const day = 24 * time.Hour
+	h := NewAgeHistogram([]time.Duration{day, 7 * day, 30 * day})
+
+	var labels []string
+	for _, bucket := range h {
+		labels = append(labels, bucket.Label)
+	}
+	want := []string{"<1d", "1d-7d", "7d-30d", ">30d"}
+	if len(labels) != len(want) {
+		t.Fatalf("Expected buckets %v, got %v", want, labels)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("Expected bucket %d labelled %q, got %q", i, want[i], labels[i])
+		}
+	}
+
+	h.add(time.Hour, 100)
+	h.add(day, 2*1024*1024) // bounds are exclusive
+	h.add(10*day, 20*1024*1024)
+	h.add(365*day, 1)
+
+	counts := []int{1, 1, 1, 1}
+	for i, bucket := range h {
+		if bucket.Count != counts[i] {
+			t.Errorf("Expected %d entries in %s, got %d", counts[i], bucket.Label, bucket.Count)
+		}
+	}
+	if h[1].BySize.Medium != 1 || h[1].Size != 2*1024*1024 {
+		t.Errorf("Expected a medium entry in 1d-7d, got %+v", h[1])
+	}
+	if h[2].BySize.Large != 1 {
+		t.Errorf("Expected a large entry in 7d-30d, got %+v", h[2])
+	}
+
+	if only := NewAgeHistogram(nil); len(only) != 1 || only[0].Label != "all" {
+		t.Errorf("Expected a single bucket without bounds, got %+v", only)
+	}
+}
+
+func TestValidateAgeBuckets(t *testing.T) {
+	if err := ValidateAgeBuckets([]time.Duration{time.Hour, 24 * time.Hour}); err != nil {
+		t.Errorf("Expected ascending buckets to be valid, got %v", err)
+	}
+	if err := ValidateAgeBuckets([]time.Duration{24 * time.Hour, time.Hour}); err == nil {
+		t.Error("Expected descending buckets to be rejected")
+	}
+	if err := ValidateAgeBuckets([]time.Duration{0}); err == nil {
+		t.Error("Expected a zero bucket to be rejected")
+	}
+}
+
+func TestBuildManager_GetStatsAges(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"b1-a": time.Minute, "b2-a": 3 * time.Hour, "b3-a": 48 * time.Hour} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte("archive"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr, err := NewBuildManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	mgr.SetAgeBuckets([]time.Duration{time.Hour, 24 * time.Hour})
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	ages := stats.(*BuildCacheStats).Ages
+	if len(ages) != 3 {
+		t.Fatalf("Expected 3 age buckets, got %+v", ages)
+	}
+	for i, bucket := range ages {
+		if bucket.Count != 1 || bucket.Size != 7 || bucket.BySize.Small != 1 {
+			t.Errorf("Expected one small entry in bucket %d, got %+v", i, bucket)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexLimit is how many builds an action index keeps; older ones are
+// dropped as new ones are appended
+const IndexLimit = 30
+
+// IndexRecord is one build in an action index: the action ID of every
+// package the build compiled or found compiled, and the inputs hashed into it
+type IndexRecord struct {
+	Recorded time.Time `json:"recorded"`
+	Command  []string  `json:"command,omitempty"`
+	Actions  HashTrace `json:"actions"`
+}
+
+// ReadIndex reads the action index at path, oldest build first. A missing
+// index holds no builds.
+func ReadIndex(path string) ([]IndexRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action index: %w", err)
+	}
+
+	var records []IndexRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record IndexRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse action index %s, line %d: %w", path, line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read action index: %w", err)
+	}
+	return records, nil
+}
+
+// AppendIndex adds the package builds of a trace to the action index at
+// path, keeping the last IndexLimit builds
+func AppendIndex(path string, command []string, trace HashTrace) error {
+	records, err := ReadIndex(path)
+	if err != nil {
+		return err
+	}
+	record := IndexRecord{Recorded: time.Now(), Command: command, Actions: make(HashTrace)}
+	for name, action := range trace {
+		if strings.HasPrefix(name, "build ") {
+			record.Actions[name] = action
+		}
+	}
+	records = append(records, record)
+	records = records[max(len(records)-IndexLimit, 0):]
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode action index: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create action index directory: %w", err)
+	}
+	// Written aside and renamed, so that an interrupted write leaves the
+	// previous index intact
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write action index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write action index: %w", err)
+	}
+	return nil
+}
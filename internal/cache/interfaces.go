@@ -1,5 +1,16 @@
 package cache
 
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// ErrCacheNotFound is returned by managers whose cache directory does not
+// exist. Optional caches such as gopls are skipped when it is returned.
+var ErrCacheNotFound = errors.New("cache directory does not exist")
+
 type Stats interface {
 	Type() string
 }
@@ -9,3 +20,76 @@ type CacheManager interface {
 	Clear() (int, int64, error)
 	GetLocation() string
 }
+
+// EntryLister is implemented by managers whose cache can be browsed and
+// removed entry by entry
+type EntryLister interface {
+	// ListEntries returns every entry of the cache
+	ListEntries() ([]Entry, error)
+	// RemoveEntries removes entries returned by ListEntries and returns the
+	// number removed and the bytes freed. Paths it could not remove are
+	// reported as a *ClearError.
+	RemoveEntries(entries []Entry) (int, int64, error)
+}
+
+// Trimmer is implemented by managers of caches the go command trims
+// itself, which can apply the same trim on demand
+type Trimmer interface {
+	// TrimEntries returns the entries a trim at now would remove
+	TrimEntries(now time.Time) ([]Entry, error)
+	// Trim removes them and records the trim, so that the go command does
+	// not trim again before its usual interval
+	Trim(now time.Time) (int, int64, error)
+}
+
+// Remover deletes cache paths for a manager. Managers implementing
+// RemoverSetter remove through it, so that a clear can quarantine entries
+// instead of deleting them.
+type Remover interface {
+	Remove(path string) error
+	// RemoveAll removes a directory tree; a missing path is not an error
+	RemoveAll(path string) error
+}
+
+// Replacer is implemented by Removers that keep a copy of a file a manager
+// is about to rewrite in place, so that the rewrite is undone with the
+// removals.
+type Replacer interface {
+	Replace(path string) error
+}
+
+// RemoverSetter is implemented by managers that remove through a Remover.
+// A nil Remover restores deleting.
+type RemoverSetter interface {
+	SetRemover(r Remover)
+}
+
+// ClearError lists the paths a Clear could not remove. Managers return it,
+// possibly wrapped, together with the counts of what they did remove.
+type ClearError struct {
+	Failures []*fs.PathError
+}
+
+func (e *ClearError) Error() string {
+	if len(e.Failures) == 1 {
+		return e.Failures[0].Error()
+	}
+	return fmt.Sprintf("%d paths could not be removed, first: %v", len(e.Failures), e.Failures[0])
+}
+
+// add records that path could not be removed
+func (e *ClearError) add(path string, err error) {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		pathErr = &fs.PathError{Op: "remove", Path: path, Err: err}
+	}
+	e.Failures = append(e.Failures, pathErr)
+}
+
+// err returns e if any path failed, and nil otherwise
+func (e *ClearError) err() error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	return e
+}
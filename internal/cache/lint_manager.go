@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LintManager manages the golangci-lint analysis cache
+type LintManager struct {
+	cacheDir string
+	remover
+}
+
+var (
+	_ CacheManager  = (*LintManager)(nil)
+	_ RemoverSetter = (*LintManager)(nil)
+)
+
+// NewLintManager creates a new golangci-lint cache manager
+func NewLintManager(cacheDir string) (*LintManager, error) {
+	if cacheDir == "" {
+		dir, err := LintCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheDir = dir
+	}
+
+	// Verify cache directory exists
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("golangci-lint %w: %s", ErrCacheNotFound, cacheDir)
+	}
+
+	return &LintManager{
+		cacheDir: cacheDir,
+	}, nil
+}
+
+// LintCacheDir returns the golangci-lint cache location:
+// $GOLANGCI_LINT_CACHE if set, otherwise golangci-lint under the user cache
+// directory
+func LintCacheDir() (string, error) {
+	if dir := os.Getenv("GOLANGCI_LINT_CACHE"); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate golangci-lint cache: %w", err)
+	}
+	return filepath.Join(dir, "golangci-lint"), nil
+}
+
+// GetStats retrieves golangci-lint cache statistics
+func (m *LintManager) GetStats() (Stats, error) {
+	files, err := scanFiles(m.cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk golangci-lint cache: %w", err)
+	}
+
+	return &LintCacheStats{
+		Location:    m.cacheDir,
+		Size:        files.size,
+		EntryCount:  files.count,
+		OldestEntry: files.oldest,
+		NewestEntry: files.newest,
+	}, nil
+}
+
+// Clear removes all golangci-lint cache entries
+func (m *LintManager) Clear() (int, int64, error) {
+	deletedCount, freedSpace, err := m.clearFiles(m.cacheDir)
+	if err != nil {
+		return deletedCount, freedSpace, fmt.Errorf("failed to clear golangci-lint cache: %w", err)
+	}
+
+	return deletedCount, freedSpace, nil
+}
+
+// GetLocation returns the cache directory path
+func (m *LintManager) GetLocation() string {
+	return m.cacheDir
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintManager_GetStatsAndClear(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gocachectl-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	entryDir := filepath.Join(tmpDir, "0f")
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(entryDir, "0f1e-a"), []byte("lint results"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOLANGCI_LINT_CACHE", tmpDir)
+	mgr, err := NewLintManager("")
+	if err != nil {
+		t.Fatalf("NewLintManager failed: %v", err)
+	}
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if lintStats := stats.(*LintCacheStats); lintStats.EntryCount != 1 {
+		t.Errorf("Expected 1 entry, got %d", lintStats.EntryCount)
+	}
+
+	deleted, _, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted file, got %d", deleted)
+	}
+	if _, err := os.Stat(filepath.Join(entryDir, "0f1e-a")); !os.IsNotExist(err) {
+		t.Error("0f1e-a should have been deleted")
+	}
+}
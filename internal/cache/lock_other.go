@@ -0,0 +1,14 @@
+//go:build !unix
+
+package cache
+
+import "os"
+
+// tryLock always succeeds: advisory locks are only taken on Unix
+func tryLock(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
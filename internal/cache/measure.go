@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// testResultLine matches the line go test prints for each tested package,
+// e.g. "ok  \texample.com/foo\t(cached)" or "FAIL\texample.com/bar\t0.02s"
+var testResultLine = regexp.MustCompile(`^(ok|FAIL)\s+\S+\s+(\(cached\)|[0-9.]+s)`)
+
+// Snapshot holds the size of every file in a build cache at one time, by
+// name below the cache
+type Snapshot map[string]int64
+
+// CacheEffect is what one go command did with the build and test cache
+type CacheEffect struct {
+	// Actions counts the packages the command compiled or found compiled
+	// in the cache; a hit is an action whose ID the cache already held
+	Actions int     `json:"actions"`
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // hits per action, 0 without actions
+	// TestsRun and TestsCached count the tested packages whose results
+	// were computed and replayed as "(cached)"
+	TestsRun    int `json:"tests_run"`
+	TestsCached int `json:"tests_cached"`
+	// New entries and bytes written to the cache, by build and test entries
+	NewBuildEntries int   `json:"new_build_entries"`
+	BuildBytesAdded int64 `json:"build_bytes_added"`
+	NewTestEntries  int   `json:"new_test_entries"`
+	TestBytesAdded  int64 `json:"test_bytes_added"`
+}
+
+// Snapshot lists every file of the cache, build and test entries alike, in
+// a single walk. Telling them apart means reading each file, so it is left
+// to CountEffect, for the new entries only.
+func (m *BuildManager) Snapshot() (Snapshot, error) {
+	entries, err := listFiles(m.cacheDir, func(path string) bool { return !isCacheMetadata(m.cacheDir, path) })
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(Snapshot, len(entries))
+	for _, entry := range entries {
+		snapshot[entry.Name] = entry.Size
+	}
+	return snapshot, nil
+}
+
+// growth counts the files in after that were not in before, and their size
+func growth(before, after Snapshot) (entries int, size int64) {
+	for name, n := range after {
+		if _, ok := before[name]; !ok {
+			entries++
+			size += n
+		}
+	}
+	return entries, size
+}
+
+// CountEffect counts into effect the cache hits and new entries of a go
+// command, from snapshots of the cache taken before and after it and the
+// hash trace telling which actions it looked up
+func (m *BuildManager) CountEffect(effect *CacheEffect, before, after Snapshot, trace HashTrace) {
+	for name, action := range trace {
+		if !strings.HasPrefix(name, "build ") || len(action.ID) < 2 {
+			continue
+		}
+		effect.Actions++
+		if _, ok := before[action.ID[:2]+"/"+action.ID+"-a"]; ok {
+			effect.Hits++
+		} else {
+			effect.Misses++
+		}
+	}
+	if effect.Actions > 0 {
+		effect.HitRate = float64(effect.Hits) / float64(effect.Actions)
+	}
+
+	for name, size := range after {
+		if _, ok := before[name]; ok {
+			continue
+		}
+		if isTestEntry(filepath.Join(m.cacheDir, filepath.FromSlash(name))) {
+			effect.NewTestEntries++
+			effect.TestBytesAdded += size
+		} else {
+			effect.NewBuildEntries++
+			effect.BuildBytesAdded += size
+		}
+	}
+}
+
+// CountTestResult counts a line of go test output, plain or -json, if it
+// reports a tested package
+func (e *CacheEffect) CountTestResult(line string) {
+	if strings.HasPrefix(line, "{") {
+		var event struct{ Output string }
+		if json.Unmarshal([]byte(line), &event) != nil {
+			return
+		}
+		line = event.Output
+	}
+	m := testResultLine.FindStringSubmatch(line)
+	switch {
+	case m == nil:
+	case m[2] == "(cached)":
+		e.TestsCached++
+	default:
+		e.TestsRun++
+	}
+}
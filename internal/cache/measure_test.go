@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountEffect(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hit := strings.Repeat("ab", 32)
+	miss := strings.Repeat("cd", 32)
+	write("ab/"+hit+"-a", "v1 "+hit+" 0a01 8 1700000000\n")
+	write("0a/0a01-d", "!<arch>\n")
+
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	before, err := mgr.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	// The command compiles one package and runs its tests
+	write("cd/"+miss+"-a", "v1 "+miss+" 0b01 16 1700000000\n")
+	write("0b/0b01-d", "!<arch>\ngo object\n")
+	write("0c/0c01-d", "ok  \texample.com/lib\t0.01s\n")
+	after, err := mgr.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	trace := HashTrace{
+		"build example.com/app": {Action: "build example.com/app", ID: hit},
+		"build example.com/lib": {Action: "build example.com/lib", ID: miss},
+		"link example.com/app":  {Action: "link example.com/app", ID: strings.Repeat("ef", 32)},
+	}
+	var effect CacheEffect
+	mgr.CountEffect(&effect, before, after, trace)
+
+	if effect.Actions != 2 || effect.Hits != 1 || effect.Misses != 1 || effect.HitRate != 0.5 {
+		t.Errorf("Expected 1 hit and 1 miss of 2 compile actions, got %+v", effect)
+	}
+	if effect.NewBuildEntries != 2 || effect.NewTestEntries != 1 {
+		t.Errorf("Expected 2 new build entries and 1 new test entry, got %+v", effect)
+	}
+	if effect.TestBytesAdded != int64(len("ok  \texample.com/lib\t0.01s\n")) {
+		t.Errorf("Expected the test entry's size, got %d", effect.TestBytesAdded)
+	}
+}
+
+func TestCountTestResult(t *testing.T) {
+	var effect CacheEffect
+	for _, line := range []string{
+		"ok  \texample.com/a\t(cached)",
+		"ok  \texample.com/b\t0.013s",
+		"FAIL\texample.com/c\t0.200s",
+		"?   \texample.com/d\t[no test files]",
+		"--- FAIL: TestX (0.00s)",
+		`{"Action":"output","Package":"example.com/e","Output":"ok  \texample.com/e\t(cached)\n"}`,
+		`{"Action":"pass","Package":"example.com/e"}`,
+	} {
+		effect.CountTestResult(line)
+	}
+	if effect.TestsRun != 2 || effect.TestsCached != 2 {
+		t.Errorf("Expected 2 tests run and 2 cached, got %+v", effect)
+	}
+}
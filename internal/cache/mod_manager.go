@@ -6,14 +6,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Manager manages the Go module cache
 type ModManager struct {
 	cacheDir string
+	remover
+	ageBuckets
 }
 
-var _ CacheManager = (*ModManager)(nil)
+var (
+	_ CacheManager     = (*ModManager)(nil)
+	_ EntryLister      = (*ModManager)(nil)
+	_ RemoverSetter    = (*ModManager)(nil)
+	_ AgeBucketsSetter = (*ModManager)(nil)
+)
 
 // NewManager creates a new module cache manager
 func NewModManager(cacheDir string) (*ModManager, error) {
@@ -39,6 +47,7 @@ func NewModManager(cacheDir string) (*ModManager, error) {
 func (m *ModManager) GetStats() (Stats, error) {
 	stats := &ModCacheStats{
 		Location: m.cacheDir,
+		Ages:     m.ageHistogram(),
 	}
 
 	moduleMap := make(map[string]*ModuleInfo)
@@ -49,8 +58,12 @@ func (m *ModManager) GetStats() (Stats, error) {
 			return nil // Skip errors
 		}
 
-		// Skip directories
+		// Skip directories, leaving toolchains to ToolchainManager and
+		// quarantined entries alone
 		if d.IsDir() {
+			if rel, err := filepath.Rel(m.cacheDir, path); err == nil && (isToolchainPath(rel) || isTrashDir(d)) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -101,8 +114,15 @@ func (m *ModManager) GetStats() (Stats, error) {
 		return nil, fmt.Errorf("failed to walk module cache: %w", err)
 	}
 
-	// Count modules
+	// Count modules, aged by their directory's modification time, which is
+	// about when they were downloaded
 	stats.ModuleCount = len(moduleMap)
+	now := time.Now()
+	for modulePath, mod := range moduleMap {
+		if info, err := os.Stat(filepath.Join(m.cacheDir, modulePath)); err == nil {
+			stats.Ages.add(now.Sub(info.ModTime()), mod.Size)
+		}
+	}
 
 	// Get top modules by size
 	stats.TopModules = getTopModules(moduleMap, 10)
@@ -114,6 +134,7 @@ func (m *ModManager) GetStats() (Stats, error) {
 func (m *ModManager) Clear() (int, int64, error) {
 	var deletedCount int
 	var freedSpace int64
+	failures := &ClearError{}
 
 	err := filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -125,25 +146,52 @@ func (m *ModManager) Clear() (int, int64, error) {
 			return nil
 		}
 
-		// Skip directories
+		// Skip directories, leaving toolchains to ToolchainManager and
+		// quarantined entries alone
 		if d.IsDir() {
+			rel, err := filepath.Rel(m.cacheDir, path)
+			if err != nil {
+				return nil
+			}
+			if isToolchainPath(rel) || isTrashDir(d) {
+				return filepath.SkipDir
+			}
+
+			// Extracted module versions are read-only, so their files cannot
+			// be removed one by one: each tree is made writable and removed,
+			// or quarantined, as a whole
+			if strings.Contains(d.Name(), "@") && !strings.HasPrefix(filepath.ToSlash(rel), "cache/") {
+				count, size, err := m.removeTree(path)
+				if err != nil {
+					failures.add(path, err)
+					return filepath.SkipDir
+				}
+				deletedCount += count
+				freedSpace += size
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Get file size before deletion
 		info, err := d.Info()
-		if err == nil {
-			freedSpace += info.Size()
+		if err != nil {
+			return nil
 		}
 
-		// Delete file
-		if err := os.Remove(path); err == nil {
-			deletedCount++
+		// Delete file, counting it only once it is gone
+		if err := m.remove(path); err != nil {
+			failures.add(path, err)
+			return nil
 		}
+		deletedCount++
+		freedSpace += info.Size()
 
 		return nil
 	})
 
+	if err == nil {
+		err = failures.err()
+	}
 	if err != nil {
 		return deletedCount, freedSpace, fmt.Errorf("failed to clear module cache: %w", err)
 	}
@@ -151,6 +199,225 @@ func (m *ModManager) Clear() (int, int64, error) {
 	return deletedCount, freedSpace, nil
 }
 
+// ListEntries returns every extracted module version. Names keep the module
+// cache's escaping of upper-case letters, e.g. github.com/!burnt!sushi@v1.4.0,
+// while modules and versions are unescaped.
+func (m *ModManager) ListEntries() ([]Entry, error) {
+	var entries []Entry
+	err := m.walkExtracted(func(rel, path string, info fs.FileInfo) {
+		module, version, _ := strings.Cut(rel, "@")
+		size := dirSize(path)
+		for _, download := range m.downloads(module, version) {
+			if info, err := os.Stat(download); err == nil {
+				size += info.Size()
+			}
+		}
+		entries = append(entries, Entry{
+			Name:    rel,
+			Module:  UnescapeModulePath(module),
+			Version: UnescapeModulePath(version),
+			Path:    path,
+			Size:    size,
+			ModTime: info.ModTime(),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk module cache: %w", err)
+	}
+	return entries, nil
+}
+
+// walkExtracted calls fn for the directory of every extracted module
+// version, with its path below the cache as module@version
+func (m *ModManager) walkExtracted(fn func(rel, path string, info fs.FileInfo)) error {
+	return filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == m.cacheDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.cacheDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Downloads are removed together with their module version
+		if rel == "cache" || isToolchainPath(rel) || isTrashDir(d) {
+			return filepath.SkipDir
+		}
+		if !strings.Contains(rel, "@") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return filepath.SkipDir
+		}
+		fn(rel, path, info)
+		return filepath.SkipDir
+	})
+}
+
+// RemoveEntries removes module versions: their extracted tree and their
+// downloaded zip, go.mod and metadata files
+func (m *ModManager) RemoveEntries(entries []Entry) (int, int64, error) {
+	var deletedCount int
+	var freedSpace int64
+	failures := &ClearError{}
+
+	for _, entry := range entries {
+		if entry.Module == "" || !withinDir(m.cacheDir, entry.Path) {
+			failures.add(entry.Path, fmt.Errorf("not a module version in %s", m.cacheDir))
+			continue
+		}
+
+		freed, ok := m.removeVersion(EscapeModulePath(entry.Module), EscapeModulePath(entry.Version), failures)
+		freedSpace += freed
+		if ok {
+			deletedCount++
+		}
+	}
+
+	if err := failures.err(); err != nil {
+		return deletedCount, freedSpace, fmt.Errorf("failed to remove module versions: %w", err)
+	}
+	return deletedCount, freedSpace, nil
+}
+
+// removeVersion removes the extracted tree and downloaded files of a module
+// version, given escaped as on disk, and drops it from the version list of
+// its module. It reports the bytes freed and whether everything was
+// removed, recording what was not in failures.
+func (m *ModManager) removeVersion(module, version string, failures *ClearError) (int64, bool) {
+	var freed int64
+	tree := filepath.Join(m.cacheDir, filepath.FromSlash(module)+"@"+version)
+	if _, err := os.Lstat(tree); err == nil {
+		_, size, err := m.removeTree(tree)
+		freed += size
+		if err != nil {
+			failures.add(tree, err)
+			return freed, false
+		}
+	}
+
+	// Downloads are not extracted read-only, but their directory may have
+	// been made read-only since
+	dir := m.downloadDir(module)
+	if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0o200 == 0 {
+		_ = os.Chmod(dir, info.Mode().Perm()|0o700)
+	}
+	ok := true
+	for _, path := range m.downloads(module, version) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := m.remove(path); err != nil {
+			failures.add(path, err)
+			ok = false
+			continue
+		}
+		freed += info.Size()
+	}
+	if err := m.dropListedVersion(filepath.Join(dir, "list"), version); err != nil {
+		failures.add(filepath.Join(dir, "list"), err)
+		ok = false
+	}
+	return freed, ok
+}
+
+// dropListedVersion removes a version, escaped or not, from the version
+// list of a module's downloads, which the go command serves when the
+// module cache is used as a proxy. An emptied list is removed. A
+// quarantining Remover keeps a copy of the list from before.
+func (m *ModManager) dropListedVersion(list, version string) error {
+	data, err := os.ReadFile(list)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line != "" && line != version && line != UnescapeModulePath(version) {
+			kept = append(kept, line)
+		}
+	}
+	updated := strings.Join(kept, "\n") + "\n"
+	if len(kept) > 0 && updated == string(data) {
+		return nil
+	}
+	if err := m.replace(list); err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		return os.Remove(list)
+	}
+
+	// Written aside and renamed, so that go commands never read half a list
+	tmp := list + ".tmp"
+	if err := os.WriteFile(tmp, []byte(updated), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, list); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// downloads returns the downloaded files of a module version: its zip,
+// go.mod, .info and hash files
+func (m *ModManager) downloads(module, version string) []string {
+	matches, _ := filepath.Glob(filepath.Join(m.downloadDir(module), version+".*"))
+	return matches
+}
+
+// downloadDir returns the directory of the downloads of a module, given
+// escaped as on disk
+func (m *ModManager) downloadDir(module string) string {
+	return filepath.Join(m.cacheDir, "cache", "download", filepath.FromSlash(module), "@v")
+}
+
+// EscapeModulePath escapes upper-case letters as the module cache does,
+// turning github.com/BurntSushi into github.com/!burnt!sushi
+func EscapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// UnescapeModulePath undoes the module cache's escaping of upper-case
+// letters, turning github.com/!burnt!sushi into github.com/BurntSushi
+func UnescapeModulePath(path string) string {
+	if !strings.Contains(path, "!") {
+		return path
+	}
+
+	var b strings.Builder
+	bang := false
+	for _, r := range path {
+		switch {
+		case bang:
+			b.WriteString(strings.ToUpper(string(r)))
+			bang = false
+		case r == '!':
+			bang = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 // GetLocation returns the cache directory path
 func (m *ModManager) GetLocation() string {
 	return m.cacheDir
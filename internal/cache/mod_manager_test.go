@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -89,3 +91,82 @@ func TestModManager_Clear(t *testing.T) {
 		t.Error("File should have been deleted")
 	}
 }
+
+func TestModManager_Entries(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		dir := filepath.Join(tmpDir, "github.com", "!user", "repo@"+version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/User/repo"), 0444); err != nil {
+			t.Fatal(err)
+		}
+		// Extracted module trees are read-only
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+
+		download := filepath.Join(tmpDir, "cache", "download", "github.com", "!user", "repo", "@v")
+		if err := os.MkdirAll(download, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, ext := range []string{".info", ".mod", ".zip"} {
+			if err := os.WriteFile(filepath.Join(download, version+ext), []byte("data"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	mgr, err := NewModManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewModManager failed: %v", err)
+	}
+
+	entries, err := mgr.ListEntries()
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 module versions, got %+v", entries)
+	}
+	first := entries[0]
+	if first.Module != "github.com/User/repo" || first.Version != "v1.0.0" || first.Name != "github.com/!user/repo@v1.0.0" {
+		t.Errorf("Unexpected entry: %+v", first)
+	}
+
+	deleted, freed, err := mgr.RemoveEntries(entries[:1])
+	if err != nil {
+		t.Fatalf("RemoveEntries failed: %v", err)
+	}
+	if deleted != 1 || freed == 0 {
+		t.Errorf("Expected 1 version removed with space freed, got %d and %d", deleted, freed)
+	}
+
+	if _, err := os.Stat(first.Path); !os.IsNotExist(err) {
+		t.Error("Expected the extracted version to be removed")
+	}
+	download := filepath.Join(tmpDir, "cache", "download", "github.com", "!user", "repo", "@v")
+	if _, err := os.Stat(filepath.Join(download, "v1.0.0.zip")); !os.IsNotExist(err) {
+		t.Error("Expected the downloaded zip to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(download, "v1.1.0.zip")); err != nil {
+		t.Errorf("Expected the other version to be kept: %v", err)
+	}
+
+	// Entries outside the cache are refused
+	_, _, err = mgr.RemoveEntries([]Entry{{Module: "x", Version: "v1.0.0", Path: filepath.Dir(tmpDir)}})
+	var clearErr *ClearError
+	if !errors.As(err, &clearErr) || len(clearErr.Failures) != 1 {
+		t.Errorf("Expected a ClearError for a path outside the cache, got %v", err)
+	}
+
+	// Let t.TempDir clean up the remaining read-only tree
+	_ = filepath.WalkDir(tmpDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(path, 0755)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Extensions of the files the go command downloads for a module version
+const (
+	downloadInfo = ".info"
+	downloadMod  = ".mod"
+	downloadZip  = ".zip"
+)
+
+// ModuleVersion is a module version in the module cache: what was
+// downloaded for it, whether it was extracted, and the metadata the proxy
+// or VCS reported for it
+type ModuleVersion struct {
+	Path    string `json:"path"`    // module path, e.g. github.com/BurntSushi/toml
+	Version string `json:"version"` // e.g. v1.4.0
+	// Time is when the version was released, from its .info file
+	Time   time.Time     `json:"time,omitzero"`
+	Origin *ModuleOrigin `json:"origin,omitempty"`
+	Dir    string        `json:"dir,omitempty"` // extracted tree, when present
+	// Size is that of the extracted tree, ZipSize that of the downloaded zip
+	Size    int64 `json:"size"`
+	ZipSize int64 `json:"zip_size"`
+	HasZip  bool  `json:"has_zip"`
+	HasMod  bool  `json:"has_mod"`
+	// Extracted reports whether the zip was extracted into the cache
+	Extracted bool `json:"extracted"`
+	// Complete reports whether the zip, go.mod and extracted tree are all
+	// present; versions only resolved while selecting versions have a go.mod
+	// alone
+	Complete bool `json:"complete"`
+	// Versions counts the versions of the module path in the cache
+	Versions int `json:"versions"`
+}
+
+// ModuleOrigin is where a module version came from, as recorded in its
+// .info file by the go command or proxy
+type ModuleOrigin struct {
+	VCS    string `json:"vcs,omitempty"` // e.g. git
+	URL    string `json:"url,omitempty"`
+	Subdir string `json:"subdir,omitempty"`
+	Ref    string `json:"ref,omitempty"` // e.g. refs/tags/v1.4.0
+	Hash   string `json:"hash,omitempty"`
+}
+
+// VersionLister is implemented by managers of module caches, which can list
+// and remove module versions with their metadata
+type VersionLister interface {
+	ListVersions() ([]ModuleVersion, error)
+	// RemoveVersions removes versions returned by ListVersions and returns
+	// the number removed and the bytes freed, like EntryLister.RemoveEntries
+	RemoveVersions(versions []ModuleVersion) (int, int64, error)
+}
+
+var _ VersionLister = (*ModManager)(nil)
+
+// moduleInfo is the content of a .info file
+type moduleInfo struct {
+	Version string
+	Time    time.Time
+	Origin  *ModuleOrigin
+}
+
+// ListVersions returns every module version in the cache, downloaded or
+// extracted, ordered by module path and version. Paths and versions are
+// unescaped.
+func (m *ModManager) ListVersions() ([]ModuleVersion, error) {
+	// Keyed by escaped module path and version, as they appear on disk
+	versions := make(map[[2]string]*ModuleVersion)
+	version := func(module, v string) *ModuleVersion {
+		key := [2]string{module, v}
+		if versions[key] == nil {
+			versions[key] = &ModuleVersion{Path: UnescapeModulePath(module), Version: UnescapeModulePath(v)}
+		}
+		return versions[key]
+	}
+
+	downloads := filepath.Join(m.cacheDir, "cache", "download")
+	err := filepath.WalkDir(downloads, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == downloads && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(m.cacheDir, path)
+		if err != nil || isToolchainPath(rel) || isTrashDir(d) || path == filepath.Join(downloads, "sumdb") {
+			return filepath.SkipDir
+		}
+		if d.Name() != "@v" {
+			return nil
+		}
+
+		module, err := filepath.Rel(downloads, filepath.Dir(path))
+		if err != nil {
+			return filepath.SkipDir
+		}
+		module = filepath.ToSlash(module)
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return filepath.SkipDir
+		}
+		for _, file := range files {
+			ext := filepath.Ext(file.Name())
+			v := strings.TrimSuffix(file.Name(), ext)
+			switch ext {
+			case downloadMod:
+				version(module, v).HasMod = true
+			case downloadZip:
+				mv := version(module, v)
+				mv.HasZip = true
+				if info, err := file.Info(); err == nil {
+					mv.ZipSize = info.Size()
+				}
+			case downloadInfo:
+				readModuleInfo(filepath.Join(path, file.Name()), version(module, v))
+			}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk module downloads: %w", err)
+	}
+
+	err = m.walkExtracted(func(rel, path string, info fs.FileInfo) {
+		module, v, _ := strings.Cut(rel, "@")
+		mv := version(module, v)
+		mv.Extracted = true
+		mv.Dir = path
+		mv.Size = dirSize(path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk module cache: %w", err)
+	}
+
+	counts := make(map[string]int)
+	list := make([]ModuleVersion, 0, len(versions))
+	for _, mv := range versions {
+		mv.Complete = mv.HasZip && mv.HasMod && mv.Extracted
+		counts[mv.Path]++
+		list = append(list, *mv)
+	}
+	for i := range list {
+		list[i].Versions = counts[list[i].Path]
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// RemoveVersions removes module versions: their extracted tree, their
+// downloaded zip, go.mod and metadata files, and their line in the version
+// list of their module. Extracted trees are read-only and made writable
+// first.
+func (m *ModManager) RemoveVersions(versions []ModuleVersion) (int, int64, error) {
+	var deletedCount int
+	var freedSpace int64
+	failures := &ClearError{}
+
+	for _, v := range versions {
+		freed, ok := m.removeVersion(EscapeModulePath(v.Path), EscapeModulePath(v.Version), failures)
+		freedSpace += freed
+		if ok {
+			deletedCount++
+		}
+	}
+
+	if err := failures.err(); err != nil {
+		return deletedCount, freedSpace, fmt.Errorf("failed to remove module versions: %w", err)
+	}
+	return deletedCount, freedSpace, nil
+}
+
+// readModuleInfo fills the release time and origin of mv from the .info
+// file at path, leaving them unset if it cannot be read
+func readModuleInfo(path string, mv *ModuleVersion) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var info moduleInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return
+	}
+	mv.Time = info.Time
+	if info.Origin != nil && *info.Origin != (ModuleOrigin{}) {
+		mv.Origin = info.Origin
+	}
+}
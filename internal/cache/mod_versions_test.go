@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModManager_ListVersions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// Complete: downloaded, with metadata, and extracted
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.info": `{"Version":"v1.4.0","Time":"2024-06-08T08:13:36Z","Origin":{"VCS":"git","URL":"https://github.com/BurntSushi/toml","Ref":"refs/tags/v1.4.0","Hash":"abc123"}}`,
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.mod":  "module github.com/BurntSushi/toml\n",
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.zip":  "zipdata",
+		"cache/download/github.com/!burnt!sushi/toml/@v/list":        "v1.3.2\nv1.4.0\n",
+		"github.com/!burnt!sushi/toml@v1.4.0/go.mod":                 "module github.com/BurntSushi/toml\n",
+		"github.com/!burnt!sushi/toml@v1.4.0/decode.go":              "package toml\n",
+		// Only resolved while selecting versions
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.3.2.mod": "module github.com/BurntSushi/toml\n",
+		// Extracted but its downloads are gone
+		"example.com/lib@v0.1.0/lib.go": "package lib\n",
+		// Neither modules nor module versions
+		"cache/download/sumdb/sum.golang.org/lookup/example.com/lib@v0.1.0":      "",
+		"cache/download/golang.org/toolchain/@v/v0.0.1-go1.24.1.linux-amd64.mod": "",
+		"golang.org/toolchain@v0.0.1-go1.24.1.linux-amd64/bin/go":                "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr, err := NewModManager(dir)
+	if err != nil {
+		t.Fatalf("NewModManager failed: %v", err)
+	}
+	versions, err := mgr.ListVersions()
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 module versions, got %+v", versions)
+	}
+
+	lib, old, toml := versions[0], versions[1], versions[2]
+	if lib.Path != "example.com/lib" || !lib.Extracted || lib.HasZip || lib.HasMod || lib.Complete || lib.Size != 12 {
+		t.Errorf("Expected example.com/lib extracted without downloads, got %+v", lib)
+	}
+	if old.Path != "github.com/BurntSushi/toml" || old.Version != "v1.3.2" || !old.HasMod || old.Extracted || old.Complete {
+		t.Errorf("Expected toml v1.3.2 with a go.mod alone, got %+v", old)
+	}
+	if toml.Version != "v1.4.0" || !toml.Complete || toml.ZipSize != 7 || toml.Versions != 2 || old.Versions != 2 {
+		t.Errorf("Expected toml v1.4.0 complete, one of 2 versions, got %+v", toml)
+	}
+	if want := time.Date(2024, 6, 8, 8, 13, 36, 0, time.UTC); !toml.Time.Equal(want) {
+		t.Errorf("Expected the release time %v, got %v", want, toml.Time)
+	}
+	if toml.Origin == nil || toml.Origin.VCS != "git" || toml.Origin.URL != "https://github.com/BurntSushi/toml" || toml.Origin.Hash != "abc123" {
+		t.Errorf("Expected the git origin, got %+v", toml.Origin)
+	}
+}
+
+func TestModManager_RemoveVersions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cache/download/github.com/!acme/lib/@v/v1.4.0.info": `{"Version":"v1.4.0"}`,
+		"cache/download/github.com/!acme/lib/@v/v1.4.0.mod":  "module github.com/Acme/lib\n",
+		"cache/download/github.com/!acme/lib/@v/v1.4.0.zip":  "zipdata",
+		"cache/download/github.com/!acme/lib/@v/v1.5.0.mod":  "module github.com/Acme/lib\n",
+		"cache/download/github.com/!acme/lib/@v/list":        "v1.4.0\nv1.5.0\n",
+		"github.com/!acme/lib@v1.4.0/lib.go":                 "package lib\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Extracted trees are read-only, as the go command leaves them
+	tree := filepath.Join(dir, "github.com", "!acme", "lib@v1.4.0")
+	if err := os.Chmod(filepath.Join(tree, "lib.go"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(tree, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewModManager(dir)
+	if err != nil {
+		t.Fatalf("NewModManager failed: %v", err)
+	}
+	versions, err := mgr.ListVersions()
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %+v, %v", versions, err)
+	}
+
+	deleted, freed, err := mgr.RemoveVersions(versions[:1])
+	if err != nil {
+		t.Fatalf("RemoveVersions failed: %v", err)
+	}
+	if deleted != 1 || freed != int64(len("package lib\n")+len(`{"Version":"v1.4.0"}`)+len("module github.com/Acme/lib\n")+len("zipdata")) {
+		t.Errorf("Expected v1.4.0 removed with its downloads, got %d, %d bytes", deleted, freed)
+	}
+	downloads := filepath.Join(dir, "cache", "download", "github.com", "!acme", "lib", "@v")
+	for _, path := range []string{tree, filepath.Join(downloads, "v1.4.0.zip"), filepath.Join(downloads, "v1.4.0.mod")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+	if list, err := os.ReadFile(filepath.Join(downloads, "list")); err != nil || string(list) != "v1.5.0\n" {
+		t.Errorf("Expected the list to keep v1.5.0 only, got %q, %v", list, err)
+	}
+
+	// Removing the last version removes the list
+	if deleted, _, err := mgr.RemoveVersions(versions[1:]); err != nil || deleted != 1 {
+		t.Fatalf("Expected v1.5.0 removed, got %d, %v", deleted, err)
+	}
+	if _, err := os.Stat(filepath.Join(downloads, "list")); !os.IsNotExist(err) {
+		t.Errorf("Expected the emptied list to be removed, got %v", err)
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	for _, path := range []string{"github.com/BurntSushi/toml", "example.com/lib", "github.com/Azure/AZ"} {
+		escaped := EscapeModulePath(path)
+		if strings.ContainsAny(escaped, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") || UnescapeModulePath(escaped) != path {
+			t.Errorf("EscapeModulePath(%q) = %q does not round-trip", path, escaped)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"fmt"
+	"go/version"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// objectHeader starts the line naming the platform and toolchain of a
+// compiled Go object, found at the top of package archives
+const objectHeader = "go object "
+
+// otherGroup holds the entries of a breakdown with no known producer
+const otherGroup = "other"
+
+// ObjectInfo describes the toolchain and platform that produced a build
+// cache output
+type ObjectInfo struct {
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	GoVersion string `json:"go_version"` // e.g. go1.24.1, or "devel go1.25-abc123"
+}
+
+// ObjectSelector is implemented by managers of caches holding compiled Go
+// objects, which can select entries by who produced them
+type ObjectSelector interface {
+	EntryLister
+	// SelectObjects returns the outputs whose producer satisfies keep,
+	// together with the action files naming them
+	SelectObjects(keep func(ObjectInfo) bool) ([]Entry, error)
+}
+
+// Breakdown selects the optional groupings of build cache stats. Each reads
+// the header of every output, so they are off by default.
+type Breakdown struct {
+	ByToolchain bool
+	ByPlatform  bool
+}
+
+// BreakdownSetter is implemented by managers whose stats can be broken down
+type BreakdownSetter interface {
+	SetBreakdown(b Breakdown)
+}
+
+// EntryGroup is the part of a cache produced by one toolchain or for one
+// platform, action files included
+type EntryGroup struct {
+	Name    string `json:"name"` // e.g. go1.24.1 or linux/amd64, or "other" for entries of no known producer
+	Entries int    `json:"entries"`
+	Size    int64  `json:"size"`
+}
+
+// readObjectInfo returns who produced the output at path: the object header
+// of a package archive or object file, or the build info of a linked binary
+func readObjectInfo(path string) (ObjectInfo, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ObjectInfo{}, false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ObjectInfo{}, false
+	}
+	if info, ok := parseObjectHeader(buf[:n]); ok {
+		return info, true
+	}
+
+	// A linked binary: its headers name the platform, its build info the
+	// toolchain
+	var info ObjectInfo
+	goos, goarch, isExe := executablePlatform(f)
+	bi, err := buildinfo.Read(f)
+	if err == nil {
+		info.GoVersion = bi.GoVersion
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "GOOS":
+				info.GOOS = setting.Value
+			case "GOARCH":
+				info.GOARCH = setting.Value
+			}
+		}
+	}
+	if isExe {
+		info.GOOS, info.GOARCH = goos, goarch
+	}
+	return info, isExe || err == nil
+}
+
+// parseObjectHeader parses the first object header line in data, e.g.
+// "go object linux amd64 go1.24.1 X:regabiwrappers"
+func parseObjectHeader(data []byte) (ObjectInfo, bool) {
+	i := bytes.Index(data, []byte(objectHeader))
+	if i < 0 {
+		return ObjectInfo{}, false
+	}
+	line, _, _ := bytes.Cut(data[i+len(objectHeader):], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) < 3 {
+		return ObjectInfo{}, false
+	}
+
+	info := ObjectInfo{GOOS: fields[0], GOARCH: fields[1], GoVersion: fields[2]}
+	if info.GoVersion == "devel" && len(fields) > 3 {
+		info.GoVersion += " " + fields[3]
+	}
+	return info, true
+}
+
+// objectIndex links the outputs of a build cache to their producers and to
+// the action files naming them
+type objectIndex struct {
+	outputs map[string]Entry      // every output, by output ID
+	objects map[string]ObjectInfo // outputs of a known producer, by output ID
+	actions map[string][]Entry    // action files, by the output ID they name
+}
+
+// indexObjects reads the header of every output and every action file of
+// the cache in dir
+func indexObjects(dir string) (*objectIndex, error) {
+	entries, err := listFiles(dir, func(path string) bool { return isTrimmable(dir, path) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk build cache: %w", err)
+	}
+
+	index := &objectIndex{
+		outputs: make(map[string]Entry),
+		objects: make(map[string]ObjectInfo),
+		actions: make(map[string][]Entry),
+	}
+	for _, entry := range entries {
+		name := filepath.Base(entry.Path)
+		if id, ok := strings.CutSuffix(name, "-d"); ok {
+			index.outputs[id] = entry
+			if info, ok := readObjectInfo(entry.Path); ok {
+				index.objects[id] = info
+			}
+			continue
+		}
+		if id, ok := actionOutput(entry.Path); ok {
+			index.actions[id] = append(index.actions[id], entry)
+		}
+	}
+	return index, nil
+}
+
+// actionOutput returns the output ID named by the action file at path
+func actionOutput(path string) (string, bool) {
+	output, _, ok := actionWritten(path)
+	return output, ok
+}
+
+// actionWritten returns the output ID named by the action file at path,
+// which reads "v1 <action ID> <output ID> <size> <time>", and the time it
+// was written
+func actionWritten(path string) (string, time.Time, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 5 || fields[0] != "v1" || len(fields[2]) < 2 {
+		return "", time.Time{}, false
+	}
+	ns, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return fields[2], time.Unix(0, ns), true
+}
+
+// selectObjects returns the outputs whose producer satisfies keep, with
+// their action files
+func (x *objectIndex) selectObjects(keep func(ObjectInfo) bool) []Entry {
+	var selected []Entry
+	for id, info := range x.objects {
+		if keep(info) {
+			selected = append(selected, x.outputs[id])
+			selected = append(selected, x.actions[id]...)
+		}
+	}
+	return selected
+}
+
+// groups totals the outputs of a known producer and their action files by
+// key. The rest of total, which counts the entries the stats do, is
+// grouped as "other".
+func (x *objectIndex) groups(key func(ObjectInfo) string, totalEntries int, totalSize int64) []EntryGroup {
+	byName := make(map[string]*EntryGroup)
+	for id, info := range x.objects {
+		name := key(info)
+		if name == "" {
+			continue
+		}
+		group, ok := byName[name]
+		if !ok {
+			group = &EntryGroup{Name: name}
+			byName[name] = group
+		}
+		for _, entry := range append([]Entry{x.outputs[id]}, x.actions[id]...) {
+			group.Entries++
+			group.Size += entry.Size
+			totalEntries--
+			totalSize -= entry.Size
+		}
+	}
+
+	groups := make([]EntryGroup, 0, len(byName)+1)
+	for _, group := range byName {
+		groups = append(groups, *group)
+	}
+	if totalEntries > 0 {
+		groups = append(groups, EntryGroup{Name: otherGroup, Entries: totalEntries, Size: max(totalSize, 0)})
+	}
+	return groups
+}
+
+// sortByVersion orders toolchain groups newest first, leaving versions
+// that cannot be compared, like devel builds, and "other" last
+func sortByVersion(groups []EntryGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i].Name, groups[j].Name
+		switch {
+		case version.IsValid(a) && version.IsValid(b):
+			return version.Compare(a, b) > 0
+		case version.IsValid(a) != version.IsValid(b):
+			return version.IsValid(a)
+		case (a == otherGroup) != (b == otherGroup):
+			return b == otherGroup
+		}
+		return a < b
+	})
+}
+
+// sortBySize orders groups largest first, leaving "other" last
+func sortBySize(groups []EntryGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Name == otherGroup) != (b.Name == otherGroup) {
+			return b.Name == otherGroup
+		}
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Name < b.Name
+	})
+}
+
+// OlderToolchain reports whether the object was produced by a release of Go
+// older than v, e.g. "go1.24". Development builds are never older.
+func (o ObjectInfo) OlderToolchain(v string) bool {
+	return version.IsValid(o.GoVersion) && version.Compare(o.GoVersion, v) < 0
+}
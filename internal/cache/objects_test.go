@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestParseObjectHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   ObjectInfo
+		ok     bool
+	}{
+		{"!<arch>\n__.PKGDEF       0           0     0     644     17946     `\ngo object linux amd64 go1.24.1 GOAMD64=v1 X:regabiwrappers\n",
+			ObjectInfo{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.1"}, true},
+		{"go object darwin arm64 devel go1.25-8d8ba8d Fri Mar 7 X:none\n",
+			ObjectInfo{GOOS: "darwin", GOARCH: "arm64", GoVersion: "devel go1.25-8d8ba8d"}, true},
+		{"ok  \texample.com/pkg\t0.01s\n", ObjectInfo{}, false},
+		{"go object linux\n", ObjectInfo{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseObjectHeader([]byte(tt.header))
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseObjectHeader(%q) = %+v, %v, want %+v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// writeObjectCache creates a build cache with the archives of two
+// toolchains and three platforms, an action file naming each, and a test
+// output
+func writeObjectCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"0a/0a01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object linux amd64 go1.23.4 X:none\n",
+		"0b/0b01-a": "v1 0b01 0a01 60 1700000000\n",
+		"0c/0c01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object linux arm64 go1.24.1 X:none\n",
+		"0d/0d01-a": "v1 0d01 0c01 60 1700000000\n",
+		"1a/1a01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object windows amd64 go1.24.1 X:none\n",
+		"1b/1b01-a": "v1 1b01 1a01 60 1700000000\n",
+		"0e/0e01-d": "ok  \texample.com/pkg\t0.01s\n",
+		"0f/0f01-a": "v1 0f01 0e01 24 1700000000\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestBuildManager_ByToolchain(t *testing.T) {
+	dir := writeObjectCache(t)
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	mgr.SetBreakdown(Breakdown{ByToolchain: true})
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	var names []string
+	for _, group := range stats.(*BuildCacheStats).Toolchains {
+		names = append(names, group.Name)
+		if group.Name == "go1.23.4" && group.Entries != 2 {
+			t.Errorf("Expected the archive and action file of %s, got %+v", group.Name, group)
+		}
+	}
+	// The test output is not a build entry; its action file is
+	if want := []string{"go1.24.1", "go1.23.4", otherGroup}; !slices.Equal(names, want) || stats.(*BuildCacheStats).Toolchains[0].Entries != 4 {
+		t.Errorf("Expected toolchains %v, got %v", want, names)
+	}
+}
+
+func TestBuildManager_SelectObjects(t *testing.T) {
+	dir := writeObjectCache(t)
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+
+	opts := PruneOptions{ToolchainOlderThan: "go1.24"}
+	entries, err := mgr.SelectObjects(opts.ObjectFilter())
+	if err != nil {
+		t.Fatalf("SelectObjects failed: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"0a/0a01-d", "0b/0b01-a"}; !slices.Equal(names, want) {
+		t.Errorf("Expected the go1.23.4 archive and its action, got %v", names)
+	}
+}
+
+func TestObjectInfo_OlderToolchain(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"go1.23.4", true},
+		{"go1.24rc1", true},
+		{"go1.24.0", false},
+		{"go1.25.1", false},
+		{"devel go1.23-abc", false},
+	}
+	for _, tt := range tests {
+		if got := (ObjectInfo{GoVersion: tt.version}).OlderToolchain("go1.24.0"); got != tt.want {
+			t.Errorf("OlderToolchain(%s) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestBuildManager_ByPlatform(t *testing.T) {
+	dir := writeObjectCache(t)
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	mgr.SetBreakdown(Breakdown{ByPlatform: true})
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	platforms := make(map[string]int)
+	for _, group := range stats.(*BuildCacheStats).Platforms {
+		platforms[group.Name] = group.Entries
+	}
+	want := map[string]int{"linux/amd64": 2, "linux/arm64": 2, "windows/amd64": 2, otherGroup: 1}
+	if !maps.Equal(platforms, want) {
+		t.Errorf("Expected platforms %v, got %v", want, platforms)
+	}
+	if stats.(*BuildCacheStats).Toolchains != nil {
+		t.Error("Expected no toolchain breakdown unless requested")
+	}
+}
+
+func TestPruneOptions_ObjectFilter(t *testing.T) {
+	windows := ObjectInfo{GOOS: "windows", GOARCH: "amd64", GoVersion: "go1.23.4"}
+	linux := ObjectInfo{GOOS: "linux", GOARCH: "arm64", GoVersion: "go1.24.1"}
+	tests := []struct {
+		name           string
+		opts           PruneOptions
+		windows, linux bool
+	}{
+		{"platform", PruneOptions{Platforms: []string{"windows/amd64"}}, true, false},
+		{"goos", PruneOptions{Platforms: []string{"linux"}}, false, true},
+		{"several", PruneOptions{Platforms: []string{"windows", "linux/arm64"}}, true, true},
+		{"other arch", PruneOptions{Platforms: []string{"windows/arm64"}}, false, false},
+		{"toolchain and platform", PruneOptions{Platforms: []string{"linux"}, ToolchainOlderThan: "go1.24"}, false, false},
+	}
+	for _, tt := range tests {
+		keep := tt.opts.ObjectFilter()
+		if keep(windows) != tt.windows || keep(linux) != tt.linux {
+			t.Errorf("%s: got windows=%v linux=%v, want %v %v", tt.name, keep(windows), keep(linux), tt.windows, tt.linux)
+		}
+	}
+	if (PruneOptions{}).ObjectFilter() != nil {
+		t.Error("Expected no filter without a toolchain or platform")
+	}
+}
+
+func TestExecutablePlatform(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	info, ok := readObjectInfo(self)
+	if !ok || info.Platform() != runtime.GOOS+"/"+runtime.GOARCH || info.GoVersion != runtime.Version() {
+		t.Errorf("Expected the test binary to be %s/%s %s, got %+v, %v", runtime.GOOS, runtime.GOARCH, runtime.Version(), info, ok)
+	}
+
+	for _, p := range []string{"windows/amd64", "darwin", "js/wasm"} {
+		if err := ValidatePlatform(p); err != nil {
+			t.Errorf("ValidatePlatform(%q) failed: %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "windows/", "Windows", "linux/amd64/v3"} {
+		if ValidatePlatform(p) == nil {
+			t.Errorf("Expected ValidatePlatform(%q) to fail", p)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"encoding/binary"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// archiveMagic starts the archive of a compiled package
+	archiveMagic = "!<arch>\n"
+	// archiveHeaderSize is the size of the header of an archive member
+	archiveHeaderSize = 60
+	// goobjMagic starts the Go object file in the _go_.o member of an
+	// archive, after a text header ending in "\n!\n"
+	goobjMagic = "\x00go120ld"
+	// cuPackagePrefix starts the name of the DWARF symbol the compiler
+	// defines for the package it compiles, e.g.
+	// go:cuinfo.packagename.example.com/foo
+	cuPackagePrefix = "go:cuinfo.packagename."
+)
+
+// Layout of a Go object file: the magic, a fingerprint, flags and the
+// offsets of its blocks, of which the symbol definitions and references
+// are consecutive blocks of fixed-size symbols starting with a reference
+// to their name in the string table
+const (
+	goobjOffsets   = len(goobjMagic) + 8 + 4
+	goobjSymdef    = 3 // first block of symbols
+	goobjRefFlags  = 8 // first block after them
+	goobjBlocks    = 19
+	goobjSymSize   = 8 + 2 + 1 + 1 + 1 + 4 + 4
+	goobjMaxMember = 256 << 20
+	goobjMaxHeader = 4096 // text header before the object file
+)
+
+// objectPackage returns the import path of the package compiled into the
+// archive at path, or of the main package of the binary at path
+func objectPackage(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(archiveMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return "", false
+	}
+	if string(magic) != archiveMagic {
+		bi, err := buildinfo.Read(f)
+		if err != nil || bi.Path == "" {
+			return "", false
+		}
+		return bi.Path, true
+	}
+
+	off, size, ok := archiveMember(f, "_go_.o")
+	if !ok {
+		return "", false
+	}
+	// The object file follows a text header of a few lines; of it, only the
+	// string table and symbol definitions before goobjRefFlags are read
+	head := make([]byte, min(size, goobjMaxHeader))
+	if _, err := f.ReadAt(head, off); err != nil {
+		return "", false
+	}
+	i := bytes.Index(head, []byte("\n!\n"+goobjMagic))
+	if i < 0 {
+		return "", false
+	}
+	off, size = off+int64(i+3), size-int64(i+3)
+	offsets := make([]byte, goobjOffsets+4*goobjBlocks)
+	if size < int64(len(offsets)) {
+		return "", false
+	}
+	if _, err := f.ReadAt(offsets, off); err != nil {
+		return "", false
+	}
+	end := int64(binary.LittleEndian.Uint32(offsets[goobjOffsets+4*goobjRefFlags:]))
+	if end < int64(len(offsets)) || end > size {
+		return "", false
+	}
+	obj := make([]byte, end)
+	if _, err := f.ReadAt(obj, off); err != nil {
+		return "", false
+	}
+	return goobjPackage(obj)
+}
+
+// archiveMember returns the offset and size of the member called name in
+// the archive r
+func archiveMember(r io.ReaderAt, name string) (int64, int64, bool) {
+	header := make([]byte, archiveHeaderSize)
+	for off := int64(len(archiveMagic)); ; {
+		if _, err := r.ReadAt(header, off); err != nil {
+			return 0, 0, false
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 || size > goobjMaxMember {
+			return 0, 0, false
+		}
+		off += archiveHeaderSize
+		if strings.TrimRight(string(header[:16]), " /") == name {
+			return off, size, true
+		}
+		off += size + size%2 // members are padded to an even size
+	}
+}
+
+// goobjPackage finds the package path in the names of the symbols a Go
+// object file defines
+func goobjPackage(obj []byte) (string, bool) {
+	if len(obj) < goobjOffsets+4*goobjBlocks {
+		return "", false
+	}
+	offset := func(block int) int {
+		return int(binary.LittleEndian.Uint32(obj[goobjOffsets+4*block:]))
+	}
+	start, end := offset(goobjSymdef), offset(goobjRefFlags)
+	if start > end || end > len(obj) {
+		return "", false
+	}
+	for sym := start; sym+goobjSymSize <= end; sym += goobjSymSize {
+		n := int(binary.LittleEndian.Uint32(obj[sym:]))
+		at := int(binary.LittleEndian.Uint32(obj[sym+4:]))
+		if n <= len(cuPackagePrefix) || at < 0 || at+n > len(obj) {
+			continue
+		}
+		if name := string(obj[at : at+n]); strings.HasPrefix(name, cuPackagePrefix) {
+			return name[len(cuPackagePrefix):], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// platformPattern matches a target platform, e.g. windows/amd64, or a GOOS
+// alone
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+(/[a-z0-9]+)?$`)
+
+// ValidatePlatform checks a platform given to prune, e.g. "windows/amd64"
+// or "windows" for every architecture
+func ValidatePlatform(p string) error {
+	if !platformPattern.MatchString(p) {
+		return fmt.Errorf("invalid platform %q: want GOOS/GOARCH such as windows/amd64, or a GOOS", p)
+	}
+	return nil
+}
+
+// Platform returns the target of the object as GOOS/GOARCH, or "" if
+// unknown
+func (o ObjectInfo) Platform() string {
+	if o.GOOS == "" || o.GOARCH == "" {
+		return ""
+	}
+	return o.GOOS + "/" + o.GOARCH
+}
+
+// OnPlatform reports whether the object targets p, either GOOS/GOARCH or a
+// GOOS matching every architecture
+func (o ObjectInfo) OnPlatform(p string) bool {
+	if goos, goarch, ok := strings.Cut(p, "/"); ok {
+		return o.GOOS == goos && o.GOARCH == goarch
+	}
+	return o.GOOS != "" && o.GOOS == p
+}
+
+// executablePlatform returns the GOOS and GOARCH of an ELF, Mach-O or PE
+// executable, such as the binaries go run and go test link into the cache
+func executablePlatform(r io.ReaderAt) (goos, goarch string, ok bool) {
+	if f, err := elf.NewFile(r); err == nil {
+		return elfOS(f), elfArch(f), elfArch(f) != ""
+	}
+	if f, err := macho.NewFile(r); err == nil {
+		goarch := map[macho.Cpu]string{macho.CpuAmd64: "amd64", macho.CpuArm64: "arm64", macho.Cpu386: "386", macho.CpuArm: "arm"}[f.Cpu]
+		return "darwin", goarch, goarch != ""
+	}
+	if f, err := pe.NewFile(r); err == nil {
+		goarch := map[uint16]string{
+			pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+			pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+			pe.IMAGE_FILE_MACHINE_I386:  "386",
+			pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+		}[f.Machine]
+		return "windows", goarch, goarch != ""
+	}
+	return "", "", false
+}
+
+// elfOS returns the GOOS of an ELF file. Linux binaries rarely set an OS
+// ABI, so anything not naming another system is taken for linux.
+func elfOS(f *elf.File) string {
+	switch f.OSABI {
+	case elf.ELFOSABI_FREEBSD:
+		return "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		return "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		return "openbsd"
+	case elf.ELFOSABI_SOLARIS:
+		return "solaris"
+	}
+	return "linux"
+}
+
+// elfArch returns the GOARCH of an ELF file
+func elfArch(f *elf.File) string {
+	littleEndian := f.ByteOrder == binary.LittleEndian
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_RISCV:
+		return "riscv64"
+	case elf.EM_LOONGARCH:
+		return "loong64"
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_PPC64:
+		if littleEndian {
+			return "ppc64le"
+		}
+		return "ppc64"
+	case elf.EM_MIPS:
+		switch {
+		case f.Class == elf.ELFCLASS64 && littleEndian:
+			return "mips64le"
+		case f.Class == elf.ELFCLASS64:
+			return "mips64"
+		case littleEndian:
+			return "mipsle"
+		}
+		return "mips"
+	}
+	return ""
+}
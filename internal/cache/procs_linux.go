@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CacheUsers returns the processes using the cache in dir, found by
+// scanning /proc: any process with a file open below dir, and go commands
+// whose GOCACHE is dir. A go command whose environment cannot be read, or
+// that relies on the default GOCACHE, is counted too. Processes of other
+// users are only seen as far as /proc permits.
+func CacheUsers(dir string) ([]Process, error) {
+	dir = filepath.Clean(dir)
+	pids, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	var users []Process
+	for _, d := range pids {
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		proc := filepath.Join("/proc", d.Name())
+
+		comm, err := os.ReadFile(filepath.Join(proc, "comm"))
+		if err != nil {
+			continue // Exited since
+		}
+		p := Process{PID: pid, Name: strings.TrimSpace(string(comm))}
+		if cmdline, err := os.ReadFile(filepath.Join(proc, "cmdline")); err == nil {
+			p.Command = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+		}
+
+		if p.Name == "go" && goCacheOf(proc, dir) || hasOpenFileIn(proc, dir) {
+			users = append(users, p)
+		}
+	}
+	return users, nil
+}
+
+// goCacheOf reports whether the go command at proc may use the cache in
+// dir, judging by its GOCACHE or, when unset, the default location derived
+// from its XDG_CACHE_HOME or HOME. A go command whose environment cannot be
+// read is assumed to use it.
+func goCacheOf(proc, dir string) bool {
+	environ, err := os.ReadFile(filepath.Join(proc, "environ"))
+	if err != nil {
+		return true
+	}
+	env := make(map[string]string)
+	for _, v := range strings.Split(string(environ), "\x00") {
+		if key, value, ok := strings.Cut(v, "="); ok {
+			env[key] = value
+		}
+	}
+
+	switch {
+	case env["GOCACHE"] != "":
+		return filepath.Clean(env["GOCACHE"]) == dir
+	case env["XDG_CACHE_HOME"] != "":
+		return filepath.Join(env["XDG_CACHE_HOME"], "go-build") == dir
+	case env["HOME"] != "":
+		return filepath.Join(env["HOME"], ".cache", "go-build") == dir
+	}
+	return true
+}
+
+// hasOpenFileIn reports whether the process at proc has a file below dir
+// open
+func hasOpenFileIn(proc, dir string) bool {
+	fds, err := os.ReadDir(filepath.Join(proc, "fd"))
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(proc, "fd", fd.Name()))
+		if err == nil && withinDir(dir, target) {
+			return true
+		}
+	}
+	return false
+}
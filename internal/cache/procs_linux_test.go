@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCacheUsers(t *testing.T) {
+	tmpDir := t.TempDir()
+	f, err := os.Create(filepath.Join(tmpDir, "b1-d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// A child writing to the cache, as a go command would
+	cmd := exec.Command("sleep", "30")
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	users, err := CacheUsers(tmpDir)
+	if err != nil {
+		t.Fatalf("CacheUsers failed: %v", err)
+	}
+	if !slices.ContainsFunc(users, func(p Process) bool { return p.PID == cmd.Process.Pid }) {
+		t.Errorf("Expected pid %d among the users, got %+v", cmd.Process.Pid, users)
+	}
+
+	// The test itself holds the file too, but does not count
+	if slices.ContainsFunc(users, func(p Process) bool { return p.PID == os.Getpid() }) {
+		t.Errorf("Expected the current process to be ignored, got %+v", users)
+	}
+
+	other, err := CacheUsers(t.TempDir())
+	if err != nil {
+		t.Fatalf("CacheUsers failed: %v", err)
+	}
+	if slices.ContainsFunc(other, func(p Process) bool { return p.PID == cmd.Process.Pid }) {
+		t.Errorf("Expected pid %d not to use another cache, got %+v", cmd.Process.Pid, other)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cache
+
+// CacheUsers finds no processes: running go commands are only detected
+// on Linux, through /proc. The trim.txt lock still applies elsewhere.
+func CacheUsers(dir string) ([]Process, error) {
+	return nil, nil
+}
@@ -13,9 +13,18 @@ import (
 // Manager manages the Go test cache (part of build cache)
 type TestManager struct {
 	cacheDir string
+	remover
+	guard
+	ageBuckets
 }
 
-var _ CacheManager = (*TestManager)(nil)
+var (
+	_ CacheManager      = (*TestManager)(nil)
+	_ EntryLister       = (*TestManager)(nil)
+	_ RemoverSetter     = (*TestManager)(nil)
+	_ ConcurrencySetter = (*TestManager)(nil)
+	_ AgeBucketsSetter  = (*TestManager)(nil)
+)
 
 // NewManager creates a new test cache manager
 func NewTestManager(cacheDir string) (*TestManager, error) {
@@ -39,9 +48,11 @@ func NewTestManager(cacheDir string) (*TestManager, error) {
 
 // GetStats retrieves test cache statistics
 func (m *TestManager) GetStats() (Stats, error) {
+	now := time.Now()
 	stats := &TestCacheStats{
 		Location:    m.cacheDir,
-		OldestEntry: time.Now(),
+		OldestEntry: now,
+		Ages:        m.ageHistogram(),
 	}
 
 	// Walk the cache directory looking for test-related entries
@@ -50,7 +61,10 @@ func (m *TestManager) GetStats() (Stats, error) {
 			return nil
 		}
 
-		// Skip directories
+		// Skip directories, and quarantined entries altogether
+		if isTrashDir(d) {
+			return filepath.SkipDir
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -79,6 +93,7 @@ func (m *TestManager) GetStats() (Stats, error) {
 		if modTime.After(stats.NewestEntry) {
 			stats.NewestEntry = modTime
 		}
+		stats.Ages.add(now.Sub(modTime), info.Size())
 
 		return nil
 	})
@@ -95,17 +110,27 @@ func (m *TestManager) GetStats() (Stats, error) {
 	return stats, nil
 }
 
-// Clear removes test cache entries
+// Clear removes test cache entries, once no go command is using the cache
 func (m *TestManager) Clear() (int, int64, error) {
+	release, err := m.acquire(m.cacheDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to clear test cache: %w", err)
+	}
+	defer release()
+
 	var deletedCount int
 	var freedSpace int64
+	failures := &ClearError{}
 
-	err := filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(m.cacheDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		// Skip directories
+		// Skip directories, and quarantined entries altogether
+		if isTrashDir(d) {
+			return filepath.SkipDir
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -115,20 +140,25 @@ func (m *TestManager) Clear() (int, int64, error) {
 			return nil
 		}
 
-		// Get file size before deletion
 		info, err := d.Info()
-		if err == nil {
-			freedSpace += info.Size()
+		if err != nil {
+			return nil
 		}
 
-		// Delete file
-		if err := os.Remove(path); err == nil {
-			deletedCount++
+		// Delete file, counting it only once it is gone
+		if err := m.remove(path); err != nil {
+			failures.add(path, err)
+			return nil
 		}
+		deletedCount++
+		freedSpace += info.Size()
 
 		return nil
 	})
 
+	if err == nil {
+		err = failures.err()
+	}
 	if err != nil {
 		return deletedCount, freedSpace, fmt.Errorf("failed to clear test cache: %w", err)
 	}
@@ -136,6 +166,31 @@ func (m *TestManager) Clear() (int, int64, error) {
 	return deletedCount, freedSpace, nil
 }
 
+// ListEntries returns every test cache entry
+func (m *TestManager) ListEntries() ([]Entry, error) {
+	entries, err := listFiles(m.cacheDir, isTestEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk test cache: %w", err)
+	}
+	return entries, nil
+}
+
+// RemoveEntries removes test cache entries, once no go command is using
+// the cache
+func (m *TestManager) RemoveEntries(entries []Entry) (int, int64, error) {
+	release, err := m.acquire(m.cacheDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to remove test cache entries: %w", err)
+	}
+	defer release()
+
+	deleted, freed, err := m.removeFiles(m.cacheDir, entries)
+	if err != nil {
+		return deleted, freed, fmt.Errorf("failed to remove test cache entries: %w", err)
+	}
+	return deleted, freed, nil
+}
+
 // GetLocation returns the cache directory path
 func (m *TestManager) GetLocation() string {
 	return m.cacheDir
@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// buildGap separates the builds of a cache: entries written less than this
+// apart are taken to come from the same build
+const buildGap = 10 * time.Minute
+
+// Sources of a thrash analysis
+const (
+	ThrashFromIndex = "index" // action IDs recorded by measure --index
+	ThrashFromMtime = "mtime" // creation and use times of the entries
+)
+
+// ThrashOptions tunes the detection of packages rewritten on every build
+type ThrashOptions struct {
+	// Builds is how many of the latest builds are analyzed
+	Builds int
+	// MinBuilds is how many of them must have built a package to judge it
+	MinBuilds int
+	// MinRate is the share of builds rewriting a package from which it is
+	// reported
+	MinRate float64
+}
+
+// ThrashReport lists the packages whose cache entries are rewritten on
+// nearly every build
+type ThrashReport struct {
+	Source string    `json:"source"` // index or mtime
+	Builds int       `json:"builds"` // builds analyzed
+	First  time.Time `json:"first,omitzero"`
+	Last   time.Time `json:"last,omitzero"`
+	// Packages are ordered by rewrite rate, highest first
+	Packages []ThrashPackage `json:"packages"`
+}
+
+// ThrashPackage is a package rewritten to the cache on most builds
+type ThrashPackage struct {
+	Package string `json:"package"`
+	// Builds counts the analyzed builds since the package was first built;
+	// Rewrites those after the first that wrote it anew
+	Builds      int     `json:"builds"`
+	Rewrites    int     `json:"rewrites"`
+	RewriteRate float64 `json:"rewrite_rate"` // rewrites per build after the first
+	// Reused counts the builds that found it cached, from the index, or its
+	// entries used again after they were written, from mtimes
+	Reused int `json:"reused"`
+	// Varying are the inputs that changed on rewrites, most often first;
+	// only known from the index
+	Varying []VaryingInput `json:"varying,omitempty"`
+}
+
+// VaryingInput is an input of a package that changed between builds
+type VaryingInput struct {
+	Kind    string `json:"kind"`
+	Key     string `json:"key"`
+	Changes int    `json:"changes"` // rewrites in which it changed
+	// Old and New are its values in the latest change
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// packageHistory follows one package through the analyzed builds
+type packageHistory struct {
+	first, last int // first and last build that wrote or built it
+	builds      map[int]bool
+	reused      int
+	varying     map[[2]string]*VaryingInput
+}
+
+func newPackageHistory(build int) *packageHistory {
+	return &packageHistory{first: build, builds: make(map[int]bool), varying: make(map[[2]string]*VaryingInput)}
+}
+
+// thrashing returns the package if it was rewritten often enough, judged
+// over the builds from its first to builds
+func (h *packageHistory) thrashing(pkg string, builds, rewrites int, opts ThrashOptions) (ThrashPackage, bool) {
+	if builds < max(opts.MinBuilds, 2) {
+		return ThrashPackage{}, false
+	}
+	p := ThrashPackage{
+		Package:     pkg,
+		Builds:      builds,
+		Rewrites:    rewrites,
+		RewriteRate: float64(rewrites) / float64(builds-1),
+		Reused:      h.reused,
+	}
+	if p.RewriteRate < opts.MinRate {
+		return ThrashPackage{}, false
+	}
+	for _, in := range h.varying {
+		p.Varying = append(p.Varying, *in)
+	}
+	sort.Slice(p.Varying, func(i, j int) bool {
+		if p.Varying[i].Changes != p.Varying[j].Changes {
+			return p.Varying[i].Changes > p.Varying[j].Changes
+		}
+		return p.Varying[i].Key < p.Varying[j].Key
+	})
+	return p, true
+}
+
+// sortThrashing orders packages by rewrite rate, then rewrites, then name
+func sortThrashing(packages []ThrashPackage) {
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.RewriteRate != b.RewriteRate {
+			return a.RewriteRate > b.RewriteRate
+		}
+		if a.Rewrites != b.Rewrites {
+			return a.Rewrites > b.Rewrites
+		}
+		return a.Package < b.Package
+	})
+}
+
+// AnalyzeIndex finds the packages whose action ID changed on nearly every
+// build recorded in an action index, and the inputs that changed with it
+func AnalyzeIndex(records []IndexRecord, opts ThrashOptions) *ThrashReport {
+	if opts.Builds > 0 && len(records) > opts.Builds {
+		records = records[len(records)-opts.Builds:]
+	}
+	report := &ThrashReport{Source: ThrashFromIndex, Builds: len(records), Packages: []ThrashPackage{}}
+	if len(records) == 0 {
+		return report
+	}
+	report.First, report.Last = records[0].Recorded, records[len(records)-1].Recorded
+
+	histories := make(map[string]*packageHistory)
+	rewrites := make(map[string]int)
+	for i, record := range records {
+		for name, action := range record.Actions {
+			h := histories[name]
+			if h == nil {
+				h = newPackageHistory(i)
+				histories[name] = h
+			}
+			h.builds[i] = true
+			if i == h.first {
+				h.last = i
+				continue
+			}
+
+			previous := records[h.last].Actions
+			h.last = i
+			if previous[name].ID == action.ID {
+				h.reused++
+				continue
+			}
+			rewrites[name]++
+			for _, change := range Diff(previous, record.Actions, name) {
+				if change.Action != name {
+					continue
+				}
+				key := [2]string{change.Kind, change.Key}
+				in := h.varying[key]
+				if in == nil {
+					in = &VaryingInput{Kind: change.Kind, Key: change.Key}
+					h.varying[key] = in
+				}
+				in.Changes++
+				in.Old, in.New = change.Old, change.New
+			}
+		}
+	}
+
+	for name, h := range histories {
+		if p, ok := h.thrashing(strings.TrimPrefix(name, "build "), len(h.builds), rewrites[name], opts); ok {
+			report.Packages = append(report.Packages, p)
+		}
+	}
+	sortThrashing(report.Packages)
+	return report
+}
+
+// actionWrite is an action file of the build cache
+type actionWrite struct {
+	output  string
+	created time.Time
+	reused  bool // used again at least mtimeInterval after it was written
+	build   int
+}
+
+// Thrash finds the packages the cache has written anew on nearly every
+// build, without an action index: the action files tell when they were
+// written, their mtimes whether they were used again, and the outputs they
+// name which package was compiled or linked. Builds are told apart by
+// pauses of at least buildGap between writes.
+func (m *BuildManager) Thrash(opts ThrashOptions) (*ThrashReport, error) {
+	entries, err := listFiles(m.cacheDir, func(path string) bool {
+		return isTrimmable(m.cacheDir, path) && strings.HasSuffix(path, "-a")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk build cache: %w", err)
+	}
+	var writes []actionWrite
+	for _, entry := range entries {
+		output, created, ok := actionWritten(entry.Path)
+		if !ok {
+			continue
+		}
+		writes = append(writes, actionWrite{
+			output:  output,
+			created: created,
+			reused:  entry.ModTime.Sub(created) >= mtimeInterval,
+		})
+	}
+	sort.Slice(writes, func(i, j int) bool { return writes[i].created.Before(writes[j].created) })
+
+	builds := 0
+	for i := range writes {
+		if i == 0 || writes[i].created.Sub(writes[i-1].created) >= buildGap {
+			builds++
+		}
+		writes[i].build = builds - 1
+	}
+	report := &ThrashReport{Source: ThrashFromMtime, Packages: []ThrashPackage{}}
+	first := 0
+	if opts.Builds > 0 {
+		first = max(builds-opts.Builds, 0)
+	}
+	report.Builds = builds - first
+
+	// Only the writes of analyzed builds are traced to their package
+	packages := make(map[string]string)
+	histories := make(map[string]*packageHistory)
+	for _, write := range writes {
+		if write.build < first {
+			continue
+		}
+		if report.First.IsZero() {
+			report.First = write.created
+		}
+		report.Last = write.created
+
+		pkg, ok := packages[write.output]
+		if !ok {
+			pkg, _ = objectPackage(filepath.Join(m.cacheDir, write.output[:2], write.output+"-d"))
+			packages[write.output] = pkg
+		}
+		if pkg == "" {
+			continue
+		}
+		h := histories[pkg]
+		if h == nil {
+			h = newPackageHistory(write.build)
+			histories[pkg] = h
+		}
+		h.builds[write.build] = true
+		if write.reused {
+			h.reused++
+		}
+	}
+
+	for pkg, h := range histories {
+		if p, ok := h.thrashing(pkg, builds-h.first, len(h.builds)-1, opts); ok {
+			report.Packages = append(report.Packages, p)
+		}
+	}
+	sortThrashing(report.Packages)
+	return report, nil
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeArchive returns the archive of a compiled package whose Go object
+// defines the DWARF symbol naming pkg
+func fakeArchive(pkg string) []byte {
+	name := []byte(cuPackagePrefix + pkg)
+	symStart := goobjOffsets + 4*goobjBlocks + len(name)
+	obj := make([]byte, symStart+goobjSymSize)
+	copy(obj, goobjMagic)
+	for block := range goobjBlocks {
+		at := symStart
+		if block > goobjSymdef {
+			at += goobjSymSize
+		}
+		binary.LittleEndian.PutUint32(obj[goobjOffsets+4*block:], uint32(at))
+	}
+	copy(obj[goobjOffsets+4*goobjBlocks:], name)
+	binary.LittleEndian.PutUint32(obj[symStart:], uint32(len(name)))
+	binary.LittleEndian.PutUint32(obj[symStart+4:], uint32(goobjOffsets+4*goobjBlocks))
+
+	member := func(name string, data []byte) []byte {
+		header := fmt.Sprintf("%-16s%-12s%-6s%-6s%-8s%-10d`\n", name, "0", "0", "0", "644", len(data))
+		if len(data)%2 == 1 {
+			data = append(data, '\n')
+		}
+		return append([]byte(header), data...)
+	}
+	archive := []byte(archiveMagic)
+	archive = append(archive, member("__.PKGDEF", []byte("go object linux amd64 go1.24.1 X:none\n"))...)
+	archive = append(archive, member("_go_.o", append([]byte("go object linux amd64 go1.24.1 X:none\n!\n"), obj...))...)
+	return archive
+}
+
+func TestObjectPackage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0a01-d")
+	if err := os.WriteFile(path, fakeArchive("example.com/foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if pkg, ok := objectPackage(path); !ok || pkg != "example.com/foo" {
+		t.Errorf("Expected example.com/foo, got %q, %v", pkg, ok)
+	}
+
+	if err := os.WriteFile(path, []byte("ok  \texample.com/foo\t0.01s\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if pkg, ok := objectPackage(path); ok {
+		t.Errorf("Expected no package for a test result, got %q", pkg)
+	}
+}
+
+func TestBuildManager_Thrash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte, mtime time.Time) {
+		path := filepath.Join(dir, name[:2], name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	action := func(action, output string, created, used time.Time) {
+		write(action+"-a", fmt.Appendf(nil, "v1 %s %s 10 %d\n", action, output, created.UnixNano()), used)
+	}
+
+	// Four builds an hour apart: example.com/gen is written anew by each,
+	// example.com/lib by the first and used by the last
+	start := time.Now().Add(-4 * time.Hour)
+	build := func(i int) time.Time { return start.Add(time.Duration(i) * time.Hour) }
+	write("1a01-d", fakeArchive("example.com/lib"), build(3))
+	action("1b01", "1a01", build(0), build(3))
+	for i := range 4 {
+		output := fmt.Sprintf("2a0%d", i)
+		write(output+"-d", fakeArchive("example.com/gen"), build(i))
+		action(fmt.Sprintf("2b0%d", i), output, build(i).Add(time.Second), build(i).Add(time.Second))
+	}
+
+	mgr, err := NewBuildManager(dir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	report, err := mgr.Thrash(ThrashOptions{Builds: 20, MinBuilds: 3, MinRate: 0.8})
+	if err != nil {
+		t.Fatalf("Thrash failed: %v", err)
+	}
+	if report.Source != ThrashFromMtime || report.Builds != 4 {
+		t.Errorf("Expected 4 builds from mtimes, got %d from %s", report.Builds, report.Source)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("Expected only example.com/gen to thrash, got %+v", report.Packages)
+	}
+	gen := report.Packages[0]
+	if gen.Package != "example.com/gen" || gen.Builds != 4 || gen.Rewrites != 3 || gen.RewriteRate != 1 || gen.Reused != 0 {
+		t.Errorf("Expected example.com/gen rewritten on 3 of 3 builds, got %+v", gen)
+	}
+
+	// Only the last two builds
+	report, err = mgr.Thrash(ThrashOptions{Builds: 2, MinBuilds: 2, MinRate: 0.8})
+	if err != nil {
+		t.Fatalf("Thrash failed: %v", err)
+	}
+	if report.Builds != 2 || len(report.Packages) != 1 || report.Packages[0].Rewrites != 1 {
+		t.Errorf("Expected one rewrite in the last 2 builds, got %d builds and %+v", report.Builds, report.Packages)
+	}
+}
+
+func TestAnalyzeIndex(t *testing.T) {
+	var records []IndexRecord
+	for i, fooHash := range []string{"aaaa1111", "bbbb2222", "cccc3333", "dddd4444"} {
+		records = append(records, IndexRecord{Recorded: time.Unix(int64(i), 0), Actions: traceOf(t, fooHash, "v1")})
+	}
+	opts := ThrashOptions{Builds: 20, MinBuilds: 3, MinRate: 0.8}
+
+	report := AnalyzeIndex(records, opts)
+	if report.Source != ThrashFromIndex || report.Builds != 4 {
+		t.Errorf("Expected 4 builds from the index, got %d from %s", report.Builds, report.Source)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("Expected app and lib to thrash, got %+v", report.Packages)
+	}
+	for _, p := range report.Packages {
+		if p.Builds != 4 || p.Rewrites != 3 || p.Reused != 0 || len(p.Varying) != 1 {
+			t.Errorf("Expected %s rewritten on every build for one input, got %+v", p.Package, p)
+			continue
+		}
+		want := VaryingInput{Kind: InputSource, Key: "foo.go", Changes: 3, Old: "cccc3333", New: "dddd4444"}
+		if p.Package == "example.com/app" {
+			want = VaryingInput{Kind: InputDependency, Key: "example.com/lib", Changes: 3, Old: "ccccv1", New: "ddddv1"}
+		}
+		if p.Varying[0] != want {
+			t.Errorf("Expected %s to vary by %+v, got %+v", p.Package, want, p.Varying[0])
+		}
+	}
+
+	// Rebuilt once in four builds is not thrashing
+	records[1].Actions, records[2].Actions = records[0].Actions, records[0].Actions
+	if report := AnalyzeIndex(records, opts); len(report.Packages) != 0 {
+		t.Errorf("Expected no thrashing package, got %+v", report.Packages)
+	}
+}
+
+func TestAppendIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "actions.jsonl")
+	if records, err := ReadIndex(path); err != nil || len(records) != 0 {
+		t.Fatalf("Expected a missing index to be empty, got %d records, %v", len(records), err)
+	}
+
+	trace := traceOf(t, "aaaa1111", "v1")
+	trace["link example.com/app"] = &ActionHash{Action: "link example.com/app", ID: actionID("link")}
+	for range IndexLimit + 2 {
+		if err := AppendIndex(path, []string{"go", "build", "./..."}, trace); err != nil {
+			t.Fatalf("AppendIndex failed: %v", err)
+		}
+	}
+
+	records, err := ReadIndex(path)
+	if err != nil {
+		t.Fatalf("ReadIndex failed: %v", err)
+	}
+	if len(records) != IndexLimit {
+		t.Errorf("Expected the last %d builds, got %d", IndexLimit, len(records))
+	}
+	actions := records[0].Actions
+	if len(actions) != 2 || actions["build example.com/lib"] == nil || actions["link example.com/app"] != nil {
+		t.Errorf("Expected only the package builds, got %v", actions)
+	}
+}
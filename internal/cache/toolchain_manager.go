@@ -0,0 +1,345 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"go/version"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// toolchainModule is the module the go command downloads toolchains as
+// when GOTOOLCHAIN=auto selects a release newer than the local one
+const toolchainModule = "golang.org/toolchain"
+
+// ToolchainManager manages Go toolchains stored in the module cache
+type ToolchainManager struct {
+	cacheDir string
+	roots    []string
+	remover
+}
+
+var (
+	_ CacheManager  = (*ToolchainManager)(nil)
+	_ RemoverSetter = (*ToolchainManager)(nil)
+)
+
+// NewToolchainManager creates a new toolchain manager. Projects below roots
+// are scanned for go.mod and go.work toolchain and go lines to tell which
+// toolchains are still needed.
+func NewToolchainManager(cacheDir string, roots []string) (*ToolchainManager, error) {
+	if cacheDir == "" {
+		dir, err := GetGoEnv("GOMODCACHE")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GOMODCACHE: %w", err)
+		}
+		cacheDir = dir
+	}
+
+	// Verify cache directory exists
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("module cache directory does not exist: %s", cacheDir)
+	}
+
+	return &ToolchainManager{
+		cacheDir: cacheDir,
+		roots:    roots,
+	}, nil
+}
+
+// GetStats retrieves toolchain statistics
+func (m *ToolchainManager) GetStats() (Stats, error) {
+	toolchains, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ToolchainCacheStats{
+		Location:       m.cacheDir,
+		ToolchainCount: len(toolchains),
+		Toolchains:     toolchains,
+	}
+	for _, tc := range toolchains {
+		stats.Size += tc.Size
+	}
+
+	return stats, nil
+}
+
+// List returns the installed toolchains, newest first
+func (m *ToolchainManager) List() ([]ToolchainInfo, error) {
+	byVersion := make(map[string]*ToolchainInfo)
+	lookup := func(modVersion string) *ToolchainInfo {
+		if tc, ok := byVersion[modVersion]; ok {
+			return tc
+		}
+		version, platform, ok := parseToolchainVersion(modVersion)
+		if !ok {
+			return nil
+		}
+		tc := &ToolchainInfo{
+			Version:  version,
+			Platform: platform,
+			Path:     m.extractedDir(modVersion),
+		}
+		byVersion[modVersion] = tc
+		return tc
+	}
+
+	// Extracted toolchains: $GOMODCACHE/golang.org/toolchain@<version>
+	entries, err := os.ReadDir(filepath.Dir(m.extractedDir("")))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read toolchains: %w", err)
+	}
+	for _, e := range entries {
+		modVersion, ok := strings.CutPrefix(e.Name(), "toolchain@")
+		if !ok || !e.IsDir() {
+			continue
+		}
+		if tc := lookup(modVersion); tc != nil {
+			tc.Size += dirSize(tc.Path)
+		}
+	}
+
+	// Downloaded archives: $GOMODCACHE/cache/download/golang.org/toolchain/@v/<version>.zip etc.
+	entries, err = os.ReadDir(m.downloadDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read toolchain downloads: %w", err)
+	}
+	for _, e := range entries {
+		modVersion := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		if e.IsDir() || modVersion == "list" {
+			continue
+		}
+		tc := lookup(modVersion)
+		if tc == nil {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			tc.Size += info.Size()
+		}
+	}
+
+	usage := m.scanUsage()
+
+	var toolchains []ToolchainInfo
+	for _, tc := range byVersion {
+		if u, ok := usage[tc.Version]; ok {
+			tc.LastNeeded = u.lastNeeded
+			tc.UsedBy = u.files
+		}
+		toolchains = append(toolchains, *tc)
+	}
+
+	sort.Slice(toolchains, func(i, j int) bool {
+		if c := compareGoVersions(toolchains[i].Version, toolchains[j].Version); c != 0 {
+			return c > 0
+		}
+		return toolchains[i].Platform < toolchains[j].Platform
+	})
+
+	return toolchains, nil
+}
+
+// Unused returns the installed toolchains no project below the roots needs.
+// It requires at least one root, since without them every toolchain would
+// look unused.
+func (m *ToolchainManager) Unused() ([]ToolchainInfo, error) {
+	if len(m.roots) == 0 {
+		return nil, fmt.Errorf("no project roots to check toolchain usage against")
+	}
+
+	toolchains, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []ToolchainInfo
+	for _, tc := range toolchains {
+		if len(tc.UsedBy) == 0 {
+			unused = append(unused, tc)
+		}
+	}
+	return unused, nil
+}
+
+// Remove deletes a toolchain's extracted tree and downloaded files
+func (m *ToolchainManager) Remove(tc ToolchainInfo) (int64, error) {
+	modVersion := strings.TrimPrefix(filepath.Base(tc.Path), "toolchain@")
+
+	_, freed, err := m.removeTree(tc.Path)
+	if err != nil {
+		return freed, fmt.Errorf("failed to remove toolchain %s: %w", tc.Version, err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(m.downloadDir(), modVersion+".*"))
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := m.remove(path); err == nil {
+			freed += info.Size()
+		}
+	}
+
+	return freed, nil
+}
+
+// Clear removes all downloaded toolchains
+func (m *ToolchainManager) Clear() (int, int64, error) {
+	toolchains, err := m.List()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var deletedCount int
+	var freedSpace int64
+	failures := &ClearError{}
+	for _, tc := range toolchains {
+		freed, err := m.Remove(tc)
+		freedSpace += freed
+		if err != nil {
+			failures.add(tc.Path, err)
+			continue
+		}
+		deletedCount++
+	}
+
+	if err := failures.err(); err != nil {
+		return deletedCount, freedSpace, fmt.Errorf("failed to clear toolchains: %w", err)
+	}
+	return deletedCount, freedSpace, nil
+}
+
+// GetLocation returns the cache directory path
+func (m *ToolchainManager) GetLocation() string {
+	return m.cacheDir
+}
+
+func (m *ToolchainManager) extractedDir(modVersion string) string {
+	return filepath.Join(m.cacheDir, filepath.FromSlash(toolchainModule)+"@"+modVersion)
+}
+
+func (m *ToolchainManager) downloadDir() string {
+	return filepath.Join(m.cacheDir, "cache", "download", filepath.FromSlash(toolchainModule), "@v")
+}
+
+// toolchainUsage records which project files require a toolchain
+type toolchainUsage struct {
+	lastNeeded time.Time
+	files      []string
+}
+
+// scanUsage walks the project roots for go.mod and go.work files and
+// collects the toolchains they require
+func (m *ToolchainManager) scanUsage() map[string]*toolchainUsage {
+	usage := make(map[string]*toolchainUsage)
+
+	for _, root := range m.roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if d.IsDir() {
+				if path != root && skipProjectDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if d.Name() != "go.mod" && d.Name() != "go.work" {
+				return nil
+			}
+
+			version := readRequiredToolchain(path)
+			if version == "" {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			u, ok := usage[version]
+			if !ok {
+				u = &toolchainUsage{}
+				usage[version] = u
+			}
+			u.files = append(u.files, path)
+			if info.ModTime().After(u.lastNeeded) {
+				u.lastNeeded = info.ModTime()
+			}
+			return nil
+		})
+	}
+
+	return usage
+}
+
+// skipProjectDir reports whether a directory cannot contain projects of interest
+func skipProjectDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules"
+}
+
+// readRequiredToolchain returns the toolchain a go.mod or go.work file
+// requires: the one named by its toolchain line or, since go mod tidy drops
+// a toolchain line equal to the go version, the release named by its go
+// line. A language version such as "go 1.22" names the go1.22.0 release. It
+// returns "" if the file has neither.
+func readRequiredToolchain(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var goVersion string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch {
+		case fields[0] == "toolchain" && strings.HasPrefix(fields[1], "go1"):
+			return fields[1]
+		case fields[0] == "go" && strings.HasPrefix(fields[1], "1"):
+			goVersion = "go" + fields[1]
+			// since Go 1.21 the first release of a language version is x.y.0
+			if version.Lang(goVersion) == goVersion && version.Compare(goVersion, "go1.21") >= 0 {
+				goVersion += ".0"
+			}
+		}
+	}
+	return goVersion
+}
+
+// parseToolchainVersion splits a toolchain module version such as
+// "v0.0.1-go1.22.0.linux-amd64" into "go1.22.0" and "linux-amd64"
+func parseToolchainVersion(modVersion string) (string, string, bool) {
+	_, rest, ok := strings.Cut(modVersion, "-")
+	if !ok || !strings.HasPrefix(rest, "go") {
+		return "", "", false
+	}
+
+	i := strings.LastIndex(rest, ".")
+	if i < 0 || !strings.Contains(rest[i+1:], "-") {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// isToolchainPath reports whether a path relative to the module cache
+// belongs to a downloaded toolchain
+func isToolchainPath(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	return strings.HasPrefix(rel, toolchainModule+"@") ||
+		rel == "cache/download/"+toolchainModule
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeToolchain creates the module cache layout of a downloaded toolchain
+func writeToolchain(t *testing.T, cacheDir, modVersion string) {
+	t.Helper()
+
+	binDir := filepath.Join(cacheDir, "golang.org", "toolchain@"+modVersion, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "go"), []byte("toolchain binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dlDir := filepath.Join(cacheDir, "cache", "download", "golang.org", "toolchain", "@v")
+	if err := os.MkdirAll(dlDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".zip", ".mod", ".info"} {
+		if err := os.WriteFile(filepath.Join(dlDir, modVersion+ext), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestToolchainManager_List(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeToolchain(t, cacheDir, "v0.0.1-go1.22.0.linux-amd64")
+	writeToolchain(t, cacheDir, "v0.0.1-go1.23.4.linux-amd64")
+
+	projectDir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.22\n\ntoolchain go1.23.4\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewToolchainManager(cacheDir, []string{projectDir})
+	if err != nil {
+		t.Fatalf("NewToolchainManager failed: %v", err)
+	}
+
+	toolchains, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(toolchains) != 2 {
+		t.Fatalf("Expected 2 toolchains, got %d", len(toolchains))
+	}
+	if toolchains[0].Version != "go1.23.4" || toolchains[0].Platform != "linux-amd64" {
+		t.Errorf("Expected go1.23.4 linux-amd64 first, got %s %s", toolchains[0].Version, toolchains[0].Platform)
+	}
+	if len(toolchains[0].UsedBy) != 1 || toolchains[0].LastNeeded.IsZero() {
+		t.Errorf("Expected go1.23.4 to be needed by go.mod, got %+v", toolchains[0])
+	}
+	// 16 bytes of binary plus three 4 byte download files
+	if toolchains[1].Size != 28 {
+		t.Errorf("Expected size 28, got %d", toolchains[1].Size)
+	}
+
+	unused, err := mgr.Unused()
+	if err != nil {
+		t.Fatalf("Unused failed: %v", err)
+	}
+	if len(unused) != 1 || unused[0].Version != "go1.22.0" {
+		t.Fatalf("Expected go1.22.0 to be unused, got %+v", unused)
+	}
+
+	freed, err := mgr.Remove(unused[0])
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if freed != 28 {
+		t.Errorf("Expected 28 bytes freed, got %d", freed)
+	}
+	if _, err := os.Stat(unused[0].Path); !os.IsNotExist(err) {
+		t.Error("Toolchain directory should have been deleted")
+	}
+}
+
+func TestModManager_SkipsToolchains(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeToolchain(t, cacheDir, "v0.0.1-go1.22.0.linux-amd64")
+
+	modulePath := filepath.Join(cacheDir, "github.com", "user", "repo@v1.0.0")
+	if err := os.MkdirAll(modulePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modulePath, "go.mod"), []byte("module github.com/user/repo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewModManager(cacheDir)
+	if err != nil {
+		t.Fatalf("NewModManager failed: %v", err)
+	}
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+
+	modStats := stats.(*ModCacheStats)
+	if modStats.ModuleCount != 1 {
+		t.Errorf("Expected 1 module, got %d", modStats.ModuleCount)
+	}
+}
+
+func TestParseToolchainVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		version  string
+		platform string
+		ok       bool
+	}{
+		{"v0.0.1-go1.22.0.linux-amd64", "go1.22.0", "linux-amd64", true},
+		{"v0.0.1-go1.21rc2.darwin-arm64", "go1.21rc2", "darwin-arm64", true},
+		{"v1.0.0", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			version, platform, ok := parseToolchainVersion(tt.input)
+			if version != tt.version || platform != tt.platform || ok != tt.ok {
+				t.Errorf("parseToolchainVersion(%q) = %q, %q, %v", tt.input, version, platform, ok)
+			}
+		})
+	}
+}
+
+func TestToolchainManager_GoLineOnly(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeToolchain(t, cacheDir, "v0.0.1-go1.22.0.linux-amd64")
+	writeToolchain(t, cacheDir, "v0.0.1-go1.23.4.linux-amd64")
+
+	// go mod tidy drops a toolchain line equal to the go version
+	projectDir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.23.4\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewToolchainManager(cacheDir, []string{projectDir})
+	if err != nil {
+		t.Fatalf("NewToolchainManager failed: %v", err)
+	}
+	unused, err := mgr.Unused()
+	if err != nil {
+		t.Fatalf("Unused failed: %v", err)
+	}
+	if len(unused) != 1 || unused[0].Version != "go1.22.0" {
+		t.Fatalf("Expected only go1.22.0 to be unused, got %+v", unused)
+	}
+}
+
+func TestReadRequiredToolchain_LanguageVersion(t *testing.T) {
+	tests := []struct {
+		goMod string
+		want  string
+	}{
+		{"module m\n\ngo 1.23.4\n", "go1.23.4"},
+		{"module m\n\ngo 1.22\n", "go1.22.0"},
+		{"module m\n\ngo 1.22rc1\n", "go1.22rc1"},
+		{"module m\n\ngo 1.20\n", "go1.20"},
+		{"module m\n\ngo 1.22\n\ntoolchain go1.22.5\n", "go1.22.5"},
+		{"module m\n", ""},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "go.mod")
+		if err := os.WriteFile(path, []byte(tt.goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := readRequiredToolchain(path); got != tt.want {
+			t.Errorf("readRequiredToolchain(%q) = %q, want %q", tt.goMod, got, tt.want)
+		}
+	}
+}
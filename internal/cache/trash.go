@@ -0,0 +1,470 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// TrashDirName is the directory inside a cache root that quarantined
+// entries are moved to. Keeping it inside the root keeps moves on the same
+// filesystem, so quarantining is a rename rather than a copy.
+const TrashDirName = ".gocachectl-trash"
+
+// manifestName is the file describing a trash operation
+const manifestName = "manifest.json"
+
+// journalName is the file a trash operation appends each entry to before
+// moving it, so that an operation interrupted before writing its manifest
+// can still be restored
+const journalName = "journal.jsonl"
+
+// Trash is the quarantine area of one cache root. Every quarantining clear
+// adds an operation to it: a directory named by the operation ID holding
+// the moved entries below files/, laid out as they were in the root, and a
+// manifest listing them. Until the manifest is written, a journal lists
+// the entries moved so far.
+type Trash struct {
+	root string
+}
+
+// NewTrash returns the trash of a cache root
+func NewTrash(root string) *Trash {
+	return &Trash{root: root}
+}
+
+// Dir returns the trash directory
+func (t *Trash) Dir() string {
+	return filepath.Join(t.root, TrashDirName)
+}
+
+// NewTrashID returns an operation ID for a quarantine started at now.
+// IDs sort in time order, and one ID is shared by every root an operation
+// touches.
+func NewTrashID(now time.Time) string {
+	return now.UTC().Format(trashIDLayout)
+}
+
+// trashIDLayout is the time layout of trash operation IDs
+const trashIDLayout = "20060102T150405.000000000Z"
+
+// TrashOperation is one quarantining operation in one trash
+type TrashOperation struct {
+	ID      string       `json:"id"`
+	Time    time.Time    `json:"time"`
+	Root    string       `json:"root"`
+	Entries []TrashEntry `json:"entries"`
+	// Restored is when undo restored the operation, zero until then
+	Restored time.Time `json:"restored,omitzero"`
+	// Interrupted operations lack a manifest, because they crashed or are
+	// still running; their entries come from their journal
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Incomplete operations lack both a manifest and a journal; they can
+	// only be purged
+	Incomplete bool `json:"incomplete,omitempty"`
+
+	dir string
+	// updated is when an uncommitted operation last changed
+	updated time.Time
+}
+
+// TrashEntry is a file or directory moved into the trash
+type TrashEntry struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path"` // original location
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+	// Modes holds the permissions of the read-only directories of a
+	// directory entry, keyed by path relative to it, which undo restores
+	Modes map[string]fs.FileMode `json:"modes,omitempty"`
+	// Replaced entries are copies of files rewritten in place, such as
+	// the version list of a module, which undo puts back over them
+	Replaced bool `json:"replaced,omitempty"`
+	// Skipped is set when undo left the entry in the trash because the
+	// go command had recreated it
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// InProgress reports whether an operation without a manifest may still be
+// running in another process: it changed less than grace ago
+func (op *TrashOperation) InProgress(now time.Time, grace time.Duration) bool {
+	return (op.Interrupted || op.Incomplete) && now.Sub(op.updated) < grace
+}
+
+// Size returns the combined size of the entries still in the trash: all
+// of them, or once restored just those skipped
+func (op *TrashOperation) Size() int64 {
+	var size int64
+	for _, entry := range op.Entries {
+		if op.Restored.IsZero() || entry.Skipped {
+			size += entry.Size
+		}
+	}
+	return size
+}
+
+// Operations returns the operations in the trash, oldest first
+func (t *Trash) Operations() ([]*TrashOperation, error) {
+	dirs, err := os.ReadDir(t.Dir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var ops []*TrashOperation
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(t.Dir(), d.Name())
+		op := &TrashOperation{ID: d.Name(), Root: t.root, dir: dir}
+
+		data, err := os.ReadFile(filepath.Join(dir, manifestName))
+		if err == nil {
+			err = json.Unmarshal(data, op)
+		}
+		if err != nil {
+			op.Entries, err = readJournal(filepath.Join(dir, journalName))
+			op.Interrupted, op.Incomplete = err == nil, err != nil
+			if info, err := d.Info(); err == nil {
+				op.updated = info.ModTime()
+			}
+			if info, err := os.Stat(filepath.Join(dir, journalName)); err == nil && info.ModTime().After(op.updated) {
+				op.updated = info.ModTime()
+			}
+			if op.Time, err = time.Parse(trashIDLayout, d.Name()); err != nil {
+				op.Time = op.updated
+			}
+		}
+		op.ID, op.Root, op.dir = d.Name(), t.root, dir
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+	return ops, nil
+}
+
+// Quarantine starts an operation that moves removed paths into the trash
+// instead of deleting them. Commit writes its manifest.
+func (t *Trash) Quarantine(id string, now time.Time) *Quarantine {
+	return &Quarantine{
+		trash: t,
+		op: &TrashOperation{
+			ID:   id,
+			Time: now,
+			Root: t.root,
+			dir:  filepath.Join(t.Dir(), id),
+		},
+	}
+}
+
+// Quarantine moves paths below a cache root into a trash operation
+type Quarantine struct {
+	trash   *Trash
+	op      *TrashOperation
+	journal *os.File
+}
+
+// For returns a Remover quarantining paths as entries of kind
+func (q *Quarantine) For(kind string) Remover {
+	return quarantineRemover{q: q, kind: kind}
+}
+
+// move renames path into the operation, recording it as an entry
+func (q *Quarantine) move(kind, path string) error {
+	rel, err := filepath.Rel(q.trash.root, path)
+	if err != nil || !withinDir(q.trash.root, path) {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: fmt.Errorf("not inside %s", q.trash.root)}
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	entry := TrashEntry{Kind: kind, Path: path, Size: info.Size(), Files: 1}
+	if info.IsDir() {
+		summary, _ := scanFiles(path)
+		entry.Size, entry.Files = summary.size, summary.count
+		entry.Modes = readOnlyDirs(path)
+	}
+
+	dest := filepath.Join(q.op.dir, "files", rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: err}
+	}
+	if err := q.record(entry); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: err}
+	}
+	// Moving a directory to another parent rewrites its .. entry, which
+	// takes write permission on it
+	if info.IsDir() && info.Mode().Perm()&0o200 == 0 {
+		if err := os.Chmod(path, info.Mode().Perm()|0o700); err != nil {
+			return &fs.PathError{Op: "quarantine", Path: path, Err: errors.Unwrap(err)}
+		}
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: errors.Unwrap(err)}
+	}
+
+	q.op.Entries = append(q.op.Entries, entry)
+	return nil
+}
+
+// keep copies path into the operation before it is rewritten, recording it
+// as a replaced entry. Only the first copy of a path is kept, as it holds
+// the contents from before the operation.
+func (q *Quarantine) keep(kind, path string) error {
+	rel, err := filepath.Rel(q.trash.root, path)
+	if err != nil || !withinDir(q.trash.root, path) {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: fmt.Errorf("not inside %s", q.trash.root)}
+	}
+	for _, entry := range q.op.Entries {
+		if entry.Path == path {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	entry := TrashEntry{Kind: kind, Path: path, Size: info.Size(), Files: 1, Replaced: true}
+
+	dest := filepath.Join(q.op.dir, "files", rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: err}
+	}
+	if err := q.record(entry); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: err}
+	}
+	if err := os.WriteFile(dest, data, info.Mode().Perm()); err != nil {
+		return &fs.PathError{Op: "quarantine", Path: path, Err: errors.Unwrap(err)}
+	}
+
+	q.op.Entries = append(q.op.Entries, entry)
+	return nil
+}
+
+// readOnlyDirs returns the permissions of the directories below root,
+// root included, that are not writable, such as the trees the go command
+// extracts modules into
+func readOnlyDirs(root string) map[string]fs.FileMode {
+	modes := make(map[string]fs.FileMode)
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Mode().Perm()&0o200 != 0 {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			modes[filepath.ToSlash(rel)] = info.Mode().Perm()
+		}
+		return nil
+	})
+	if len(modes) == 0 {
+		return nil
+	}
+	return modes
+}
+
+// record appends an entry to the operation's journal before it is moved
+func (q *Quarantine) record(entry TrashEntry) error {
+	if q.journal == nil {
+		f, err := os.OpenFile(filepath.Join(q.op.dir, journalName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		q.journal = f
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = q.journal.Write(append(data, '\n'))
+	return err
+}
+
+// readJournal reads the entries a quarantine recorded in its journal. An
+// entry whose move was interrupted may be listed without being in the
+// trash.
+func readJournal(path string) ([]TrashEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry TrashEntry
+		if len(line) > 0 && json.Unmarshal(line, &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Empty reports whether nothing has been quarantined yet
+func (q *Quarantine) Empty() bool {
+	return len(q.op.Entries) == 0
+}
+
+// Commit writes the operation's manifest, replacing its journal.
+// Operations that moved nothing leave no trace.
+func (q *Quarantine) Commit() error {
+	if q.journal != nil {
+		q.journal.Close()
+		q.journal = nil
+	}
+	if q.Empty() {
+		_ = os.Remove(filepath.Join(q.op.dir, journalName))
+		return nil
+	}
+	if err := q.op.writeManifest(); err != nil {
+		return err
+	}
+	_ = os.Remove(filepath.Join(q.op.dir, journalName))
+	return nil
+}
+
+// quarantineRemover is the Remover of a Quarantine for one kind
+type quarantineRemover struct {
+	q    *Quarantine
+	kind string
+}
+
+func (r quarantineRemover) Remove(path string) error {
+	return r.q.move(r.kind, path)
+}
+
+// RemoveAll quarantines path, which like os.RemoveAll may not exist
+func (r quarantineRemover) RemoveAll(path string) error {
+	if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return r.q.move(r.kind, path)
+}
+
+// Replace keeps a copy of path, which is about to be rewritten
+func (r quarantineRemover) Replace(path string) error {
+	return r.q.keep(r.kind, path)
+}
+
+// RestoreResult reports what undo put back
+type RestoreResult struct {
+	Restored int   `json:"restored"`
+	Size     int64 `json:"size"`
+	// Skipped lists entries left in the trash because the go command has
+	// recreated them since
+	Skipped []string `json:"skipped"`
+}
+
+// Restore moves the operation's entries back to where they were. Entries
+// whose path exists again are never overwritten: they stay in the trash
+// and are reported as skipped. Replaced entries are the exception, as
+// their file was only rewritten. Restored operations are kept, marked as
+// such, until the trash is emptied.
+func (op *TrashOperation) Restore(now time.Time) (*RestoreResult, error) {
+	if op.Incomplete {
+		return nil, fmt.Errorf("trash operation %s has no manifest or journal and cannot be restored", op.ID)
+	}
+
+	result := &RestoreResult{Skipped: []string{}}
+	var errs []error
+	for i := range op.Entries {
+		entry := &op.Entries[i]
+		if entry.Skipped {
+			continue
+		}
+		if _, err := os.Lstat(entry.Path); err == nil && !entry.Replaced {
+			entry.Skipped = true
+			result.Skipped = append(result.Skipped, entry.Path)
+			continue
+		}
+
+		rel, err := filepath.Rel(op.Root, entry.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		src := filepath.Join(op.dir, "files", rel)
+		if _, err := os.Lstat(src); op.Interrupted && errors.Is(err, fs.ErrNotExist) {
+			// Journaled, but the move never happened
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(entry.Path), 0o755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Rename(src, entry.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for rel, mode := range entry.Modes {
+			if err := os.Chmod(filepath.Join(entry.Path, filepath.FromSlash(rel)), mode); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		result.Restored++
+		result.Size += entry.Size
+	}
+
+	// Entries that failed stay unmarked, but the operation counts as
+	// restored so that the next undo moves on to the one before
+	op.Restored = now
+	if err := op.writeManifest(); err != nil {
+		errs = append(errs, err)
+	} else {
+		_ = os.Remove(filepath.Join(op.dir, journalName))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return result, fmt.Errorf("failed to restore trash operation %s: %w", op.ID, err)
+	}
+	return result, nil
+}
+
+// Purge deletes the operation and everything in it, returning the bytes
+// freed
+func (op *TrashOperation) Purge() (int64, error) {
+	var r remover
+	_, freed, err := r.removeTree(op.dir)
+	if err != nil {
+		return freed, fmt.Errorf("failed to purge trash operation %s: %w", op.ID, err)
+	}
+	return freed, nil
+}
+
+func (op *TrashOperation) writeManifest() error {
+	data, err := json.MarshalIndent(op, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trash manifest: %w", err)
+	}
+	if err := os.MkdirAll(op.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(op.dir, manifestName), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+	return nil
+}
+
+// isTrashDir reports whether d is a trash directory, which walks over a
+// cache skip
+func isTrashDir(d fs.DirEntry) bool {
+	return d.IsDir() && d.Name() == TrashDirName
+}
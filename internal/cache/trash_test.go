@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuarantine_RestoreAndPurge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"00/b1-d": "\x7fELFbuild",
+		"01/b2-a": "v1 action",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr, err := NewBuildManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	trash := NewTrash(tmpDir)
+	quarantine := trash.Quarantine(NewTrashID(now), now)
+	mgr.SetRemover(quarantine.For("build"))
+
+	deleted, _, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if err := quarantine.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 entries quarantined, got %d", deleted)
+	}
+
+	// Quarantined entries no longer count as cache entries
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if count := stats.(*BuildCacheStats).EntryCount; count != 0 {
+		t.Errorf("Expected an empty cache, got %d entries", count)
+	}
+
+	ops, err := trash.Operations()
+	if err != nil {
+		t.Fatalf("Operations failed: %v", err)
+	}
+	if len(ops) != 1 || len(ops[0].Entries) != 2 || ops[0].Entries[0].Kind != "build" {
+		t.Fatalf("Expected one operation with 2 build entries, got %+v", ops)
+	}
+
+	// The go command recreated one entry in the meantime
+	recreated := filepath.Join(tmpDir, "01", "b2-a")
+	if err := os.WriteFile(recreated, []byte("v1 newer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ops[0].Restore(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Restored != 1 || len(result.Skipped) != 1 || result.Skipped[0] != recreated {
+		t.Errorf("Expected 1 entry restored and the recreated one skipped, got %+v", result)
+	}
+	if data, _ := os.ReadFile(recreated); string(data) != "v1 newer" {
+		t.Errorf("Expected the recreated entry to be kept, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "00", "b1-d")); string(data) != files["00/b1-d"] {
+		t.Errorf("Expected the entry to be restored, got %q", data)
+	}
+
+	ops, _ = trash.Operations()
+	if len(ops) != 1 || ops[0].Restored.IsZero() || !ops[0].Entries[1].Skipped {
+		t.Fatalf("Expected the operation to be marked restored, got %+v", ops)
+	}
+	freed, err := ops[0].Purge()
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if freed < int64(len(files["01/b2-a"])) {
+		t.Errorf("Expected the skipped entry and the manifest to be purged, freed %d", freed)
+	}
+	if ops, _ := trash.Operations(); len(ops) != 0 {
+		t.Errorf("Expected an empty trash, got %+v", ops)
+	}
+}
+
+func TestTrash_IncompleteOperation(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, TrashDirName, "crashed", "files"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := NewTrash(tmpDir).Operations()
+	if err != nil {
+		t.Fatalf("Operations failed: %v", err)
+	}
+	if len(ops) != 1 || !ops[0].Incomplete {
+		t.Fatalf("Expected one incomplete operation, got %+v", ops)
+	}
+	if _, err := ops[0].Restore(time.Now()); err == nil {
+		t.Error("Expected an operation without manifest not to be restorable")
+	}
+}
+
+func TestTrash_InterruptedOperation(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "00", "b1-d")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("entry"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The clear moves an entry, then crashes before committing
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	trash := NewTrash(tmpDir)
+	quarantine := trash.Quarantine(NewTrashID(now), now)
+	if err := quarantine.For("build").Remove(path); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	ops, err := trash.Operations()
+	if err != nil {
+		t.Fatalf("Operations failed: %v", err)
+	}
+	if len(ops) != 1 || !ops[0].Interrupted || ops[0].Incomplete || len(ops[0].Entries) != 1 || !ops[0].Time.Equal(now) {
+		t.Fatalf("Expected one interrupted operation with its entry, got %+v", ops)
+	}
+	if !ops[0].InProgress(time.Now(), time.Hour) || ops[0].InProgress(time.Now().Add(2*time.Hour), time.Hour) {
+		t.Error("Expected the operation to count as running for an hour after it changed")
+	}
+
+	result, err := ops[0].Restore(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Restored != 1 {
+		t.Errorf("Expected the moved entry to be restored, got %+v", result)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the entry back in the cache: %v", err)
+	}
+}
+
+func TestQuarantine_ReadOnlyModuleTree(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	tmpDir := t.TempDir()
+	tree := filepath.Join(tmpDir, "example.com", "b@v1.0.0")
+	for name, content := range map[string]string{
+		"example.com/b@v1.0.0/go.mod":                "module example.com/b\n",
+		"example.com/b@v1.0.0/sub/b.go":              "package sub\n",
+		"cache/download/example.com/b/@v/v1.0.0.mod": "module example.com/b\n",
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0444); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The go command extracts module trees read-only
+	for _, dir := range []string{filepath.Join(tree, "sub"), tree} {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		_ = filepath.WalkDir(tmpDir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				_ = os.Chmod(path, 0755)
+			}
+			return nil
+		})
+	})
+
+	mgr, err := NewModManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewModManager failed: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	trash := NewTrash(tmpDir)
+	quarantine := trash.Quarantine(NewTrashID(now), now)
+	mgr.SetRemover(quarantine.For("mod"))
+
+	deleted, _, err := mgr.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if err := quarantine.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 files quarantined, got %d", deleted)
+	}
+	if _, err := os.Lstat(tree); !os.IsNotExist(err) {
+		t.Errorf("Expected the module tree to be quarantined, got %v", err)
+	}
+
+	// The tree is quarantined as one entry, not file by file
+	ops, err := trash.Operations()
+	if err != nil {
+		t.Fatalf("Operations failed: %v", err)
+	}
+	if len(ops) != 1 || len(ops[0].Entries) != 2 {
+		t.Fatalf("Expected one operation with the tree and the download, got %+v", ops)
+	}
+
+	result, err := ops[0].Restore(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Restored != 2 {
+		t.Errorf("Expected 2 entries restored, got %+v", result)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tree, "sub", "b.go")); string(data) != "package sub\n" {
+		t.Errorf("Expected the module tree to be restored, got %q", data)
+	}
+	// Restored trees are read-only again, as the go command extracted them
+	for _, dir := range []string{tree, filepath.Join(tree, "sub")} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0555 {
+			t.Errorf("Expected %s to be restored read-only, got %v", dir, info.Mode())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The go command's trim policy, as in cmd/go/internal/cache
+const (
+	// mtimeInterval is how stale an entry's mtime gets before the go
+	// command refreshes it on use
+	mtimeInterval = time.Hour
+	// trimInterval is how often the go command trims the cache
+	trimInterval = 24 * time.Hour
+	// trimLimit is how long an entry may go unused before a trim removes it
+	trimLimit = 5 * 24 * time.Hour
+)
+
+// lastTrim returns when the go command last trimmed the cache in dir, or
+// the zero time if trim.txt is missing or cannot be parsed
+func lastTrim(dir string) time.Time {
+	data, err := os.ReadFile(filepath.Join(dir, trimFile))
+	if err != nil {
+		return time.Time{}
+	}
+	t, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(t, 0)
+}
+
+// nextTrim returns when the first go command to run from now on trims the
+// cache last trimmed at last. Like the go command, it treats a last trim
+// too far in the future as corrupt.
+func nextTrim(last, now time.Time) time.Time {
+	if d := now.Sub(last); !last.IsZero() && d < trimInterval && d > -mtimeInterval {
+		return last.Add(trimInterval)
+	}
+	return now
+}
+
+// trimCutoff returns the mtime before which a trim at t removes entries,
+// allowing for the imprecision of mtimes refreshed only every mtimeInterval
+func trimCutoff(t time.Time) time.Time {
+	return t.Add(-trimLimit - mtimeInterval)
+}
+
+// isTrimmable reports whether a trim of the cache in dir considers path:
+// an action or output file in one of the 256 two-digit hex subdirectories
+func isTrimmable(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	sub, name, ok := strings.Cut(filepath.ToSlash(rel), "/")
+	if !ok || len(sub) != 2 || strings.Contains(name, "/") {
+		return false
+	}
+	for _, c := range sub {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return strings.HasSuffix(name, "-a") || strings.HasSuffix(name, "-d")
+}
+
+// writeTrimTime records a trim at t in trim.txt, in the go command's format
+func writeTrimTime(dir string, t time.Time) error {
+	path := filepath.Join(dir, trimFile)
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(t.Unix(), 10)), 0o666); err != nil {
+		return fmt.Errorf("failed to record trim: %w", err)
+	}
+	return nil
+}
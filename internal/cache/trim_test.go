@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNextTrim(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		last time.Time
+		want time.Time
+	}{
+		{"never trimmed", time.Time{}, now},
+		{"trimmed recently", now.Add(-2 * time.Hour), now.Add(22 * time.Hour)},
+		{"trim due", now.Add(-25 * time.Hour), now},
+		{"slightly in the future", now.Add(30 * time.Minute), now.Add(30*time.Minute + trimInterval)},
+		{"corrupt, far in the future", now.Add(2 * time.Hour), now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextTrim(tt.last, now); !got.Equal(tt.want) {
+				t.Errorf("nextTrim(%v) = %v, want %v", tt.last, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTrimmable(t *testing.T) {
+	dir := filepath.FromSlash("/cache")
+	tests := map[string]bool{
+		"0a/0123-a":      true,
+		"ff/0123-d":      true,
+		"0a/0123-x":      false, // not an action or output
+		"0A/0123-a":      false, // the go command writes lower-case hex
+		"zz/0123-a":      false,
+		"0a/sub/0123-a":  false,
+		"0123-a":         false,
+		"trim.txt":       false,
+		"README":         false,
+		"../elsewhere-d": false,
+	}
+	for rel, want := range tests {
+		if got := isTrimmable(dir, filepath.Join(dir, filepath.FromSlash(rel))); got != want {
+			t.Errorf("isTrimmable(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestBuildManager_Trim(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-trimLimit - mtimeInterval - time.Minute)
+	recent := now.Add(-trimLimit) // within the extra mtimeInterval
+
+	files := map[string]time.Time{
+		"0a/b1-a": old,
+		"0a/b2-d": old,
+		"0a/b3-d": recent,
+		"ff/b4-x": old, // not an entry the go command trims
+		"b5-d":    old,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastTrimmed := now.Add(-48 * time.Hour)
+	if err := writeTrimTime(tmpDir, lastTrimmed); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewBuildManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewBuildManager failed: %v", err)
+	}
+	mgr.users = func(string) ([]Process, error) { return nil, nil }
+
+	stats, err := mgr.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	s := stats.(*BuildCacheStats)
+	if s.LastTrim.Unix() != lastTrimmed.Unix() {
+		t.Errorf("Expected last trim %v, got %v", lastTrimmed, s.LastTrim)
+	}
+	if s.TrimmableEntries != 2 || s.TrimmableSize != 8 {
+		t.Errorf("Expected 2 trimmable entries of 8 bytes, got %d of %d", s.TrimmableEntries, s.TrimmableSize)
+	}
+
+	deleted, freed, err := mgr.Trim(now)
+	if err != nil {
+		t.Fatalf("Trim failed: %v", err)
+	}
+	if deleted != 2 || freed != 8 {
+		t.Errorf("Expected 2 entries trimmed, 8 bytes freed, got %d, %d", deleted, freed)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(tmpDir, filepath.FromSlash(name)))
+		if trimmed := name == "0a/b1-a" || name == "0a/b2-d"; trimmed != os.IsNotExist(err) {
+			t.Errorf("Expected %s trimmed: %v, got %v", name, trimmed, err)
+		}
+	}
+
+	// The go command reads the trim time back and skips its own trim
+	data, err := os.ReadFile(filepath.Join(tmpDir, trimFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != strconv.FormatInt(now.Unix(), 10) {
+		t.Errorf("Expected trim.txt to hold %d, got %q", now.Unix(), data)
+	}
+	if next := nextTrim(lastTrim(tmpDir), now); next.Unix() != now.Add(trimInterval).Unix() {
+		t.Errorf("Expected the next trim in a day, got %v", next)
+	}
+}
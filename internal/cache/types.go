@@ -1,6 +1,9 @@
 package cache
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // BuildCacheStats contains build cache statistics
 type BuildCacheStats struct {
@@ -10,6 +13,23 @@ type BuildCacheStats struct {
 	OldestEntry  time.Time        `json:"oldest_entry"`
 	NewestEntry  time.Time        `json:"newest_entry"`
 	Distribution SizeDistribution `json:"distribution"`
+	// LastTrim is when the go command last trimmed the cache, according to
+	// trim.txt; zero if it never did
+	LastTrim time.Time `json:"last_trim,omitzero"`
+	// NextTrim is when the go command trims the cache next. Trimmable
+	// counts what it removes then, test entries included, unless used in
+	// the meantime.
+	NextTrim         time.Time `json:"next_trim"`
+	TrimmableEntries int       `json:"trimmable_entries"`
+	TrimmableSize    int64     `json:"trimmable_size"`
+	// Ages counts entries by the time since they were last used
+	Ages AgeHistogram `json:"ages"`
+	// Toolchains groups entries by the Go version that produced them,
+	// newest first, when broken down by toolchain
+	Toolchains []EntryGroup `json:"toolchains,omitempty"`
+	// Platforms groups entries by their target GOOS/GOARCH, largest first,
+	// when broken down by platform
+	Platforms []EntryGroup `json:"platforms,omitempty"`
 }
 
 // ModCacheStats contains module cache statistics
@@ -20,6 +40,8 @@ type ModCacheStats struct {
 	DirectCount   int          `json:"direct_count"`
 	IndirectCount int          `json:"indirect_count"`
 	TopModules    []ModuleInfo `json:"top_modules,omitempty"`
+	// Ages counts module versions by the time since they were downloaded
+	Ages AgeHistogram `json:"ages"`
 }
 
 // TestCacheStats contains test cache statistics
@@ -29,11 +51,54 @@ type TestCacheStats struct {
 	EntryCount  int       `json:"entry_count"`
 	OldestEntry time.Time `json:"oldest_entry"`
 	NewestEntry time.Time `json:"newest_entry"`
+	// Ages counts entries by the time since they were last used
+	Ages AgeHistogram `json:"ages"`
 }
 
-func (s BuildCacheStats) Type() string { return "build" }
-func (s ModCacheStats) Type() string   { return "module" }
-func (s TestCacheStats) Type() string  { return "test" }
+// ToolchainCacheStats contains statistics about Go toolchains downloaded
+// into the module cache
+type ToolchainCacheStats struct {
+	Location       string          `json:"location"`
+	Size           int64           `json:"size"`
+	ToolchainCount int             `json:"toolchain_count"`
+	Toolchains     []ToolchainInfo `json:"toolchains,omitempty"`
+}
+
+// GoplsCacheStats contains gopls cache statistics
+type GoplsCacheStats struct {
+	Location    string    `json:"location"`
+	Size        int64     `json:"size"`
+	EntryCount  int       `json:"entry_count"`
+	OldestEntry time.Time `json:"oldest_entry"`
+	NewestEntry time.Time `json:"newest_entry"`
+}
+
+// LintCacheStats contains golangci-lint cache statistics
+type LintCacheStats struct {
+	Location    string    `json:"location"`
+	Size        int64     `json:"size"`
+	EntryCount  int       `json:"entry_count"`
+	OldestEntry time.Time `json:"oldest_entry"`
+	NewestEntry time.Time `json:"newest_entry"`
+}
+
+func (s BuildCacheStats) Type() string     { return "build" }
+func (s ModCacheStats) Type() string       { return "module" }
+func (s TestCacheStats) Type() string      { return "test" }
+func (s ToolchainCacheStats) Type() string { return "toolchain" }
+func (s GoplsCacheStats) Type() string     { return "gopls" }
+func (s LintCacheStats) Type() string      { return "lint" }
+
+// Entry is one removable item of a cache: a build or test cache file, or
+// an extracted module version
+type Entry struct {
+	Name    string    `json:"name"`              // path below the cache, or module@version
+	Module  string    `json:"module,omitempty"`  // module path of a module version
+	Version string    `json:"version,omitempty"` // version of a module version
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
 
 // SizeDistribution tracks distribution of cache entries by size
 type SizeDistribution struct {
@@ -53,30 +118,131 @@ type ModuleInfo struct {
 	Direct  bool   `json:"direct"`
 }
 
+// ToolchainInfo contains information about a downloaded toolchain
+type ToolchainInfo struct {
+	Version    string    `json:"version"`  // e.g. go1.22.0
+	Platform   string    `json:"platform"` // e.g. linux-amd64
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	LastNeeded time.Time `json:"last_needed"` // newest go.mod requiring it
+	UsedBy     []string  `json:"used_by,omitempty"`
+}
+
 // ClearOptions contains options for clearing cache
 type ClearOptions struct {
-	Build   bool
-	Modules bool
-	Test    bool
+	Targets []string // kinds to clear, e.g. "build"
+	Roots   []string // root labels to clear; empty clears every root
 	All     bool
-	Force   bool
-	DryRun  bool
+	// Force clears even while go commands use the cache
+	Force  bool
+	DryRun bool
+	// Quarantine moves entries into the trash of their cache root instead
+	// of deleting them
+	Quarantine bool
+	// Wait waits up to this long for go commands to stop using the cache
+	// instead of refusing to clear it
+	Wait time.Duration
+}
+
+// Concurrency returns how clearing coordinates with running go commands
+func (o ClearOptions) Concurrency() Concurrency {
+	return concurrencyFor(o.Force, o.Wait)
+}
+
+// PruneOptions selects the entries a prune removes
+type PruneOptions struct {
+	Targets []string // kinds to prune, e.g. "build"
+	Roots   []string // root labels to prune; empty prunes every root
+	// OlderThan removes entries last modified longer ago than this
+	OlderThan time.Duration
+	// ToolchainOlderThan removes the compiled objects of Go releases older
+	// than this version, e.g. "go1.24", with their action files
+	ToolchainOlderThan string
+	// Platforms removes the compiled objects and executables built for
+	// these targets, e.g. "windows/amd64" or "windows", with their action
+	// files
+	Platforms []string
+	// GoCompatible trims the build cache exactly as the go command does
+	// instead, and records the trim in trim.txt
+	GoCompatible bool
+	// DryRun only counts the entries that would be removed
+	DryRun bool
+	// Force prunes even while go commands use the cache
+	Force bool
+	// Wait waits up to this long for go commands to stop using the cache
+	// instead of refusing to prune it
+	Wait time.Duration
+}
+
+// Concurrency returns how pruning coordinates with running go commands
+func (o PruneOptions) Concurrency() Concurrency {
+	return concurrencyFor(o.Force, o.Wait)
+}
+
+// ObjectFilter returns the predicate selecting the compiled objects a prune
+// removes, or nil if it does not select entries by their producer. Objects
+// must match both the toolchain and the platforms, when given.
+func (o PruneOptions) ObjectFilter() func(ObjectInfo) bool {
+	if o.ToolchainOlderThan == "" && len(o.Platforms) == 0 {
+		return nil
+	}
+	return func(info ObjectInfo) bool {
+		if o.ToolchainOlderThan != "" && !info.OlderToolchain(o.ToolchainOlderThan) {
+			return false
+		}
+		return len(o.Platforms) == 0 || slices.ContainsFunc(o.Platforms, info.OnPlatform)
+	}
+}
+
+// concurrencyFor returns the concurrency of --force and --wait. An explicit
+// wait takes precedence over force.
+func concurrencyFor(force bool, wait time.Duration) Concurrency {
+	switch {
+	case wait > 0:
+		return Concurrency{Mode: Wait, Timeout: wait}
+	case force:
+		return Concurrency{Mode: Force}
+	}
+	return Concurrency{Mode: Refuse}
 }
 
 // ClearResult contains the result of a clear operation
 type ClearResult struct {
-	BuildDeleted   int   `json:"build_deleted"`
-	ModulesDeleted int   `json:"modules_deleted"`
-	TestDeleted    int   `json:"test_deleted"`
-	TotalFreed     int64 `json:"total_freed"`
-	Errors         int   `json:"errors"`
+	Caches     map[string]*CacheClearResult `json:"caches"` // keyed by kind
+	TotalFreed int64                        `json:"total_freed"`
+	Errors     int                          `json:"errors"`
+	Failures   []ClearFailure               `json:"failures"`
+	// TrashID identifies the trash operation holding quarantined entries
+	TrashID string `json:"trash_id,omitempty"`
+}
+
+// ClearFailure describes a path that could not be cleared
+type ClearFailure struct {
+	Kind  string `json:"kind"`
+	Root  string `json:"root"` // root label
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// CacheClearResult contains the result of clearing one kind of cache
+type CacheClearResult struct {
+	Deleted int   `json:"deleted"`
+	Freed   int64 `json:"freed"`
 }
 
 // CacheInfo contains information about cache locations
 type CacheInfo struct {
-	GOCACHE      string `json:"gocache"`
-	GOMODCACHE   string `json:"gomodcache"`
-	GoVersion    string `json:"go_version"`
-	BuildCacheOK bool   `json:"build_cache_ok"`
-	ModCacheOK   bool   `json:"mod_cache_ok"`
+	GoVersion string `json:"go_version"`
+	// Roots lists the directories of every kind of cache, in registration
+	// order, including optional caches that do not exist
+	Roots []RootInfo `json:"roots"`
+}
+
+// RootInfo describes a cache directory registered for a kind of cache
+type RootInfo struct {
+	Kind      string `json:"kind"`
+	Env       string `json:"env"` // variable locating it, e.g. GOCACHE
+	Label     string `json:"label"`
+	Path      string `json:"path"`
+	Available bool   `json:"available"`
 }
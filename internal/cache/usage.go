@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// UsageNode is a module path segment, or a module version, with the size
+// of everything below it
+type UsageNode struct {
+	Name     string       `json:"name"` // segment, e.g. "kubernetes", or version
+	Path     string       `json:"path"` // module path prefix, or path@version
+	Size     int64        `json:"size"`
+	Versions int          `json:"versions"` // module versions below
+	Children []*UsageNode `json:"children,omitempty"`
+	// Omitted counts the children left out by Prune, and OmittedSize their
+	// combined size
+	Omitted     int   `json:"omitted,omitempty"`
+	OmittedSize int64 `json:"omitted_size,omitempty"`
+
+	index map[string]*UsageNode
+}
+
+// IsVersion reports whether the node is a module version rather than a
+// path segment
+func (n *UsageNode) IsVersion() bool {
+	return strings.Contains(n.Path, "@")
+}
+
+// ModuleUsage builds the tree of module versions by path segment: host,
+// then org, then repository and so on, with versions as leaves. Entries
+// carry unescaped paths, so github.com/!burnt!sushi appears as
+// github.com/BurntSushi.
+func ModuleUsage(entries []Entry) *UsageNode {
+	root := &UsageNode{}
+	for _, entry := range entries {
+		if entry.Module == "" {
+			continue
+		}
+		module := entry.Module
+
+		node := root
+		node.add(entry.Size)
+		for _, segment := range strings.Split(module, "/") {
+			path := segment
+			if node.Path != "" {
+				path = node.Path + "/" + segment
+			}
+			node = node.child(segment, path)
+			node.add(entry.Size)
+		}
+		version := node.child(entry.Version, module+"@"+entry.Version)
+		version.add(entry.Size)
+	}
+	return root
+}
+
+func (n *UsageNode) add(size int64) {
+	n.Size += size
+	n.Versions++
+}
+
+// child returns the named child, creating it if needed
+func (n *UsageNode) child(name, path string) *UsageNode {
+	if n.index == nil {
+		n.index = make(map[string]*UsageNode)
+	}
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	c := &UsageNode{Name: name, Path: path}
+	n.index[name] = c
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Find returns the node for a module path prefix such as "k8s.io" or
+// "github.com/spf13", or nil if no module lies below it
+func (n *UsageNode) Find(prefix string) *UsageNode {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return n
+	}
+
+	node := n
+	for _, segment := range strings.Split(prefix, "/") {
+		node = node.index[segment]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Usage sort orders
+const (
+	SortBySize     = "size"
+	SortByName     = "name"
+	SortByVersions = "versions"
+)
+
+// Sort orders the children at every level: largest first, by name, or by
+// most versions first
+func (n *UsageNode) Sort(by string) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		switch by {
+		case SortByName:
+			return a.Name < b.Name
+		case SortByVersions:
+			if a.Versions != b.Versions {
+				return a.Versions > b.Versions
+			}
+		}
+		return a.Size > b.Size
+	})
+	for _, c := range n.Children {
+		c.Sort(by)
+	}
+}
+
+// Prune keeps depth levels below n and only the first top children of
+// every node, counting what it drops as omitted. Zero disables either
+// limit. Sort first to keep the largest children.
+func (n *UsageNode) Prune(depth, top int) {
+	if top > 0 && len(n.Children) > top {
+		n.omit(n.Children[top:])
+		n.Children = n.Children[:top]
+	}
+	for _, c := range n.Children {
+		if depth == 1 {
+			c.omit(c.Children)
+			c.Children = nil
+			continue
+		}
+		c.Prune(max(depth-1, 0), top)
+	}
+}
+
+func (n *UsageNode) omit(children []*UsageNode) {
+	for _, c := range children {
+		n.Omitted++
+		n.OmittedSize += c.Size
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import "testing"
+
+func TestModuleUsage(t *testing.T) {
+	entries := []Entry{
+		{Module: "k8s.io/api", Version: "v0.30.0", Size: 400},
+		{Module: "k8s.io/api", Version: "v0.31.0", Size: 500},
+		{Module: "k8s.io/client-go", Version: "v0.31.0", Size: 300},
+		{Module: "github.com/BurntSushi/toml", Version: "v1.4.0", Size: 50},
+		{Module: "github.com/spf13/cobra", Version: "v1.10.1", Size: 70},
+		{Module: "github.com/spf13/pflag", Version: "v1.0.10", Size: 20},
+	}
+
+	root := ModuleUsage(entries)
+	if root.Size != 1340 || root.Versions != 6 {
+		t.Errorf("Expected 1340 bytes in 6 versions, got %d in %d", root.Size, root.Versions)
+	}
+
+	k8s := root.Find("k8s.io")
+	if k8s == nil || k8s.Size != 1200 || k8s.Versions != 3 {
+		t.Fatalf("Expected k8s.io to hold 1200 bytes in 3 versions, got %+v", k8s)
+	}
+	api := root.Find("k8s.io/api/")
+	if api == nil || len(api.Children) != 2 || !api.Children[0].IsVersion() || api.Children[0].Path != "k8s.io/api@v0.30.0" {
+		t.Fatalf("Expected the versions of k8s.io/api, got %+v", api)
+	}
+	if root.Find("github.com/BurntSushi/toml") == nil {
+		t.Error("Expected the upper-case module path to be found")
+	}
+	if root.Find("example.com") != nil {
+		t.Error("Expected no node for an unknown prefix")
+	}
+
+	root.Sort(SortBySize)
+	if root.Children[0].Name != "k8s.io" || api.Children[0].Name != "v0.31.0" {
+		t.Errorf("Expected largest first, got %s and %s", root.Children[0].Name, api.Children[0].Name)
+	}
+
+	github := root.Find("github.com")
+	root.Prune(2, 1)
+	if len(root.Children) != 1 || root.Omitted != 1 || root.OmittedSize != 140 {
+		t.Errorf("Expected only k8s.io with github.com omitted, got %d children, %d omitted (%d bytes)",
+			len(root.Children), root.Omitted, root.OmittedSize)
+	}
+	if len(k8s.Children) != 1 || k8s.Omitted != 1 {
+		t.Errorf("Expected the top child of k8s.io only, got %+v", k8s.Children)
+	}
+	if k8s.Children[0].Children != nil || k8s.Children[0].Omitted != 2 {
+		t.Errorf("Expected versions beyond depth 2 to be omitted, got %+v", k8s.Children[0])
+	}
+	if github.Size != 140 {
+		t.Errorf("Expected github.com to keep its size, got %d", github.Size)
+	}
+}
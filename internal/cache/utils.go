@@ -2,8 +2,13 @@ package cache
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // GetGoEnv retrieves a Go environment variable
@@ -66,3 +71,378 @@ func addCommas(n int) string {
 	}
 	return result.String()
 }
+
+// remover removes paths through a Remover, deleting them when none is
+// set. Managers embed it to implement RemoverSetter.
+type remover struct {
+	r Remover
+}
+
+// SetRemover makes the manager remove through r, or delete when r is nil
+func (m *remover) SetRemover(r Remover) {
+	m.r = r
+}
+
+func (m *remover) remove(path string) error {
+	if m.r == nil {
+		return os.Remove(path)
+	}
+	return m.r.Remove(path)
+}
+
+func (m *remover) removeAll(path string) error {
+	if m.r == nil {
+		return os.RemoveAll(path)
+	}
+	return m.r.RemoveAll(path)
+}
+
+// replace lets a Remover keeping copies of rewritten files copy path, which
+// is about to be rewritten in place
+func (m *remover) replace(path string) error {
+	if r, ok := m.r.(Replacer); ok {
+		return r.Replace(path)
+	}
+	return nil
+}
+
+// removeTree removes a directory tree and reports how many files and bytes
+// it held. The module cache extracts modules read-only, so directories are
+// made writable before their contents are removed, unless the tree is only
+// moved by a Remover, which keeps their modes.
+func (m *remover) removeTree(root string) (int, int64, error) {
+	var count int
+	var size int64
+
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if m.r == nil {
+				_ = os.Chmod(path, info.Mode().Perm()|0o700)
+			}
+			return nil
+		}
+
+		count++
+		size += info.Size()
+		return nil
+	})
+
+	if err := m.removeAll(root); err != nil {
+		return count, size, err
+	}
+	return count, size, nil
+}
+
+// fileSummary aggregates the files below a directory
+type fileSummary struct {
+	count  int
+	size   int64
+	oldest time.Time
+	newest time.Time
+}
+
+// scanFiles summarizes every file below root
+func scanFiles(root string) (fileSummary, error) {
+	var summary fileSummary
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && isTrashDir(d) {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return nil // Skip errors and directories
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		summary.count++
+		summary.size += info.Size()
+
+		modTime := info.ModTime()
+		if summary.oldest.IsZero() || modTime.Before(summary.oldest) {
+			summary.oldest = modTime
+		}
+		if modTime.After(summary.newest) {
+			summary.newest = modTime
+		}
+		return nil
+	})
+
+	return summary, err
+}
+
+// clearFiles removes every file below root, leaving the directories in place
+func (m *remover) clearFiles(root string) (int, int64, error) {
+	var deletedCount int
+	var freedSpace int64
+	failures := &ClearError{}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && isTrashDir(d) {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if err := m.remove(path); err != nil {
+			failures.add(path, err)
+			return nil
+		}
+		deletedCount++
+		freedSpace += info.Size()
+		return nil
+	})
+
+	if err == nil {
+		err = failures.err()
+	}
+	return deletedCount, freedSpace, err
+}
+
+// listFiles returns an entry for every file below root that keep accepts
+func listFiles(root string, keep func(path string) bool) ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && isTrashDir(d) {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() || !keep(path) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		entries = append(entries, Entry{
+			Name:    filepath.ToSlash(rel),
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// removeFiles removes file entries below root
+func (m *remover) removeFiles(root string, entries []Entry) (int, int64, error) {
+	var deletedCount int
+	var freedSpace int64
+	failures := &ClearError{}
+
+	for _, entry := range entries {
+		if !withinDir(root, entry.Path) {
+			failures.add(entry.Path, fmt.Errorf("not inside %s", root))
+			continue
+		}
+		info, err := os.Lstat(entry.Path)
+		if err != nil {
+			failures.add(entry.Path, err)
+			continue
+		}
+		if err := m.remove(entry.Path); err != nil {
+			failures.add(entry.Path, err)
+			continue
+		}
+		deletedCount++
+		freedSpace += info.Size()
+	}
+
+	return deletedCount, freedSpace, failures.err()
+}
+
+// withinDir reports whether path lies strictly below dir
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !filepath.IsAbs(rel) &&
+		rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// dirSize returns the total size of all files below root
+func dirSize(root string) int64 {
+	var size int64
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// compareGoVersions compares two Go release names such as "go1.21.3",
+// "go1.22rc1" or "go1.20" and returns -1, 0 or +1. Like the go command, it
+// orders a language version before its prereleases and those before the
+// first release: go1.21 < go1.21rc1 < go1.21.0 < go1.21.1.
+func compareGoVersions(a, b string) int {
+	pa, pb := parseGoVersion(a), parseGoVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseGoVersion splits a Go release name into comparable parts:
+// major, minor, release kind (0 language, 1 beta, 2 rc, 3 release),
+// prerelease number and patch.
+func parseGoVersion(v string) [5]int {
+	var parts [5]int
+	v = strings.TrimPrefix(v, "go")
+
+	num := func() int {
+		i := 0
+		for i < len(v) && v[i] >= '0' && v[i] <= '9' {
+			i++
+		}
+		n, _ := strconv.Atoi(v[:i])
+		v = v[i:]
+		return n
+	}
+
+	parts[0] = num()
+	if !strings.HasPrefix(v, ".") {
+		return parts
+	}
+	v = v[1:]
+	parts[1] = num()
+
+	switch {
+	case strings.HasPrefix(v, "beta"):
+		v = v[len("beta"):]
+		parts[2], parts[3] = 1, num()
+	case strings.HasPrefix(v, "rc"):
+		v = v[len("rc"):]
+		parts[2], parts[3] = 2, num()
+	case strings.HasPrefix(v, "."):
+		v = v[1:]
+		parts[2], parts[4] = 3, num()
+	case v == "" && parts[0] == 1 && parts[1] < 21:
+		// Before Go 1.21 the first release had no ".0" suffix
+		parts[2] = 3
+	}
+	return parts
+}
+
+// ParseBytes parses a human-readable size such as "15GB", "1.5 GiB",
+// "512M" or "4096". Units are powers of 1024 to match FormatBytes.
+func ParseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	unit := strings.ToUpper(strings.TrimSpace(s[i:]))
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "IB"), "B")
+	exp := 0
+	if unit != "" {
+		exp = strings.Index("KMGTPE", unit) + 1
+		if exp == 0 || len(unit) != 1 {
+			return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, s[i:])
+		}
+	}
+
+	for ; exp > 0; exp-- {
+		value *= 1024
+	}
+	return int64(value), nil
+}
+
+// ParseAge parses an age such as "30d", "2w", "1d12h" or any
+// time.ParseDuration string. It adds days (d) and weeks (w) to the units
+// time.ParseDuration knows.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		j := i
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') && rest[j] != '.' {
+			j++
+		}
+
+		value, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+
+		var unit time.Duration
+		switch rest[i:j] {
+		case "w":
+			unit = 7 * 24 * time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		default:
+			d, err := time.ParseDuration("1" + rest[i:j])
+			if err != nil {
+				return 0, fmt.Errorf("invalid age %q: unknown unit %q", s, rest[i:j])
+			}
+			unit = d
+		}
+
+		total += time.Duration(value * float64(unit))
+		rest = rest[j:]
+	}
+
+	if s == "" {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return total, nil
+}
+
+// FormatAge formats a duration in the largest whole days or hours, as
+// accepted by ParseAge
+func FormatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return d.String()
+}
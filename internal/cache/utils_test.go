@@ -2,6 +2,7 @@ package cache
 
 import (
 	"testing"
+	"time"
 )
 
 func TestFormatBytes(t *testing.T) {
@@ -46,3 +47,76 @@ func TestFormatCount(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareGoVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"go1.22.0", "go1.22.0", 0},
+		{"go1.21.0", "go1.22.0", -1},
+		{"go1.22.10", "go1.22.9", 1},
+		{"go1.21", "go1.21rc1", -1},
+		{"go1.21rc2", "go1.21.0", -1},
+		{"go1.21beta1", "go1.21rc1", -1},
+		{"go1.20", "go1.20.0", 0},
+		{"go1.9", "go1.20", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareGoVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareGoVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"4096", 4096, false},
+		{"512B", 512, false},
+		{"15GB", 15 * 1024 * 1024 * 1024, false},
+		{"1.5 GiB", 1536 * 1024 * 1024, false},
+		{"5M", 5 * 1024 * 1024, false},
+		{"10XB", 0, true},
+		{"GB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseBytes(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseBytes(%q) = %d, %v, want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"3y", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAge(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseAge(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
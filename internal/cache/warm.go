@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Warmer fills the build and module caches the way a build would, so that
+// later builds of the same packages hit
+type Warmer struct {
+	// Dir is the directory the go command runs in, usually the module root
+	Dir string
+	// Proxy is a directory in GOPROXY layout to download modules from
+	// instead of the network, e.g. another machine's module cache
+	// cache/download; empty uses the configured GOPROXY
+	Proxy string
+	// Output receives what the go command prints
+	Output io.Writer
+
+	build  *BuildManager
+	modDir string
+}
+
+// WarmResult is what one step of warming added to a cache
+type WarmResult struct {
+	NewEntries int   `json:"new_entries"`
+	BytesAdded int64 `json:"bytes_added"`
+}
+
+// NewWarmer creates a warmer of the GOCACHE and GOMODCACHE the go command
+// uses in dir, creating the build cache if a fresh machine has none yet
+func NewWarmer(dir, proxy string, output io.Writer) (*Warmer, error) {
+	cacheDir, err := GetGoEnv("GOCACHE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GOCACHE: %w", err)
+	}
+	if err := os.MkdirAll(cacheDir, 0o777); err != nil {
+		return nil, fmt.Errorf("failed to create build cache: %w", err)
+	}
+	build, err := NewBuildManager(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	modDir, err := GetGoEnv("GOMODCACHE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GOMODCACHE: %w", err)
+	}
+	return &Warmer{Dir: dir, Proxy: proxy, Output: output, build: build, modDir: modDir}, nil
+}
+
+// Caches returns the build and module cache directories being warmed
+func (w *Warmer) Caches() (gocache, gomodcache string) {
+	return w.build.GetLocation(), w.modDir
+}
+
+// env returns the environment of the go commands run for platform, given
+// as GOOS/GOARCH or empty for the host
+func (w *Warmer) env(platform string) ([]string, error) {
+	env := os.Environ()
+	if w.Proxy != "" {
+		dir, err := filepath.Abs(w.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve proxy directory: %w", err)
+		}
+		// The checksum database cannot be reached offline; go.sum still
+		// verifies every module
+		path := filepath.ToSlash(dir)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path // C:/proxy on Windows
+		}
+		env = append(env, "GOPROXY=file://"+path, "GOSUMDB=off")
+	}
+	if platform != "" {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		env = append(env, "GOOS="+goos, "GOARCH="+goarch)
+	}
+	return env, nil
+}
+
+// run runs the go command with args for platform
+func (w *Warmer) run(platform string, args ...string) error {
+	env, err := w.env(platform)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = w.Dir
+	cmd.Env = env
+	cmd.Stdout = w.Output
+	cmd.Stderr = w.Output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run 'go %s': %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// DownloadModules downloads every module the main module needs and returns
+// how many files the module cache grew by
+func (w *Warmer) DownloadModules() (WarmResult, error) {
+	// A fresh machine has no module cache to scan yet
+	before, _ := scanFiles(w.modDir)
+	downloadErr := w.run("", "mod", "download")
+	after, _ := scanFiles(w.modDir)
+	return WarmResult{
+		NewEntries: max(after.count-before.count, 0),
+		BytesAdded: max(after.size-before.size, 0),
+	}, downloadErr
+}
+
+// Compile compiles the packages matching patterns for platform, and with
+// tests their test binaries too, without running them, and returns what
+// was added to the build cache. Binaries are discarded; only the cache
+// keeps what was compiled.
+func (w *Warmer) Compile(platform string, patterns []string, tests bool) (WarmResult, error) {
+	before, err := w.build.Snapshot()
+	if err != nil {
+		return WarmResult{}, fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+	compileErr := w.compile(platform, patterns, tests)
+	after, err := w.build.Snapshot()
+	if err != nil {
+		return WarmResult{}, fmt.Errorf("failed to snapshot build cache: %w", err)
+	}
+
+	var result WarmResult
+	result.NewEntries, result.BytesAdded = growth(before, after)
+	return result, compileErr
+}
+
+func (w *Warmer) compile(platform string, patterns []string, tests bool) error {
+	if err := w.run(platform, append([]string{"build"}, patterns...)...); err != nil {
+		return err
+	}
+	if !tests {
+		return nil
+	}
+
+	// go test -c writes one binary per package into a directory given
+	// with a trailing separator
+	out, err := os.MkdirTemp("", "gocachectl-warm-")
+	if err != nil {
+		return fmt.Errorf("failed to create directory for test binaries: %w", err)
+	}
+	defer os.RemoveAll(out)
+	return w.run(platform, append([]string{"test", "-c", "-o", out + string(filepath.Separator)}, patterns...)...)
+}
+
+// ExportArchive writes the directories in dirs to a gzipped tar archive at
+// path, each under the name it is keyed by, e.g. "gocache", and returns the
+// size of the archive. Missing directories, quarantined entries and the
+// lock file are left out.
+func ExportArchive(path string, dirs map[string]string) (int64, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve archive path: %w", err)
+	}
+	for _, dir := range dirs {
+		if withinDir(dir, abs) {
+			return 0, fmt.Errorf("cannot write archive %s into %s, which it archives", path, dir)
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range slices.Sorted(maps.Keys(dirs)) {
+		dir := dirs[name]
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			continue // e.g. no module was needed
+		}
+		if err := addToArchive(tw, name, dir); err != nil {
+			return 0, fmt.Errorf("failed to archive %s: %w", dir, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// addToArchive adds the files below dir to tw under name
+func addToArchive(tw *tar.Writer, name, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if isTrashDir(d) {
+			return filepath.SkipDir
+		}
+		if isCacheMetadata(dir, path) && filepath.Base(path) == trimFile {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(name, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+}
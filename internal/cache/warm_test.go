@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWarmer_Env(t *testing.T) {
+	proxy := t.TempDir()
+	w := &Warmer{Proxy: proxy}
+
+	env, err := w.env("linux/arm64")
+	if err != nil {
+		t.Fatalf("env failed: %v", err)
+	}
+	for _, want := range []string{"GOPROXY=file://" + filepath.ToSlash(proxy), "GOSUMDB=off", "GOOS=linux", "GOARCH=arm64"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Expected %s in the environment", want)
+		}
+	}
+
+	env, err = (&Warmer{}).env("")
+	if err != nil {
+		t.Fatalf("env failed: %v", err)
+	}
+	if slices.ContainsFunc(env[len(os.Environ()):], func(v string) bool { return strings.HasPrefix(v, "GO") }) {
+		t.Errorf("Expected the host environment unchanged, got %v", env[len(os.Environ()):])
+	}
+}
+
+func TestExportArchive(t *testing.T) {
+	gocache := t.TempDir()
+	for name, content := range map[string]string{
+		"ab/ab01-a":                   "v1 ab01 cd01 3 1700000000\n",
+		"cd/cd01-d":                   "obj",
+		trimFile:                      "1700000000",
+		TrashDirName + "/1/ef/ef01-d": "quarantined",
+	} {
+		path := filepath.Join(gocache, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive := filepath.Join(t.TempDir(), "cache.tar.gz")
+	size, err := ExportArchive(archive, map[string]string{
+		"gocache":    gocache,
+		"gomodcache": filepath.Join(t.TempDir(), "missing"),
+	})
+	if err != nil {
+		t.Fatalf("ExportArchive failed: %v", err)
+	}
+	if size == 0 {
+		t.Error("Expected the archive size")
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			files = append(files, header.Name)
+		}
+	}
+	if want := []string{"gocache/ab/ab01-a", "gocache/cd/cd01-d"}; !slices.Equal(files, want) {
+		t.Errorf("Expected files %v, got %v", want, files)
+	}
+
+	if _, err := ExportArchive(filepath.Join(gocache, "self.tar.gz"), map[string]string{"gocache": gocache}); err == nil {
+		t.Error("Expected an archive inside an archived cache to be refused")
+	}
+}
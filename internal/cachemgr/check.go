@@ -0,0 +1,69 @@
+package cachemgr
+
+import "time"
+
+// Limits are the thresholds Check compares caches against; zero disables
+// a limit
+type Limits struct {
+	// MaxTotal caps the size of all caches together
+	MaxTotal int64
+	// MaxBuild caps the size of each build cache root
+	MaxBuild int64
+	// MaxAge caps the age of the oldest entry in each cache with entry times
+	MaxAge time.Duration
+	// MaxModules caps the module count of each module cache root
+	MaxModules int
+}
+
+// Units of CheckResult values
+const (
+	UnitBytes   = "bytes"
+	UnitSeconds = "seconds"
+	UnitModules = "modules"
+)
+
+// CheckResult compares one measured value against its limit
+type CheckResult struct {
+	Limit    string `json:"limit"`          // e.g. "max_total"
+	Kind     string `json:"kind,omitempty"` // empty for limits across caches
+	Root     string `json:"root,omitempty"`
+	Unit     string `json:"unit"`
+	Max      int64  `json:"max"`
+	Actual   int64  `json:"actual"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+// Check compares stats against the enabled limits, returning one result
+// per limit and cache it applies to. Ages are measured from now.
+func Check(stats []RootStats, limits Limits, now time.Time) []CheckResult {
+	var results []CheckResult
+	add := func(result CheckResult) {
+		result.Exceeded = result.Actual > result.Max
+		results = append(results, result)
+	}
+
+	if limits.MaxTotal > 0 {
+		var total int64
+		for _, s := range stats {
+			total += s.Kind.Summary(s.Stats).Size
+		}
+		add(CheckResult{Limit: "max_total", Unit: UnitBytes, Max: limits.MaxTotal, Actual: total})
+	}
+
+	for _, s := range stats {
+		summary := s.Kind.Summary(s.Stats)
+		switch {
+		case limits.MaxBuild > 0 && s.Kind.Name == "build":
+			add(CheckResult{Limit: "max_build", Kind: s.Kind.Name, Root: s.Root.Label, Unit: UnitBytes, Max: limits.MaxBuild, Actual: summary.Size})
+		case limits.MaxModules > 0 && s.Kind.Name == "module":
+			add(CheckResult{Limit: "max_modules", Kind: s.Kind.Name, Root: s.Root.Label, Unit: UnitModules, Max: int64(limits.MaxModules), Actual: int64(summary.Count)})
+		}
+
+		if limits.MaxAge > 0 && !summary.Oldest.IsZero() {
+			age := now.Sub(summary.Oldest)
+			add(CheckResult{Limit: "max_age", Kind: s.Kind.Name, Root: s.Root.Label, Unit: UnitSeconds, Max: int64(limits.MaxAge.Seconds()), Actual: int64(age.Seconds())})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,74 @@
+package cachemgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	stats := []RootStats{
+		{
+			Kind:  Lookup("build"),
+			Root:  Root{Label: "ci", Path: "/srv/cache/ci"},
+			Stats: &cache.BuildCacheStats{Size: 12 << 30, EntryCount: 10, OldestEntry: now.Add(-40 * 24 * time.Hour)},
+		},
+		{
+			Kind:  Lookup("test"),
+			Root:  Root{Label: "ci", Path: "/srv/cache/ci"},
+			Stats: &cache.TestCacheStats{Size: 1 << 30, EntryCount: 3, OldestEntry: now.Add(-24 * time.Hour)},
+		},
+		{
+			Kind:  Lookup("module"),
+			Root:  Root{Label: DefaultLabel, Path: "/go/pkg/mod"},
+			Stats: &cache.ModCacheStats{Size: 2 << 30, ModuleCount: 1500},
+		},
+	}
+
+	results := Check(stats, Limits{
+		MaxTotal:   15 << 30,
+		MaxBuild:   10 << 30,
+		MaxAge:     30 * 24 * time.Hour,
+		MaxModules: 2000,
+	}, now)
+
+	exceeded := make(map[string]bool)
+	for _, result := range results {
+		exceeded[result.Limit+"/"+result.Kind] = result.Exceeded
+	}
+
+	want := map[string]bool{
+		"max_total/":         false, // 15GB exactly is within the limit
+		"max_build/build":    true,
+		"max_age/build":      true,
+		"max_age/test":       false,
+		"max_modules/module": false,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(want), len(results), results)
+	}
+	for key, w := range want {
+		got, ok := exceeded[key]
+		if !ok {
+			t.Errorf("Missing result %s", key)
+			continue
+		}
+		if got != w {
+			t.Errorf("Expected %s exceeded=%v, got %v", key, w, got)
+		}
+	}
+}
+
+func TestCheck_Disabled(t *testing.T) {
+	stats := []RootStats{{
+		Kind:  Lookup("build"),
+		Root:  Root{Label: DefaultLabel},
+		Stats: &cache.BuildCacheStats{Size: 1 << 40},
+	}}
+
+	if results := Check(stats, Limits{}, time.Now()); len(results) != 0 {
+		t.Errorf("Expected no results without limits, got %+v", results)
+	}
+}
@@ -0,0 +1,237 @@
+package cachemgr
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+// Built-in cache kinds
+func init() {
+	Register(&Kind{
+		Name:        "build",
+		Flag:        "build",
+		Title:       "Build Cache",
+		Description: "build cache",
+		Unit:        "entries",
+		Env:         "GOCACHE",
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewBuildManager(dir) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.BuildCacheStats)
+			return Summary{Size: s.Size, Count: s.EntryCount, Oldest: s.OldestEntry}
+		},
+		Render: renderBuildStats,
+		Schema: SchemaOf(cache.BuildCacheStats{}),
+	})
+
+	Register(&Kind{
+		Name:        "module",
+		Flag:        "modules",
+		Title:       "Module Cache",
+		Description: "module cache",
+		Unit:        "modules",
+		Env:         "GOMODCACHE",
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewModManager(dir) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.ModCacheStats)
+			return Summary{Size: s.Size, Count: s.ModuleCount}
+		},
+		Render: renderModuleStats,
+		Schema: SchemaOf(cache.ModCacheStats{}),
+	})
+
+	Register(&Kind{
+		Name:        "test",
+		Flag:        "test",
+		Title:       "Test Cache",
+		Description: "test cache",
+		Unit:        "entries",
+		Env:         "GOCACHE",
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewTestManager(dir) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.TestCacheStats)
+			return Summary{Size: s.Size, Count: s.EntryCount, Oldest: s.OldestEntry}
+		},
+		Render: renderTestStats,
+		Schema: SchemaOf(cache.TestCacheStats{}),
+	})
+
+	Register(&Kind{
+		Name:        "toolchain",
+		Flag:        "toolchains",
+		Title:       "Toolchains",
+		Description: "downloaded Go toolchains",
+		Unit:        "toolchains",
+		Env:         "GOMODCACHE",
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewToolchainManager(dir, nil) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.ToolchainCacheStats)
+			return Summary{Size: s.Size, Count: s.ToolchainCount}
+		},
+		Render: renderToolchainStats,
+		Schema: SchemaOf(cache.ToolchainCacheStats{}),
+	})
+
+	// Tool caches are optional: they only exist where the tool has run
+	Register(&Kind{
+		Name:        "gopls",
+		Flag:        "gopls",
+		Title:       "gopls Cache",
+		Description: "gopls cache",
+		Unit:        "entries",
+		Env:         "GOPLSCACHE",
+		Optional:    true,
+		Locate:      cache.GoplsCacheDir,
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewGoplsManager(dir) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.GoplsCacheStats)
+			return Summary{Size: s.Size, Count: s.EntryCount, Oldest: s.OldestEntry}
+		},
+		Render: func(w io.Writer, stats cache.Stats, opts RenderOptions) {
+			s := stats.(*cache.GoplsCacheStats)
+			renderEntryStats(w, opts, s.Location, s.Size, s.EntryCount, s.OldestEntry, s.NewestEntry)
+		},
+		Schema: SchemaOf(cache.GoplsCacheStats{}),
+	})
+
+	Register(&Kind{
+		Name:        "lint",
+		Flag:        "lint",
+		Title:       "golangci-lint Cache",
+		Description: "golangci-lint cache",
+		Unit:        "entries",
+		Env:         "GOLANGCI_LINT_CACHE",
+		Optional:    true,
+		Locate:      cache.LintCacheDir,
+		New:         func(dir string) (cache.CacheManager, error) { return cache.NewLintManager(dir) },
+		Summary: func(stats cache.Stats) Summary {
+			s := stats.(*cache.LintCacheStats)
+			return Summary{Size: s.Size, Count: s.EntryCount, Oldest: s.OldestEntry}
+		},
+		Render: func(w io.Writer, stats cache.Stats, opts RenderOptions) {
+			s := stats.(*cache.LintCacheStats)
+			renderEntryStats(w, opts, s.Location, s.Size, s.EntryCount, s.OldestEntry, s.NewestEntry)
+		},
+		Schema: SchemaOf(cache.LintCacheStats{}),
+	})
+}
+
+// writeField writes one aligned "Label: value" line
+func writeField(w io.Writer, opts RenderOptions, label, value string) {
+	fmt.Fprintf(w, "%s%-14s%s\n", opts.Indent, label+":", value)
+}
+
+// renderEntryStats writes the fields shared by caches made of plain entries
+func renderEntryStats(w io.Writer, opts RenderOptions, location string, size int64, count int, oldest, newest time.Time) {
+	writeField(w, opts, "Location", location)
+	writeField(w, opts, "Size", cache.FormatBytes(size))
+	writeField(w, opts, "Entries", cache.FormatCount(count))
+	if !oldest.IsZero() {
+		writeField(w, opts, "Oldest Entry", oldest.Format("2006-01-02 15:04:05"))
+		writeField(w, opts, "Newest Entry", newest.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// renderAges writes the age histogram of a cache, split by size when
+// verbose
+func renderAges(w io.Writer, opts RenderOptions, ages cache.AgeHistogram, unit string) {
+	if len(ages) == 0 {
+		return
+	}
+
+	if !opts.Verbose {
+		fmt.Fprintf(w, "%sAge Distribution:\n", opts.Indent)
+		for _, bucket := range ages {
+			fmt.Fprintf(w, "%s   %-8s %8s %s (%s)\n", opts.Indent, bucket.Label,
+				cache.FormatCount(bucket.Count), unit, cache.FormatBytes(bucket.Size))
+		}
+		return
+	}
+
+	cell := func(count int, size int64) string {
+		return fmt.Sprintf("%s (%s)", cache.FormatCount(count), cache.FormatBytes(size))
+	}
+	fmt.Fprintf(w, "%sAge x Size (%s):\n", opts.Indent, unit)
+	fmt.Fprintf(w, "%s   %-8s %-20s %-20s %-20s %s\n", opts.Indent, "", "<1MB", "1-10MB", ">10MB", "Total")
+	for _, bucket := range ages {
+		d := bucket.BySize
+		fmt.Fprintf(w, "%s   %-8s %-20s %-20s %-20s %s\n", opts.Indent, bucket.Label,
+			cell(d.Small, d.SmallSize), cell(d.Medium, d.MediumSize), cell(d.Large, d.LargeSize),
+			cell(bucket.Count, bucket.Size))
+	}
+}
+
+// renderGroups writes a breakdown of the build cache, if requested
+func renderGroups(w io.Writer, opts RenderOptions, title string, groups []cache.EntryGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s%s:\n", opts.Indent, title)
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s   %-14s %8s entries (%s)\n", opts.Indent, group.Name,
+			cache.FormatCount(group.Entries), cache.FormatBytes(group.Size))
+	}
+}
+
+func renderBuildStats(w io.Writer, stats cache.Stats, opts RenderOptions) {
+	s := stats.(*cache.BuildCacheStats)
+	renderEntryStats(w, opts, s.Location, s.Size, s.EntryCount, s.OldestEntry, s.NewestEntry)
+
+	lastTrim := "never"
+	if !s.LastTrim.IsZero() {
+		lastTrim = s.LastTrim.Format("2006-01-02 15:04:05")
+	}
+	writeField(w, opts, "Last Go Trim", lastTrim)
+	writeField(w, opts, "Next Go Trim", fmt.Sprintf("%s (would remove %s entries, %s)",
+		s.NextTrim.Format("2006-01-02 15:04:05"), cache.FormatCount(s.TrimmableEntries),
+		cache.FormatBytes(s.TrimmableSize)))
+	renderAges(w, opts, s.Ages, "entries")
+	renderGroups(w, opts, "By Toolchain", s.Toolchains)
+	renderGroups(w, opts, "By Platform", s.Platforms)
+
+	if opts.Verbose {
+		fmt.Fprintf(w, "%sSize Distribution:\n", opts.Indent)
+		fmt.Fprintf(w, "%s   Small (<1MB):    %d entries (%s)\n", opts.Indent,
+			s.Distribution.Small, cache.FormatBytes(s.Distribution.SmallSize))
+		fmt.Fprintf(w, "%s   Medium (1-10MB): %d entries (%s)\n", opts.Indent,
+			s.Distribution.Medium, cache.FormatBytes(s.Distribution.MediumSize))
+		fmt.Fprintf(w, "%s   Large (>10MB):   %d entries (%s)\n", opts.Indent,
+			s.Distribution.Large, cache.FormatBytes(s.Distribution.LargeSize))
+	}
+}
+
+func renderModuleStats(w io.Writer, stats cache.Stats, opts RenderOptions) {
+	s := stats.(*cache.ModCacheStats)
+	writeField(w, opts, "Location", s.Location)
+	writeField(w, opts, "Size", cache.FormatBytes(s.Size))
+	writeField(w, opts, "Modules", cache.FormatCount(s.ModuleCount))
+	renderAges(w, opts, s.Ages, "modules")
+
+	if opts.Verbose && len(s.TopModules) > 0 {
+		fmt.Fprintf(w, "%sTop Modules by Size:\n", opts.Indent)
+		for i, mod := range s.TopModules {
+			fmt.Fprintf(w, "%s   %d. %s (%s)\n", opts.Indent, i+1, mod.Path, cache.FormatBytes(mod.Size))
+		}
+	}
+}
+
+func renderTestStats(w io.Writer, stats cache.Stats, opts RenderOptions) {
+	s := stats.(*cache.TestCacheStats)
+	renderEntryStats(w, opts, s.Location, s.Size, s.EntryCount, s.OldestEntry, s.NewestEntry)
+	renderAges(w, opts, s.Ages, "entries")
+}
+
+func renderToolchainStats(w io.Writer, stats cache.Stats, opts RenderOptions) {
+	s := stats.(*cache.ToolchainCacheStats)
+	writeField(w, opts, "Location", s.Location)
+	writeField(w, opts, "Size", cache.FormatBytes(s.Size))
+	writeField(w, opts, "Toolchains", cache.FormatCount(s.ToolchainCount))
+
+	if opts.Verbose {
+		for _, tc := range s.Toolchains {
+			fmt.Fprintf(w, "%s   %-12s %-14s %10s\n", opts.Indent, tc.Version, tc.Platform, cache.FormatBytes(tc.Size))
+		}
+	}
+}
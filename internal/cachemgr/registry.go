@@ -0,0 +1,115 @@
+package cachemgr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+// Kind describes a type of cache. The CLI generates its stats and clear
+// flags, report sections and per-cache clear results from the registered
+// kinds, so adding a cache type only takes a call to Register.
+type Kind struct {
+	// Name matches the Type of the kind's stats, e.g. "build"
+	Name string
+	// Flag is the stats and clear flag selecting the kind, e.g. "modules"
+	Flag string
+	// Title heads the kind's report section, e.g. "Build Cache"
+	Title string
+	// Description completes flag help texts, e.g. "build cache"
+	Description string
+	// Unit names what Summary counts, e.g. "entries"
+	Unit string
+	// Env names the variable locating the kind's cache, e.g. "GOCACHE".
+	// Kinds sharing a variable share its configured roots.
+	Env string
+	// Optional kinds are skipped when their default cache directory does
+	// not exist
+	Optional bool
+	// Locate returns the default cache directory of an optional kind, so
+	// that info can show where it would be when it does not exist
+	Locate func() (string, error)
+
+	// New creates the kind's manager for a cache directory, or for the
+	// default location when dir is empty
+	New func(dir string) (cache.CacheManager, error)
+	// Summary extracts the size and item count of the kind's stats
+	Summary func(stats cache.Stats) Summary
+	// Render writes the kind's stats as human-readable lines
+	Render func(w io.Writer, stats cache.Stats, opts RenderOptions)
+	// Schema is the JSON Schema of the kind's stats document
+	Schema map[string]any
+}
+
+// Root is a labelled cache directory. Several roots may be configured for
+// the same variable, e.g. one GOCACHE per toolchain or CI executor.
+type Root struct {
+	Label string `json:"label" mapstructure:"label"`
+	Path  string `json:"path" mapstructure:"path"`
+}
+
+// DefaultLabel labels the root the go command reports when none is configured
+const DefaultLabel = "default"
+
+// ParseRoot parses a root given as "label=path" or just "path", in which
+// case the path doubles as the label
+func ParseRoot(s string) (Root, error) {
+	label, path, ok := strings.Cut(s, "=")
+	if !ok {
+		label, path = s, s
+	}
+	if label == "" || path == "" {
+		return Root{}, fmt.Errorf("invalid cache root %q: want label=path or path", s)
+	}
+	return Root{Label: label, Path: path}, nil
+}
+
+// Summary is the size and item count of one cache
+type Summary struct {
+	Size  int64
+	Count int
+	// Oldest is the modification time of the oldest entry, zero for
+	// caches without entry times
+	Oldest time.Time
+}
+
+// RenderOptions controls human-readable rendering
+type RenderOptions struct {
+	Indent  string
+	Verbose bool
+}
+
+var registry []*Kind
+
+// Register adds a cache kind. Kinds are reported in registration order.
+// Register panics if the name or flag is already taken, so conflicts
+// surface at startup rather than as silently shadowed flags.
+func Register(k *Kind) {
+	for _, existing := range registry {
+		if existing.Name == k.Name {
+			panic(fmt.Sprintf("cachemgr: kind %q registered twice", k.Name))
+		}
+		if existing.Flag == k.Flag {
+			panic(fmt.Sprintf("cachemgr: flag --%s of kind %q already used by %q", k.Flag, k.Name, existing.Name))
+		}
+	}
+	registry = append(registry, k)
+}
+
+// Kinds returns all registered kinds in registration order
+func Kinds() []*Kind {
+	return registry
+}
+
+// Lookup returns the kind with the given name, or nil
+func Lookup(name string) *Kind {
+	for _, k := range registry {
+		if k.Name == name {
+			return k
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cachemgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Register to panic on a duplicate flag")
+		}
+	}()
+
+	Register(&Kind{Name: "custom", Flag: "build"})
+}
+
+func TestSchemaOf(t *testing.T) {
+	type entry struct {
+		Path    string    `json:"path"`
+		Size    int64     `json:"size"`
+		Touched time.Time `json:"touched"`
+		Tags    []string  `json:"tags,omitempty"`
+		skipped bool
+	}
+
+	schema := SchemaOf(entry{})
+	properties := schema["properties"].(map[string]any)
+
+	if len(properties) != 4 {
+		t.Fatalf("Expected 4 properties, got %d", len(properties))
+	}
+	if got := properties["size"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("Expected size to be an integer, got %v", got)
+	}
+	if got := properties["touched"].(map[string]any)["format"]; got != "date-time" {
+		t.Errorf("Expected touched to be a date-time, got %v", got)
+	}
+	if required := schema["required"].([]string); len(required) != 3 {
+		t.Errorf("Expected 3 required properties, got %v", required)
+	}
+}
+
+func TestSchemaOf_OmitZero(t *testing.T) {
+	type entry struct {
+		Path     string    `json:"path"`
+		LastTrim time.Time `json:"last_trim,omitzero"`
+		Restored bool      `json:"restored,string,omitzero"`
+	}
+
+	required := SchemaOf(entry{})["required"].([]string)
+	if len(required) != 1 || required[0] != "path" {
+		t.Errorf("Expected only path to be required, got %v", required)
+	}
+}
+
+func TestSchemaOf_Embedded(t *testing.T) {
+	type counts struct {
+		Hits   int `json:"hits"`
+		Misses int `json:"misses"`
+	}
+	type report struct {
+		Command string `json:"command"`
+		counts
+	}
+
+	properties := SchemaOf(report{})["properties"].(map[string]any)
+	for _, name := range []string{"command", "hits", "misses"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Expected property %s, got %v", name, properties)
+		}
+	}
+	if len(properties) != 3 {
+		t.Errorf("Expected the embedded fields to be flattened, got %v", properties)
+	}
+}
+
+type tree struct {
+	Name     string  `json:"name"`
+	Children []*tree `json:"children,omitempty"`
+}
+
+func TestSchemaOf_Recursive(t *testing.T) {
+	schema := SchemaOf(tree{})
+
+	if got := schema["$ref"]; got != "#/$defs/tree" {
+		t.Fatalf("Expected a reference to the tree definition, got %v", got)
+	}
+	def := schema["$defs"].(map[string]any)["tree"].(map[string]any)
+	children := def["properties"].(map[string]any)["children"].(map[string]any)
+	if got := children["items"].(map[string]any)["$ref"]; got != "#/$defs/tree" {
+		t.Errorf("Expected children to reference the tree definition, got %v", got)
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Root
+		wantErr bool
+	}{
+		{"ci=/var/cache/go-ci", Root{Label: "ci", Path: "/var/cache/go-ci"}, false},
+		{"/var/cache/go", Root{Label: "/var/cache/go", Path: "/var/cache/go"}, false},
+		{"ci=", Root{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRoot(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseRoot(%q) = %+v, %v", tt.input, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cachemgr
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf derives a JSON Schema from the type of v, following the same
+// json struct tags encoding/json uses when the value is printed. Recursive
+// types are described once under $defs and referenced from where they
+// recur.
+func SchemaOf(v any) map[string]any {
+	b := &schemaBuilder{
+		building:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		defs:      make(map[string]any),
+	}
+	schema := b.schemaOfType(reflect.TypeOf(v))
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+	return schema
+}
+
+// schemaBuilder tracks the structs being described to detect recursion
+type schemaBuilder struct {
+	building  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	defs      map[string]any
+}
+
+func schemaRef(t reflect.Type) map[string]any {
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+// omitted reports whether json tag options let encoding/json leave the field
+// out, in which case the property is optional
+func omitted(opts string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *schemaBuilder) schemaOfType(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if b.building[t] {
+			b.recursive[t] = true
+			return schemaRef(t)
+		}
+		b.building[t] = true
+		defer delete(b.building, t)
+
+		properties := make(map[string]any)
+		var required []string
+		for _, field := range reflect.VisibleFields(t) {
+			if !field.IsExported() {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			// Like encoding/json, embedded structs contribute their fields,
+			// which are visited on their own
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			properties[name] = b.schemaOfType(field.Type)
+			if !omitted(opts) {
+				required = append(required, name)
+			}
+		}
+
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		if b.recursive[t] {
+			b.defs[t.Name()] = schema
+			return schemaRef(t)
+		}
+		return schema
+	}
+
+	// Interfaces and anything else may hold any value
+	return map[string]any{}
+}
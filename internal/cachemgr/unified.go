@@ -1,93 +1,459 @@
 package cachemgr
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"time"
 
+	"github.com/muhammadali7768/gocachectl/internal/audit"
 	"github.com/muhammadali7768/gocachectl/internal/cache"
 )
 
 // UnifiedManager acts as a high-level consumer that works with any cache.Manager.
 type UnifiedManager struct {
-	managers []cache.CacheManager
+	managers []managed
+	audit    *audit.Log
 }
 
-// NewUnifiedManager constructs all cache managers and registers them.
-func NewUnifiedManager() (*UnifiedManager, error) {
-	var managers []cache.CacheManager
-	// Initialize build cache manager
-	buildMgr, err := cache.NewBuildManager("")
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize build cache: %w", err)
-	}
+// managed pairs a manager with the kind and root it was registered for
+type managed struct {
+	kind *Kind
+	root Root
+	mgr  cache.CacheManager
+}
 
-	managers = append(managers, buildMgr)
-	// Initialize module cache manager
-	modMgr, err := cache.NewModManager("")
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize module cache: %w", err)
+// Options configures which cache directories a UnifiedManager covers
+type Options struct {
+	// Roots lists the cache directories per locating variable, e.g.
+	// "GOCACHE". Variables without roots use the go command's default.
+	Roots map[string][]Root
+	// Audit records every clear and entry removal; nil disables auditing
+	Audit *audit.Log
+	// AgeBuckets are the upper bounds of the age buckets in stats; nil
+	// uses cache.DefaultAgeBuckets
+	AgeBuckets []time.Duration
+}
+
+// RootStats is the stats of one kind of cache at one root
+type RootStats struct {
+	Kind  *Kind
+	Root  Root
+	Stats cache.Stats
+}
+
+// NewUnifiedManager constructs a manager for every registered kind at each
+// of its configured roots. Optional kinds whose default cache does not
+// exist are left out; configured roots must exist.
+func NewUnifiedManager(opts Options) (*UnifiedManager, error) {
+	var managers []managed
+	for _, kind := range Kinds() {
+		roots := opts.Roots[kind.Env]
+		if len(roots) == 0 {
+			mgr, err := kind.New("")
+			if err != nil {
+				if kind.Optional && errors.Is(err, cache.ErrCacheNotFound) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to initialize %s: %w", kind.Description, err)
+			}
+			root := Root{Label: DefaultLabel, Path: mgr.GetLocation()}
+			managers = append(managers, managed{kind: kind, root: root, mgr: mgr})
+			continue
+		}
+
+		for _, root := range roots {
+			mgr, err := kind.New(root.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize %s at %s: %w", kind.Description, root.Label, err)
+			}
+			managers = append(managers, managed{kind: kind, root: root, mgr: mgr})
+		}
 	}
 
-	managers = append(managers, modMgr)
-	// Initialize test cache manager
-	testMgr, err := cache.NewTestManager("")
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize test cache: %w", err)
+	for _, entry := range managers {
+		if setter, ok := entry.mgr.(cache.AgeBucketsSetter); ok {
+			setter.SetAgeBuckets(opts.AgeBuckets)
+		}
 	}
 
-	managers = append(managers, testMgr)
 	return &UnifiedManager{
 		managers: managers,
+		audit:    opts.Audit,
 	}, nil
 }
 
+// HasRoot reports whether any cache is registered under the label
+func (m *UnifiedManager) HasRoot(label string) bool {
+	for _, entry := range m.managers {
+		if entry.root.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAllStats returns stats for all caches providers
 func (m *UnifiedManager) GetAllStats() ([]cache.Stats, error) {
 	var all []cache.Stats
 
-	for _, mgr := range m.managers {
-		stat, err := mgr.GetStats()
+	for _, entry := range m.managers {
+		stat, err := entry.mgr.GetStats()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get stats for %T: %w", mgr, err)
+			return nil, fmt.Errorf("failed to get stats for %s: %w", entry.kind.Description, err)
 		}
 		all = append(all, stat)
 	}
 	return all, nil
 }
 
-// GetStatsByType retrieves the stats for a specific type ("mod", "build", "test").
-func (m *UnifiedManager) GetStatsByType(kind string) (cache.Stats, error) {
-	for _, mgr := range m.managers {
-		stats, err := mgr.GetStats()
+// GetStatsFor returns stats for the given kinds at the given root labels,
+// in registration order. Empty roots select every root.
+func (m *UnifiedManager) GetStatsFor(kinds, roots []string) ([]RootStats, error) {
+	var all []RootStats
+
+	for _, entry := range m.managers {
+		if !entry.selected(kinds, roots) {
+			continue
+		}
+		stat, err := entry.mgr.GetStats()
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to get stats for %s at %s: %w", entry.kind.Description, entry.root.Label, err)
+		}
+		all = append(all, RootStats{Kind: entry.kind, Root: entry.root, Stats: stat})
+	}
+	return all, nil
+}
+
+// selected reports whether the entry matches the kinds and root labels;
+// empty roots match every root
+func (e managed) selected(kinds, roots []string) bool {
+	return slices.Contains(kinds, e.kind.Name) && (len(roots) == 0 || slices.Contains(roots, e.root.Label))
+}
+
+// BrowsableCache is a cache whose entries can be listed and removed one by
+// one
+type BrowsableCache struct {
+	Kind   *Kind
+	Root   Root
+	Lister cache.EntryLister
+}
+
+// Browsable returns the caches of the given kinds whose managers implement
+// cache.EntryLister, in registration order
+func (m *UnifiedManager) Browsable(kinds []string) []BrowsableCache {
+	var browsable []BrowsableCache
+	for _, entry := range m.managers {
+		lister, ok := entry.mgr.(cache.EntryLister)
+		if !ok || !slices.Contains(kinds, entry.kind.Name) {
+			continue
 		}
-		if stats.Type() == kind {
-			return stats, nil
+		if m.audit != nil {
+			lister = auditedLister{EntryLister: lister, log: m.audit, entry: entry}
 		}
+		browsable = append(browsable, BrowsableCache{Kind: entry.kind, Root: entry.root, Lister: lister})
 	}
-	return nil, fmt.Errorf("no stats found for type: %s", kind)
+	return browsable
 }
 
-// GetCacheInfo retrieves cache location information
-func (m *UnifiedManager) GetCacheInfo() (*cache.CacheInfo, error) {
-	info := &cache.CacheInfo{}
+// ListedEntry is an entry of one of the caches, with what it holds
+type ListedEntry struct {
+	Cache string `json:"cache"` // kind of the cache holding it
+	Root  string `json:"root"`  // label of its root
+	cache.Entry
+	cache.EntryAttrs
 
-	// Get GOCACHE
-	gocache, err := cache.GetGoEnv("GOCACHE")
-	if err != nil {
-		info.BuildCacheOK = false
-	} else {
-		info.GOCACHE = gocache
-		info.BuildCacheOK = true
+	// owner indexes the manager that listed it
+	owner int
+}
+
+// ListEntries returns the entries of the caches of the given kinds at the
+// given root labels, described by their managers when they can. Empty
+// roots select every root.
+func (m *UnifiedManager) ListEntries(kinds, roots []string) ([]ListedEntry, error) {
+	var listed []ListedEntry
+	for i, entry := range m.managers {
+		lister, ok := entry.mgr.(cache.EntryLister)
+		if !ok || !entry.selected(kinds, roots) {
+			continue
+		}
+		entries, err := lister.ListEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s at %s: %w", entry.kind.Description, entry.root.Label, err)
+		}
+		describer, _ := entry.mgr.(cache.EntryDescriber)
+		for _, e := range entries {
+			item := ListedEntry{Cache: entry.kind.Name, Root: entry.root.Label, Entry: e, owner: i}
+			if describer != nil {
+				item.EntryAttrs = describer.DescribeEntry(e)
+			}
+			listed = append(listed, item)
+		}
 	}
+	return listed, nil
+}
 
-	// Get GOMODCACHE
-	gomodcache, err := cache.GetGoEnv("GOMODCACHE")
-	if err != nil {
-		info.ModCacheOK = false
-	} else {
-		info.GOMODCACHE = gomodcache
-		info.ModCacheOK = true
+// RemoveListed removes entries returned by ListEntries through the
+// managers that listed them, once no go command is using their cache as c
+// allows. Paths that could not be removed are reported in the result.
+func (m *UnifiedManager) RemoveListed(entries []ListedEntry, c cache.Concurrency) (*cache.ClearResult, error) {
+	result := &cache.ClearResult{
+		Caches: make(map[string]*cache.CacheClearResult),
+	}
+	byOwner := make(map[int][]cache.Entry)
+	for _, e := range entries {
+		byOwner[e.owner] = append(byOwner[e.owner], e.Entry)
+	}
+
+	for i, entry := range m.managers {
+		owned := byOwner[i]
+		if len(owned) == 0 {
+			continue
+		}
+		lister, ok := entry.mgr.(cache.EntryLister)
+		if !ok {
+			return nil, fmt.Errorf("the %s cannot remove entries", entry.kind.Description)
+		}
+		if m.audit != nil {
+			lister = auditedLister{EntryLister: lister, log: m.audit, entry: entry}
+		}
+
+		kindResult, ok := result.Caches[entry.kind.Name]
+		if !ok {
+			kindResult = &cache.CacheClearResult{}
+			result.Caches[entry.kind.Name] = kindResult
+		}
+		guarded, _ := entry.mgr.(cache.ConcurrencySetter)
+		if guarded != nil {
+			guarded.SetConcurrency(c)
+		}
+		deleted, freed, err := lister.RemoveEntries(owned)
+		if guarded != nil {
+			guarded.SetConcurrency(cache.Concurrency{})
+		}
+		kindResult.Deleted += deleted
+		kindResult.Freed += freed
+		result.TotalFreed += freed
+		if err != nil {
+			entry.addFailures(result, err)
+		}
+	}
+	return result, nil
+}
+
+// ListedModule is a module version in one of the module caches
+type ListedModule struct {
+	Root string `json:"root"` // label of its root
+	cache.ModuleVersion
+
+	// owner indexes the manager that listed it
+	owner int
+}
+
+// ModuleVersions returns the module versions in the module caches at the
+// given root labels, in registration order. Empty roots select every root.
+func (m *UnifiedManager) ModuleVersions(roots []string) ([]ListedModule, error) {
+	var listed []ListedModule
+	for i, entry := range m.managers {
+		lister, ok := entry.mgr.(cache.VersionLister)
+		if !ok || len(roots) > 0 && !slices.Contains(roots, entry.root.Label) {
+			continue
+		}
+		versions, err := lister.ListVersions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s at %s: %w", entry.kind.Description, entry.root.Label, err)
+		}
+		for _, v := range versions {
+			listed = append(listed, ListedModule{Root: entry.root.Label, ModuleVersion: v, owner: i})
+		}
+	}
+	return listed, nil
+}
+
+// RemoveModules removes module versions returned by ModuleVersions through
+// the managers that listed them. With quarantine they are moved into the
+// trash instead, and the version lists they are dropped from are kept
+// there, so that undo restores both. Paths that could not be removed are
+// reported in the result.
+func (m *UnifiedManager) RemoveModules(modules []ListedModule, quarantine bool) (*cache.ClearResult, error) {
+	result := &cache.ClearResult{
+		Caches: make(map[string]*cache.CacheClearResult),
+	}
+	byOwner := make(map[int][]cache.ModuleVersion)
+	for _, mod := range modules {
+		byOwner[mod.owner] = append(byOwner[mod.owner], mod.ModuleVersion)
+	}
+
+	now := time.Now()
+	trashID := cache.NewTrashID(now)
+	quarantines := make(map[string]*cache.Quarantine)
+
+	var errs []error
+	for i, entry := range m.managers {
+		owned := byOwner[i]
+		if len(owned) == 0 {
+			continue
+		}
+		lister, ok := entry.mgr.(cache.VersionLister)
+		if !ok {
+			return nil, fmt.Errorf("the %s holds no module versions", entry.kind.Description)
+		}
+
+		kindResult, ok := result.Caches[entry.kind.Name]
+		if !ok {
+			kindResult = &cache.CacheClearResult{}
+			result.Caches[entry.kind.Name] = kindResult
+		}
+
+		var setter cache.RemoverSetter
+		if quarantine {
+			var err error
+			if setter, err = entry.quarantine(quarantines, trashID, now); err != nil {
+				entry.addFailures(result, err)
+				continue
+			}
+		}
+		deleted, freed, err := lister.RemoveVersions(owned)
+		if setter != nil {
+			setter.SetRemover(nil)
+		}
+		kindResult.Deleted += deleted
+		kindResult.Freed += freed
+		result.TotalFreed += freed
+		if err != nil {
+			entry.addFailures(result, err)
+		}
+		if m.audit != nil {
+			var id string
+			if q := quarantines[entry.mgr.GetLocation()]; q != nil && !q.Empty() {
+				id = trashID
+			}
+			if err := auditRemoval(m.audit, entry, deleted, freed, id, err); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	var commitErrs []error
+	result.TrashID, commitErrs = commitQuarantines(quarantines, trashID)
+	return result, errors.Join(append(errs, commitErrs...)...)
+}
+
+// quarantine makes the manager of e move what it removes into the trash of
+// its directory, under the operation trashID started at now. Caches sharing
+// a directory share its quarantine in quarantines.
+func (e managed) quarantine(quarantines map[string]*cache.Quarantine, trashID string, now time.Time) (cache.RemoverSetter, error) {
+	setter, ok := e.mgr.(cache.RemoverSetter)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be quarantined", e.kind.Description)
+	}
+	location := e.mgr.GetLocation()
+	if quarantines[location] == nil {
+		quarantines[location] = cache.NewTrash(location).Quarantine(trashID, now)
+	}
+	setter.SetRemover(quarantines[location].For(e.kind.Name))
+	return setter, nil
+}
+
+// commitQuarantines writes the manifests of an operation's quarantines. It
+// returns the operation ID if anything was quarantined.
+func commitQuarantines(quarantines map[string]*cache.Quarantine, trashID string) (string, []error) {
+	var id string
+	var errs []error
+	for _, quarantine := range quarantines {
+		if !quarantine.Empty() {
+			id = trashID
+		}
+		if err := quarantine.Commit(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return id, errs
+}
+
+// RootManager is the manager of one kind of cache at one root
+type RootManager struct {
+	Root    Root
+	Manager cache.CacheManager
+}
+
+// Managers returns the managers of a kind at the given root labels, in
+// registration order. Empty roots select every root.
+func (m *UnifiedManager) Managers(kind string, roots []string) []RootManager {
+	var managers []RootManager
+	for _, entry := range m.managers {
+		if entry.selected([]string{kind}, roots) {
+			managers = append(managers, RootManager{Root: entry.root, Manager: entry.mgr})
+		}
+	}
+	return managers
+}
+
+// ToolchainRoot is the toolchain manager of one module cache root
+type ToolchainRoot struct {
+	Root    Root
+	Manager *cache.ToolchainManager
+}
+
+// Toolchains returns a toolchain manager for each module cache root, in
+// registration order, that scans the project directories for the
+// toolchains they require
+func (m *UnifiedManager) Toolchains(projects []string) ([]ToolchainRoot, error) {
+	var roots []ToolchainRoot
+	for _, entry := range m.managers {
+		if _, ok := entry.mgr.(*cache.ToolchainManager); !ok {
+			continue
+		}
+		mgr, err := cache.NewToolchainManager(entry.mgr.GetLocation(), projects)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize %s at %s: %w", entry.kind.Description, entry.root.Label, err)
+		}
+		roots = append(roots, ToolchainRoot{Root: entry.root, Manager: mgr})
+	}
+	return roots, nil
+}
+
+// GetStatsByType retrieves the stats for a specific kind ("build", "module", "test", ...).
+func (m *UnifiedManager) GetStatsByType(kind string) (cache.Stats, error) {
+	for _, entry := range m.managers {
+		if entry.kind.Name == kind {
+			return entry.mgr.GetStats()
+		}
+	}
+	return nil, fmt.Errorf("no stats found for type: %s", kind)
+}
+
+// GetCacheInfo retrieves cache location information: the directory of each
+// registered kind at each root, and where optional kinds whose cache does
+// not exist would keep it
+func (m *UnifiedManager) GetCacheInfo() (*cache.CacheInfo, error) {
+	info := &cache.CacheInfo{Roots: []cache.RootInfo{}}
+
+	for _, kind := range Kinds() {
+		found := false
+		for _, entry := range m.managers {
+			if entry.kind != kind {
+				continue
+			}
+			found = true
+			info.Roots = append(info.Roots, cache.RootInfo{
+				Kind:      kind.Name,
+				Env:       kind.Env,
+				Label:     entry.root.Label,
+				Path:      entry.root.Path,
+				Available: dirExists(entry.root.Path),
+			})
+		}
+		if found || kind.Locate == nil {
+			continue
+		}
+		root := cache.RootInfo{Kind: kind.Name, Env: kind.Env, Label: DefaultLabel}
+		if dir, err := kind.Locate(); err == nil {
+			root.Path = dir
+			root.Available = dirExists(dir)
+		}
+		info.Roots = append(info.Roots, root)
 	}
 
 	// Get Go version
@@ -100,45 +466,310 @@ func (m *UnifiedManager) GetCacheInfo() (*cache.CacheInfo, error) {
 	return info, nil
 }
 
-// Clear removes cache entries based on options
-
+// Clear removes cache entries based on options. With opts.Quarantine the
+// entries are moved into the trash of their cache root instead, under one
+// operation ID shared by every root. The clear is recorded in the audit
+// log. Errors writing the trash manifests or the audit log come after the
+// caches were cleared, so the result is valid even then.
 func (m *UnifiedManager) Clear(opts cache.ClearOptions) (*cache.ClearResult, error) {
-	result := &cache.ClearResult{}
+	result := &cache.ClearResult{
+		Caches: make(map[string]*cache.CacheClearResult),
+	}
 
-	for _, mgr := range m.managers {
-		stats, err := mgr.GetStats()
-		if err != nil {
-			result.Errors++
+	// Caches sharing a directory, like the build and test caches, share
+	// its quarantine
+	now := time.Now()
+	trashID := cache.NewTrashID(now)
+	quarantines := make(map[string]*cache.Quarantine)
+
+	for _, entry := range m.managers {
+		// Decide whether to clear this manager
+		if !opts.All && !slices.Contains(opts.Targets, entry.kind.Name) {
+			continue
+		}
+		if len(opts.Roots) > 0 && !slices.Contains(opts.Roots, entry.root.Label) {
 			continue
 		}
 
-		kind := stats.Type()
-
-		// Decide whether to clear this manager
-		if opts.All ||
-			(kind == "build" && opts.Build) ||
-			(kind == "module" && opts.Modules) ||
-			(kind == "test" && opts.Test) {
+		kindResult, ok := result.Caches[entry.kind.Name]
+		if !ok {
+			kindResult = &cache.CacheClearResult{}
+			result.Caches[entry.kind.Name] = kindResult
+		}
 
-			deleted, freed, err := mgr.Clear()
-			if err != nil {
-				result.Errors++
+		var setter cache.RemoverSetter
+		if opts.Quarantine {
+			var err error
+			if setter, err = entry.quarantine(quarantines, trashID, now); err != nil {
+				entry.addFailures(result, err)
 				continue
 			}
+		}
 
-			// Update fields based on type
-			switch kind {
-			case "build":
-				result.BuildDeleted += deleted
-			case "module":
-				result.ModulesDeleted += deleted
-			case "test":
-				result.TestDeleted += deleted
-			}
+		guarded, _ := entry.mgr.(cache.ConcurrencySetter)
+		if guarded != nil {
+			guarded.SetConcurrency(opts.Concurrency())
+		}
 
-			result.TotalFreed += freed
+		// A failed clear may still have removed part of the cache
+		deleted, freed, err := entry.mgr.Clear()
+		if setter != nil {
+			setter.SetRemover(nil)
+		}
+		if guarded != nil {
+			guarded.SetConcurrency(cache.Concurrency{})
+		}
+		kindResult.Deleted += deleted
+		kindResult.Freed += freed
+		result.TotalFreed += freed
+		if err != nil {
+			entry.addFailures(result, err)
 		}
 	}
 
-	return result, nil
+	var errs []error
+	result.TrashID, errs = commitQuarantines(quarantines, trashID)
+
+	record := audit.Record{
+		Operation: "clear",
+		Targets:   opts.Targets,
+		Roots:     opts.Roots,
+		Freed:     result.TotalFreed,
+		TrashID:   result.TrashID,
+	}
+	if opts.All {
+		record.Targets = nil
+		for _, kind := range Kinds() {
+			record.Targets = append(record.Targets, kind.Name)
+		}
+	}
+	for _, kindResult := range result.Caches {
+		record.Deleted += kindResult.Deleted
+	}
+	for _, failure := range result.Failures {
+		record.AddFailure(failure.Path + ": " + failure.Error)
+	}
+	if err := m.audit.Append(record); err != nil {
+		errs = append(errs, err)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// Prune removes the entries opts selects from the targeted caches. An entry
+// is selected only if it matches every filter given: older than
+// opts.OlderThan, produced by a toolchain older than opts.ToolchainOlderThan,
+// and built for one of opts.Platforms. With opts.GoCompatible it instead
+// removes what the go command's own trim would. A dry run only counts them.
+func (m *UnifiedManager) Prune(opts cache.PruneOptions) (*cache.ClearResult, error) {
+	result := &cache.ClearResult{
+		Caches: make(map[string]*cache.CacheClearResult),
+	}
+	now := time.Now()
+
+	for _, entry := range m.managers {
+		if !entry.selected(opts.Targets, opts.Roots) {
+			continue
+		}
+
+		kindResult, ok := result.Caches[entry.kind.Name]
+		if !ok {
+			kindResult = &cache.CacheClearResult{}
+			result.Caches[entry.kind.Name] = kindResult
+		}
+
+		guarded, _ := entry.mgr.(cache.ConcurrencySetter)
+		if guarded != nil {
+			guarded.SetConcurrency(opts.Concurrency())
+		}
+		deleted, freed, err := prune(entry, opts, now)
+		if guarded != nil {
+			guarded.SetConcurrency(cache.Concurrency{})
+		}
+		kindResult.Deleted += deleted
+		kindResult.Freed += freed
+		result.TotalFreed += freed
+		if err != nil {
+			entry.addFailures(result, err)
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	record := audit.Record{
+		Operation: "prune",
+		Targets:   opts.Targets,
+		Roots:     opts.Roots,
+		Freed:     result.TotalFreed,
+	}
+	for _, kindResult := range result.Caches {
+		record.Deleted += kindResult.Deleted
+	}
+	for _, failure := range result.Failures {
+		record.AddFailure(failure.Path + ": " + failure.Error)
+	}
+	return result, m.audit.Append(record)
+}
+
+// prune prunes the cache of entry, or counts what it would prune on a dry
+// run
+func prune(entry managed, opts cache.PruneOptions, now time.Time) (int, int64, error) {
+	if opts.GoCompatible {
+		trimmer, ok := entry.mgr.(cache.Trimmer)
+		if !ok {
+			return 0, 0, fmt.Errorf("the go command does not trim the %s", entry.kind.Description)
+		}
+		if !opts.DryRun {
+			return trimmer.Trim(now)
+		}
+		entries, err := trimmer.TrimEntries(now)
+		if err != nil {
+			return 0, 0, err
+		}
+		return countEntries(entries)
+	}
+
+	lister, ok := entry.mgr.(cache.EntryLister)
+	if !ok {
+		return 0, 0, fmt.Errorf("the %s cannot be pruned", entry.kind.Description)
+	}
+	entries, err := pruneCandidates(entry, lister, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	if opts.OlderThan > 0 {
+		cutoff := now.Add(-opts.OlderThan)
+		entries = slices.DeleteFunc(entries, func(e cache.Entry) bool { return !e.ModTime.Before(cutoff) })
+	}
+	if !opts.DryRun {
+		return lister.RemoveEntries(entries)
+	}
+	return countEntries(entries)
+}
+
+// pruneCandidates returns the entries of the cache a prune selects by the
+// toolchain or platform that produced them, or every entry
+func pruneCandidates(entry managed, lister cache.EntryLister, opts cache.PruneOptions) ([]cache.Entry, error) {
+	keep := opts.ObjectFilter()
+	if keep == nil {
+		return lister.ListEntries()
+	}
+	selector, ok := entry.mgr.(cache.ObjectSelector)
+	if !ok {
+		return nil, fmt.Errorf("the %s holds no compiled Go objects", entry.kind.Description)
+	}
+	return selector.SelectObjects(keep)
+}
+
+// countEntries returns the number and total size of entries, as a dry run
+// reports them
+func countEntries(entries []cache.Entry) (int, int64, error) {
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	return len(entries), size, nil
+}
+
+// SetBreakdown sets the groupings added to the stats of every cache that
+// supports them
+func (m *UnifiedManager) SetBreakdown(b cache.Breakdown) {
+	for _, entry := range m.managers {
+		if setter, ok := entry.mgr.(cache.BreakdownSetter); ok {
+			setter.SetBreakdown(b)
+		}
+	}
+}
+
+// Audit records an operation on the caches in the audit log, if any
+func (m *UnifiedManager) Audit(record audit.Record) error {
+	return m.audit.Append(record)
+}
+
+// auditedLister records every removal through a lister in the audit log
+type auditedLister struct {
+	cache.EntryLister
+	log   *audit.Log
+	entry managed
+}
+
+func (l auditedLister) RemoveEntries(entries []cache.Entry) (int, int64, error) {
+	deleted, freed, err := l.EntryLister.RemoveEntries(entries)
+	if auditErr := auditRemoval(l.log, l.entry, deleted, freed, "", err); auditErr != nil {
+		err = errors.Join(err, auditErr)
+	}
+	return deleted, freed, err
+}
+
+// auditRemoval records in log the removal of entries from the cache of
+// entry, into the trash operation trashID if not empty, with the paths err
+// reports as not removed
+func auditRemoval(log *audit.Log, entry managed, deleted int, freed int64, trashID string, err error) error {
+	record := audit.Record{
+		Operation: "remove",
+		Targets:   []string{entry.kind.Name},
+		Roots:     []string{entry.root.Label},
+		Deleted:   deleted,
+		Freed:     freed,
+		TrashID:   trashID,
+	}
+	var clearErr *cache.ClearError
+	switch {
+	case errors.As(err, &clearErr):
+		for _, failure := range clearErr.Failures {
+			record.AddFailure(failure.Path + ": " + failure.Err.Error())
+		}
+	case err != nil:
+		record.AddFailure(err.Error())
+	}
+	return log.Append(record)
+}
+
+// Trashes returns the trash of every distinct cache directory, in
+// registration order
+func (m *UnifiedManager) Trashes() []*cache.Trash {
+	var trashes []*cache.Trash
+	seen := make(map[string]bool)
+	for _, entry := range m.managers {
+		location := entry.mgr.GetLocation()
+		if !seen[location] {
+			seen[location] = true
+			trashes = append(trashes, cache.NewTrash(location))
+		}
+	}
+	return trashes
+}
+
+// addFailures records the paths err reports as not cleared, or the whole
+// cache when it names none
+func (e managed) addFailures(result *cache.ClearResult, err error) {
+	var clearErr *cache.ClearError
+	if !errors.As(err, &clearErr) {
+		result.Failures = append(result.Failures, cache.ClearFailure{
+			Kind:  e.kind.Name,
+			Root:  e.root.Label,
+			Path:  e.mgr.GetLocation(),
+			Error: err.Error(),
+		})
+		result.Errors++
+		return
+	}
+
+	for _, failure := range clearErr.Failures {
+		result.Failures = append(result.Failures, cache.ClearFailure{
+			Kind:  e.kind.Name,
+			Root:  e.root.Label,
+			Path:  failure.Path,
+			Error: failure.Err.Error(),
+		})
+		result.Errors++
+	}
+}
+
+// dirExists reports whether path is an existing directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
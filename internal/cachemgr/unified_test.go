@@ -1,8 +1,15 @@
 package cachemgr
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/muhammadali7768/gocachectl/internal/audit"
 	"github.com/muhammadali7768/gocachectl/internal/cache"
 )
 
@@ -45,7 +52,10 @@ func TestUnifiedManager_GetAllStats(t *testing.T) {
 	}
 
 	mgr := &UnifiedManager{
-		managers: []cache.CacheManager{mockBuild, mockTest},
+		managers: []managed{
+			{kind: Lookup("build"), mgr: mockBuild},
+			{kind: Lookup("test"), mgr: mockTest},
+		},
 	}
 
 	stats, err := mgr.GetAllStats()
@@ -71,12 +81,15 @@ func TestUnifiedManager_Clear(t *testing.T) {
 	}
 
 	mgr := &UnifiedManager{
-		managers: []cache.CacheManager{mockBuild, mockTest},
+		managers: []managed{
+			{kind: Lookup("build"), mgr: mockBuild},
+			{kind: Lookup("test"), mgr: mockTest},
+		},
 	}
 
 	// Test clearing only build
 	opts := cache.ClearOptions{
-		Build: true,
+		Targets: []string{"build"},
 	}
 
 	result, err := mgr.Clear(opts)
@@ -84,11 +97,11 @@ func TestUnifiedManager_Clear(t *testing.T) {
 		t.Fatalf("Clear failed: %v", err)
 	}
 
-	if result.BuildDeleted != 10 {
-		t.Errorf("Expected 10 build deleted, got %d", result.BuildDeleted)
+	if result.Caches["build"].Deleted != 10 {
+		t.Errorf("Expected 10 build deleted, got %d", result.Caches["build"].Deleted)
 	}
-	if result.TestDeleted != 0 {
-		t.Errorf("Expected 0 test deleted, got %d", result.TestDeleted)
+	if _, ok := result.Caches["test"]; ok {
+		t.Errorf("Expected test cache not to be cleared, got %+v", result.Caches["test"])
 	}
 	if result.TotalFreed != 1000 {
 		t.Errorf("Expected 1000 freed, got %d", result.TotalFreed)
@@ -104,13 +117,493 @@ func TestUnifiedManager_Clear(t *testing.T) {
 		t.Fatalf("Clear failed: %v", err)
 	}
 
-	if result.BuildDeleted != 10 {
-		t.Errorf("Expected 10 build deleted, got %d", result.BuildDeleted)
+	if result.Caches["build"].Deleted != 10 {
+		t.Errorf("Expected 10 build deleted, got %d", result.Caches["build"].Deleted)
 	}
-	if result.TestDeleted != 5 {
-		t.Errorf("Expected 5 test deleted, got %d", result.TestDeleted)
+	if result.Caches["test"].Deleted != 5 {
+		t.Errorf("Expected 5 test deleted, got %d", result.Caches["test"].Deleted)
+	}
+	if result.Caches["test"].Freed != 500 {
+		t.Errorf("Expected 500 test freed, got %d", result.Caches["test"].Freed)
 	}
 	if result.TotalFreed != 1500 {
 		t.Errorf("Expected 1500 freed, got %d", result.TotalFreed)
 	}
 }
+
+func TestUnifiedManager_ClearFailures(t *testing.T) {
+	partial := &MockCacheManager{
+		deleted:  3,
+		freed:    300,
+		location: "/cache/build",
+		err: fmt.Errorf("failed to clear build cache: %w", &cache.ClearError{
+			Failures: []*fs.PathError{
+				{Op: "remove", Path: "/cache/build/00/a-d", Err: fs.ErrPermission},
+				{Op: "remove", Path: "/cache/build/01/b-d", Err: fs.ErrPermission},
+			},
+		}),
+	}
+	broken := &MockCacheManager{
+		location: "/cache/test",
+		err:      errors.New("walk failed"),
+	}
+
+	mgr := &UnifiedManager{
+		managers: []managed{
+			{kind: Lookup("build"), root: Root{Label: "ci", Path: "/cache/build"}, mgr: partial},
+			{kind: Lookup("test"), root: Root{Label: "ci", Path: "/cache/build"}, mgr: broken},
+		},
+	}
+
+	result, err := mgr.Clear(cache.ClearOptions{All: true})
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	if result.Caches["build"].Deleted != 3 || result.TotalFreed != 300 {
+		t.Errorf("Expected the partial clear to be counted, got %+v", result.Caches["build"])
+	}
+	if result.Errors != 3 || len(result.Failures) != 3 {
+		t.Fatalf("Expected 3 failures, got %d: %+v", result.Errors, result.Failures)
+	}
+
+	first := result.Failures[0]
+	if first.Kind != "build" || first.Root != "ci" || first.Path != "/cache/build/00/a-d" || first.Error != fs.ErrPermission.Error() {
+		t.Errorf("Unexpected failure: %+v", first)
+	}
+	if last := result.Failures[2]; last.Kind != "test" || last.Path != "/cache/test" || last.Error != "walk failed" {
+		t.Errorf("Expected the whole test cache to be reported, got %+v", last)
+	}
+}
+
+func TestUnifiedManager_Roots(t *testing.T) {
+	ci := &MockCacheManager{stats: MockStats{typeStr: "build"}, deleted: 3, freed: 300}
+	local := &MockCacheManager{stats: MockStats{typeStr: "build"}, deleted: 4, freed: 400}
+
+	mgr := &UnifiedManager{
+		managers: []managed{
+			{kind: Lookup("build"), root: Root{Label: "ci", Path: "/ci"}, mgr: ci},
+			{kind: Lookup("build"), root: Root{Label: "local", Path: "/local"}, mgr: local},
+		},
+	}
+
+	stats, err := mgr.GetStatsFor([]string{"build"}, nil)
+	if err != nil {
+		t.Fatalf("GetStatsFor failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("Expected stats for 2 roots, got %d", len(stats))
+	}
+
+	stats, err = mgr.GetStatsFor([]string{"build"}, []string{"ci"})
+	if err != nil {
+		t.Fatalf("GetStatsFor failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Root.Label != "ci" {
+		t.Errorf("Expected stats for the ci root only, got %+v", stats)
+	}
+
+	// Clearing every root aggregates per kind
+	result, err := mgr.Clear(cache.ClearOptions{Targets: []string{"build"}})
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 7 || result.TotalFreed != 700 {
+		t.Errorf("Expected 7 deleted and 700 freed, got %+v", result.Caches["build"])
+	}
+
+	// Clearing one root leaves the others alone
+	result, err = mgr.Clear(cache.ClearOptions{Targets: []string{"build"}, Roots: []string{"local"}})
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 4 {
+		t.Errorf("Expected 4 deleted, got %d", result.Caches["build"].Deleted)
+	}
+}
+
+func TestUnifiedManager_ClearQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "00"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00", "b1-a"), []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	build, err := cache.NewBuildManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := &UnifiedManager{
+		managers: []managed{
+			{kind: Lookup("build"), root: Root{Label: DefaultLabel, Path: dir}, mgr: build},
+			{kind: Lookup("test"), root: Root{Label: DefaultLabel, Path: dir}, mgr: &MockCacheManager{location: dir}},
+		},
+	}
+
+	result, err := mgr.Clear(cache.ClearOptions{All: true, Quarantine: true})
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if result.TrashID == "" || result.Caches["build"].Deleted != 1 {
+		t.Errorf("Expected the build entry to be quarantined, got %+v", result)
+	}
+	if result.Errors != 1 || result.Failures[0].Kind != "test" {
+		t.Errorf("Expected the mock to be refused as it cannot quarantine, got %+v", result.Failures)
+	}
+
+	trashes := mgr.Trashes()
+	if len(trashes) != 1 {
+		t.Fatalf("Expected one trash for the shared directory, got %d", len(trashes))
+	}
+	ops, err := trashes[0].Operations()
+	if err != nil || len(ops) != 1 || ops[0].ID != result.TrashID {
+		t.Fatalf("Expected operation %s in the trash, got %+v, %v", result.TrashID, ops, err)
+	}
+}
+
+func TestUnifiedManager_Audit(t *testing.T) {
+	log := audit.New(filepath.Join(t.TempDir(), "audit.jsonl"), []string{"gocachectl", "clear"})
+	build := &MockCacheManager{deleted: 3, freed: 300, location: "/cache/build"}
+
+	mgr := &UnifiedManager{
+		managers: []managed{
+			{kind: Lookup("build"), root: Root{Label: "ci", Path: "/cache/build"}, mgr: build},
+		},
+		audit: log,
+	}
+
+	if _, err := mgr.Clear(cache.ClearOptions{Targets: []string{"build"}, Roots: []string{"ci"}}); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	records, err := log.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected one record, got %+v", records)
+	}
+	r := records[0]
+	if r.Operation != "clear" || r.Deleted != 3 || r.Freed != 300 || r.Targets[0] != "build" || r.Roots[0] != "ci" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+	if len(r.Command) != 2 || r.User == "" {
+		t.Errorf("Expected the command line and user to be recorded, got %+v", r)
+	}
+}
+
+func TestUnifiedManager_Prune(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	for name, mtime := range map[string]time.Time{"b1-a": old, "b2-a": time.Now()} {
+		path := filepath.Join(dir, "00", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("archive"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build, err := cache.NewBuildManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := audit.New(filepath.Join(t.TempDir(), "audit.jsonl"), []string{"gocachectl", "prune"})
+
+	mgr := &UnifiedManager{
+		managers: []managed{
+			{kind: Lookup("build"), root: Root{Label: DefaultLabel, Path: dir}, mgr: build},
+			{kind: Lookup("gopls"), root: Root{Label: DefaultLabel, Path: dir}, mgr: &MockCacheManager{location: dir}},
+		},
+		audit: log,
+	}
+
+	opts := cache.PruneOptions{Targets: []string{"build"}, OlderThan: 7 * 24 * time.Hour, DryRun: true}
+	result, err := mgr.Prune(opts)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 1 || result.TotalFreed != 7 {
+		t.Errorf("Expected 1 entry to prune, got %+v", result.Caches["build"])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00", "b1-a")); err != nil {
+		t.Errorf("Expected a dry run to keep the entry: %v", err)
+	}
+
+	opts.DryRun = false
+	opts.GoCompatible = true
+	opts.Targets = []string{"build", "gopls"}
+	result, err = mgr.Prune(opts)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 1 {
+		t.Errorf("Expected the old entry to be trimmed, got %+v", result.Caches["build"])
+	}
+	if result.Errors != 1 || result.Failures[0].Kind != "gopls" {
+		t.Errorf("Expected the gopls cache to be refused, got %+v", result.Failures)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trim.txt")); err != nil {
+		t.Errorf("Expected the trim to be recorded: %v", err)
+	}
+
+	records, err := log.Query(audit.Filter{Operation: "prune"})
+	if err != nil || len(records) != 1 || records[0].Deleted != 1 {
+		t.Errorf("Expected one audited prune, got %+v, %v", records, err)
+	}
+}
+
+func TestUnifiedManager_PruneToolchain(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"0a/0a01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object linux amd64 go1.23.4 X:none\n",
+		"0b/0b01-a": "v1 0b01 0a01 60 1700000000\n",
+		"0c/0c01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object linux amd64 go1.24.1 X:none\n",
+		"0d/0d01-a": "v1 0d01 0c01 60 1700000000\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build, err := cache.NewBuildManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &UnifiedManager{
+		managers: []managed{{kind: Lookup("build"), root: Root{Label: DefaultLabel, Path: dir}, mgr: build}},
+	}
+
+	result, err := mgr.Prune(cache.PruneOptions{Targets: []string{"build"}, ToolchainOlderThan: "go1.24", Force: true})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 2 {
+		t.Errorf("Expected the go1.23.4 archive and its action to be pruned, got %+v", result.Caches["build"])
+	}
+	for name, kept := range map[string]bool{"0a/0a01-d": false, "0b/0b01-a": false, "0c/0c01-d": true, "0d/0d01-a": true} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if (err == nil) != kept {
+			t.Errorf("%s: expected kept=%v, got %v", name, kept, err)
+		}
+	}
+}
+
+func TestUnifiedManager_ListEntries(t *testing.T) {
+	roots := map[string]string{"a": t.TempDir(), "b": t.TempDir()}
+	files := map[string]string{
+		"0a/0a01-d": "!<arch>\n__.PKGDEF 0 0 0 644 100 `\ngo object linux amd64 go1.24.1 X:none\n",
+		"0b/0b01-a": "v1 0b01 0a01 60 1700000000\n",
+	}
+	mgr := &UnifiedManager{audit: audit.New(filepath.Join(t.TempDir(), "audit.jsonl"), []string{"gocachectl", "ls"})}
+	for _, label := range []string{"a", "b"} {
+		for name, content := range files {
+			path := filepath.Join(roots[label], filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		build, err := cache.NewBuildManager(roots[label])
+		if err != nil {
+			t.Fatal(err)
+		}
+		mgr.managers = append(mgr.managers, managed{kind: Lookup("build"), root: Root{Label: label, Path: roots[label]}, mgr: build})
+	}
+
+	entries, err := mgr.ListEntries([]string{"build"}, nil)
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 2 entries per root, got %+v", entries)
+	}
+	var actions []ListedEntry
+	for _, e := range entries {
+		switch {
+		case e.Kind == cache.EntryAction:
+			actions = append(actions, e)
+		case e.Toolchain != "go1.24.1" || e.Platform != "linux/amd64":
+			t.Errorf("Expected an output of go1.24.1 for linux/amd64, got %+v", e)
+		}
+	}
+	if len(actions) != 2 || actions[0].Root != "a" || actions[1].Root != "b" {
+		t.Fatalf("Expected an action per root, got %+v", actions)
+	}
+
+	// Only the action of root b is removed, by its own manager
+	result, err := mgr.RemoveListed(actions[1:], cache.Concurrency{Mode: cache.Force})
+	if err != nil {
+		t.Fatalf("RemoveListed failed: %v", err)
+	}
+	if result.Caches["build"].Deleted != 1 || result.Errors != 0 {
+		t.Errorf("Expected 1 entry removed, got %+v", result)
+	}
+	for label, kept := range map[string]bool{"a": true, "b": false} {
+		_, err := os.Stat(filepath.Join(roots[label], "0b", "0b01-a"))
+		if (err == nil) != kept {
+			t.Errorf("%s: expected the action kept=%v, got %v", label, kept, err)
+		}
+	}
+
+	records, err := mgr.audit.Query(audit.Filter{Operation: "remove"})
+	if err != nil || len(records) != 1 || records[0].Roots[0] != "b" {
+		t.Errorf("Expected one audited removal from b, got %+v, %v", records, err)
+	}
+}
+
+func TestUnifiedManager_RemoveModules(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"cache/download/example.com/a/@v/v1.0.0.mod",
+		"cache/download/example.com/a/@v/v1.1.0.mod",
+		"example.com/a@v1.0.0/go.mod",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example.com/a\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod, err := cache.NewModManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &UnifiedManager{
+		managers: []managed{{kind: Lookup("module"), root: Root{Label: "default", Path: dir}, mgr: mod}},
+		audit:    audit.New(filepath.Join(t.TempDir(), "audit.jsonl"), []string{"gocachectl", "mod", "rm"}),
+	}
+
+	modules, err := mgr.ModuleVersions(nil)
+	if err != nil || len(modules) != 2 {
+		t.Fatalf("Expected 2 module versions, got %+v, %v", modules, err)
+	}
+	result, err := mgr.RemoveModules(modules[:1], false)
+	if err != nil {
+		t.Fatalf("RemoveModules failed: %v", err)
+	}
+	if result.Caches["module"].Deleted != 1 || result.Errors != 0 {
+		t.Errorf("Expected 1 version removed, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com", "a@v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("Expected the extracted tree removed, got %v", err)
+	}
+	if left, err := mgr.ModuleVersions(nil); err != nil || len(left) != 1 || left[0].Version != "v1.1.0" {
+		t.Errorf("Expected v1.1.0 left, got %+v, %v", left, err)
+	}
+
+	records, err := mgr.audit.Query(audit.Filter{Operation: "remove"})
+	if err != nil || len(records) != 1 || records[0].Deleted != 1 {
+		t.Errorf("Expected one audited removal, got %+v, %v", records, err)
+	}
+}
+
+func TestUnifiedManager_RemoveModulesQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"cache/download/example.com/a/@v/v1.0.0.mod": "module example.com/a\n",
+		"cache/download/example.com/a/@v/v1.1.0.mod": "module example.com/a\n",
+		"cache/download/example.com/a/@v/list":       "v1.0.0\nv1.1.0\n",
+		"example.com/a@v1.0.0/go.mod":                "module example.com/a\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod, err := cache.NewModManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &UnifiedManager{
+		managers: []managed{{kind: Lookup("module"), root: Root{Label: "default", Path: dir}, mgr: mod}},
+	}
+
+	modules, err := mgr.ModuleVersions(nil)
+	if err != nil || len(modules) != 2 {
+		t.Fatalf("Expected 2 module versions, got %+v, %v", modules, err)
+	}
+	result, err := mgr.RemoveModules(modules[:1], true)
+	if err != nil {
+		t.Fatalf("RemoveModules failed: %v", err)
+	}
+	if result.TrashID == "" || result.Caches["module"].Deleted != 1 {
+		t.Fatalf("Expected 1 version quarantined, got %+v", result)
+	}
+	list := filepath.Join(dir, "cache", "download", "example.com", "a", "@v", "list")
+	if data, err := os.ReadFile(list); err != nil || string(data) != "v1.1.0\n" {
+		t.Fatalf("Expected v1.0.0 dropped from the list, got %q, %v", data, err)
+	}
+
+	// Undo puts back the version and the list it was dropped from
+	ops, err := cache.NewTrash(dir).Operations()
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("Expected one trash operation, got %+v, %v", ops, err)
+	}
+	if _, err := ops[0].Restore(time.Now()); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if data, err := os.ReadFile(list); err != nil || string(data) != "v1.0.0\nv1.1.0\n" {
+		t.Errorf("Expected the list restored, got %q, %v", data, err)
+	}
+	if left, err := mgr.ModuleVersions(nil); err != nil || len(left) != 2 {
+		t.Errorf("Expected both versions back, got %+v, %v", left, err)
+	}
+}
+
+func TestUnifiedManager_Toolchains(t *testing.T) {
+	roots := []Root{{Label: "a", Path: t.TempDir()}, {Label: "b", Path: t.TempDir()}}
+	mgr, err := NewUnifiedManager(Options{Roots: map[string][]Root{"GOMODCACHE": roots}})
+	if err != nil {
+		t.Fatalf("NewUnifiedManager failed: %v", err)
+	}
+	toolchains, err := mgr.Toolchains(nil)
+	if err != nil {
+		t.Fatalf("Toolchains failed: %v", err)
+	}
+	if len(toolchains) != 2 || toolchains[0].Root != roots[0] || toolchains[1].Manager.GetLocation() != roots[1].Path {
+		t.Errorf("Expected a toolchain manager per module cache root, got %+v", toolchains)
+	}
+}
+
+func TestUnifiedManager_GetCacheInfo(t *testing.T) {
+	root := Root{Label: "ci", Path: t.TempDir()}
+	t.Setenv("GOPLSCACHE", filepath.Join(root.Path, "missing"))
+	build, err := cache.NewBuildManager(root.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &UnifiedManager{managers: []managed{{kind: Lookup("build"), root: root, mgr: build}}}
+
+	info, err := mgr.GetCacheInfo()
+	if err != nil {
+		t.Fatalf("GetCacheInfo failed: %v", err)
+	}
+	byKind := make(map[string]cache.RootInfo)
+	for _, r := range info.Roots {
+		byKind[r.Kind] = r
+	}
+	if got := byKind["build"]; got != (cache.RootInfo{Kind: "build", Env: "GOCACHE", Label: "ci", Path: root.Path, Available: true}) {
+		t.Errorf("Expected the configured build root, got %+v", got)
+	}
+	// Optional kinds without a cache still show where it would be
+	if got := byKind["gopls"]; got.Path != filepath.Join(root.Path, "missing") || got.Available {
+		t.Errorf("Expected the missing gopls cache, got %+v", got)
+	}
+}
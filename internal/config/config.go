@@ -0,0 +1,454 @@
+// Package config resolves gocachectl's settings from defaults, the config
+// file, a named profile, GOCACHECTL_* environment variables and flags.
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+	"github.com/muhammadali7768/gocachectl/internal/cachemgr"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes environment variables overriding config keys, e.g.
+// GOCACHECTL_OUTPUT for output and GOCACHECTL_CLEAR_TARGETS for clear.targets
+const EnvPrefix = "GOCACHECTL"
+
+// OutputFormats lists the valid values of output. The template format
+// takes its template after an equals sign, e.g. "template={{.Size}}".
+var OutputFormats = []string{"table", "json", "yaml", "csv", "ndjson", "template"}
+
+// Config is the effective configuration
+type Config struct {
+	Output     string                     `mapstructure:"output" json:"output"`
+	Clear      ClearConfig                `mapstructure:"clear" json:"clear"`
+	Prune      PruneConfig                `mapstructure:"prune" json:"prune"`
+	Stats      StatsConfig                `mapstructure:"stats" json:"stats"`
+	Roots      map[string][]cachemgr.Root `mapstructure:"roots" json:"roots"` // keyed by lower-cased variable, e.g. "gocache"
+	Thresholds Thresholds                 `mapstructure:"thresholds" json:"thresholds"`
+	Audit      AuditConfig                `mapstructure:"audit" json:"audit"`
+}
+
+// ClearConfig configures the clear command
+type ClearConfig struct {
+	// Targets are the kinds cleared when no cache flag is given
+	Targets []string `mapstructure:"targets" json:"targets"`
+	// Quarantine moves entries removed by clear and mod rm into the trash
+	// instead of deleting them, so that undo can restore them
+	Quarantine bool `mapstructure:"quarantine" json:"quarantine"`
+}
+
+// PruneConfig configures the default prune policy
+type PruneConfig struct {
+	// MaxAge prunes entries unused for longer than this
+	MaxAge Age `mapstructure:"max_age" json:"max_age"`
+	// GoCompatible prunes exactly as the go command's own trim does
+	GoCompatible bool `mapstructure:"go_compatible" json:"go_compatible"`
+}
+
+// StatsConfig configures the stats command
+type StatsConfig struct {
+	// AgeBuckets are the ascending upper bounds of the age buckets entries
+	// are counted in; older entries fall in a last bucket
+	AgeBuckets []Age `mapstructure:"age_buckets" json:"age_buckets"`
+}
+
+// AuditConfig configures the audit log of destructive operations
+type AuditConfig struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Log is the audit log file; empty selects gocachectl/audit.jsonl in
+	// the user's config directory. Point several users at one file to
+	// audit a shared host.
+	Log string `mapstructure:"log" json:"log"`
+}
+
+// Thresholds are the limits caches are checked against; zero disables a limit
+type Thresholds struct {
+	MaxTotal   ByteSize `mapstructure:"max_total" json:"max_total"`
+	MaxBuild   ByteSize `mapstructure:"max_build" json:"max_build"`
+	MaxAge     Age      `mapstructure:"max_age" json:"max_age"`
+	MaxModules int      `mapstructure:"max_modules" json:"max_modules"`
+}
+
+// Buckets returns the age buckets as durations
+func (s StatsConfig) Buckets() []time.Duration {
+	buckets := make([]time.Duration, len(s.AgeBuckets))
+	for i, age := range s.AgeBuckets {
+		buckets[i] = time.Duration(age)
+	}
+	return buckets
+}
+
+// ByteSize is a size in bytes, written like "15GB"
+type ByteSize int64
+
+func (b ByteSize) String() string {
+	return cache.FormatBytes(int64(b))
+}
+
+// MarshalText writes the size the way config files do
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Age is a duration, written like "30d"
+type Age time.Duration
+
+func (a Age) String() string {
+	return cache.FormatAge(time.Duration(a))
+}
+
+// MarshalText writes the age the way config files do
+func (a Age) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Flag is a config value given on the command line
+type Flag struct {
+	Name  string // flag name without dashes
+	Value any
+}
+
+// Options selects what Load layers over the defaults
+type Options struct {
+	// Profile names a section under profiles in the config file
+	Profile string
+	// Flags holds command line values keyed by config key
+	Flags map[string]Flag
+}
+
+// Sources maps each config key to where its effective value comes from:
+// "default", "file", "profile <name>", "env <VARIABLE>" or "flag --<name>"
+type Sources map[string]string
+
+// SetDefaults registers every config key with its default value, which
+// also lets environment variables override keys absent from the file
+func SetDefaults(v *viper.Viper) {
+	v.SetDefault("output", "table")
+	v.SetDefault("clear.targets", []string{})
+	v.SetDefault("clear.quarantine", false)
+	v.SetDefault("prune.max_age", "")
+	v.SetDefault("prune.go_compatible", false)
+	v.SetDefault("stats.age_buckets", []string{"1d", "7d", "30d"})
+	for _, env := range rootEnvs() {
+		v.SetDefault(RootsKey(env), []string{})
+	}
+	v.SetDefault("thresholds.max_total", "")
+	v.SetDefault("thresholds.max_build", "")
+	v.SetDefault("thresholds.max_age", "")
+	v.SetDefault("thresholds.max_modules", 0)
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.log", "")
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+}
+
+// Keys returns every config key in display order
+func Keys() []string {
+	keys := []string{"output", "clear.targets", "clear.quarantine", "prune.max_age", "prune.go_compatible", "stats.age_buckets"}
+	for _, env := range rootEnvs() {
+		keys = append(keys, RootsKey(env))
+	}
+	return append(keys, "thresholds.max_total", "thresholds.max_build", "thresholds.max_age", "thresholds.max_modules",
+		"audit.enabled", "audit.log")
+}
+
+// RootsKey returns the key holding the cache roots for a variable such as
+// "GOCACHE"
+func RootsKey(env string) string {
+	return "roots." + strings.ToLower(env)
+}
+
+// EnvName returns the environment variable overriding a key
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Load resolves the effective configuration. v must have had SetDefaults
+// called and its config file, if any, read.
+func Load(v *viper.Viper, opts Options) (*Config, Sources, error) {
+	var profile map[string]any
+	if opts.Profile != "" {
+		profiles := v.GetStringMap("profiles")
+		p, ok := profiles[opts.Profile]
+		if !ok {
+			return nil, nil, fmt.Errorf("profile %q is not defined in the config file", opts.Profile)
+		}
+		profile, ok = p.(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("profile %q must be a mapping of config keys", opts.Profile)
+		}
+		if err := v.MergeConfigMap(profile); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply profile %q: %w", opts.Profile, err)
+		}
+	}
+
+	for key, flag := range opts.Flags {
+		v.Set(key, flag.Value)
+	}
+
+	cfg := &Config{}
+	hook := mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToSliceHookFunc(","),
+		decodeHook,
+	)
+	if err := v.Unmarshal(cfg, viper.DecodeHook(hook)); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Roots written as {path: ...} are labelled by their path
+	for _, roots := range cfg.Roots {
+		for i := range roots {
+			if roots[i].Label == "" {
+				roots[i].Label = roots[i].Path
+			}
+		}
+	}
+
+	sources := make(Sources)
+	profileKeys := flatten("", profile)
+	for _, key := range Keys() {
+		switch {
+		case opts.Flags[key].Name != "":
+			sources[key] = "flag --" + opts.Flags[key].Name
+		case envSet(key):
+			sources[key] = "env " + EnvName(key)
+		case slices.Contains(profileKeys, key):
+			sources[key] = "profile " + opts.Profile
+		case v.InConfig(key):
+			sources[key] = "file"
+		default:
+			sources[key] = "default"
+		}
+	}
+
+	return cfg, sources, nil
+}
+
+// Validate checks values that decode but make no sense
+func (c *Config) Validate() error {
+	var problems []string
+
+	format, text, hasText := strings.Cut(c.Output, "=")
+	switch {
+	case !slices.Contains(OutputFormats, format):
+		problems = append(problems, fmt.Sprintf("output: unknown format %q (want one of %s)", c.Output, strings.Join(OutputFormats, ", ")))
+	case format == "template" && text == "":
+		problems = append(problems, "output: template needs a template, e.g. template={{.Size}}")
+	case format != "template" && hasText:
+		problems = append(problems, fmt.Sprintf("output: format %s takes no argument", format))
+	}
+
+	for _, target := range c.Clear.Targets {
+		if cachemgr.Lookup(target) == nil {
+			problems = append(problems, fmt.Sprintf("clear.targets: unknown cache %q", target))
+		}
+	}
+
+	envs := rootEnvs()
+	var keys []string
+	for key := range c.Roots {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.ContainsFunc(envs, func(env string) bool { return strings.ToLower(env) == key }) {
+			problems = append(problems, fmt.Sprintf("roots.%s: unknown cache variable", key))
+			continue
+		}
+		labels := make(map[string]bool)
+		for _, root := range c.Roots[key] {
+			if root.Path == "" {
+				problems = append(problems, fmt.Sprintf("roots.%s: root %q has no path", key, root.Label))
+			}
+			if labels[root.Label] {
+				problems = append(problems, fmt.Sprintf("roots.%s: label %q used twice", key, root.Label))
+			}
+			labels[root.Label] = true
+		}
+	}
+
+	if c.Prune.MaxAge < 0 || c.Thresholds.MaxAge < 0 {
+		problems = append(problems, "ages must not be negative")
+	}
+	if err := cache.ValidateAgeBuckets(c.Stats.Buckets()); err != nil {
+		problems = append(problems, "stats.age_buckets: "+err.Error())
+	}
+	if c.Thresholds.MaxModules < 0 {
+		problems = append(problems, "thresholds.max_modules: must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Value returns the effective value of a key for display
+func (c *Config) Value(key string) any {
+	switch key {
+	case "output":
+		return c.Output
+	case "clear.targets":
+		return c.Clear.Targets
+	case "clear.quarantine":
+		return c.Clear.Quarantine
+	case "prune.max_age":
+		return c.Prune.MaxAge
+	case "prune.go_compatible":
+		return c.Prune.GoCompatible
+	case "stats.age_buckets":
+		return c.Stats.AgeBuckets
+	case "thresholds.max_total":
+		return c.Thresholds.MaxTotal
+	case "thresholds.max_build":
+		return c.Thresholds.MaxBuild
+	case "thresholds.max_age":
+		return c.Thresholds.MaxAge
+	case "thresholds.max_modules":
+		return c.Thresholds.MaxModules
+	case "audit.enabled":
+		return c.Audit.Enabled
+	case "audit.log":
+		return c.Audit.Log
+	}
+
+	if env, ok := strings.CutPrefix(key, "roots."); ok {
+		var roots []string
+		for _, root := range c.Roots[env] {
+			roots = append(roots, root.Label+"="+root.Path)
+		}
+		return roots
+	}
+	return nil
+}
+
+// Template is the commented config file written by "config init"
+const Template = `# gocachectl configuration
+#
+# Every key can be overridden with a GOCACHECTL_* environment variable,
+# e.g. GOCACHECTL_OUTPUT=json or GOCACHECTL_CLEAR_TARGETS=build,test.
+
+# Output format: table, json, yaml, csv, ndjson or template=<Go template>
+output: table
+
+clear:
+  # Caches cleared when clear is run without cache flags
+  targets: []
+  # Move entries removed by clear and mod rm into a trash inside each
+  # cache instead of deleting them; "gocachectl undo" restores the last one
+  quarantine: false
+
+prune:
+  # Prune entries unused for longer than this, e.g. 30d
+  max_age: ""
+  # Prune exactly as the go command's own trim does
+  go_compatible: false
+
+stats:
+  # Upper bounds of the age buckets stats counts entries in, ascending;
+  # older entries fall in a last bucket
+  age_buckets: [1d, 7d, 30d]
+
+# Cache directories per variable, as label=path or {label, path} entries.
+# Variables without roots use the go command's default location.
+roots:
+  gocache: []
+  gomodcache: []
+
+# Limits checked by "gocachectl check"; empty or 0 disables a limit
+thresholds:
+  max_total: ""
+  max_build: ""
+  max_age: ""
+  max_modules: 0
+
+# Every clear and removal is recorded in an audit log, queried with
+# "gocachectl audit". An empty log uses the user's config directory.
+audit:
+  enabled: true
+  log: ""
+
+# Named profiles override any of the keys above, selected with --profile
+profiles:
+  ci:
+    output: json
+    clear:
+      targets: [build, test]
+`
+
+// rootEnvs returns the distinct variables locating registered caches
+func rootEnvs() []string {
+	var envs []string
+	for _, kind := range cachemgr.Kinds() {
+		if !slices.Contains(envs, kind.Env) {
+			envs = append(envs, kind.Env)
+		}
+	}
+	return envs
+}
+
+// envSet reports whether the environment overrides key
+func envSet(key string) bool {
+	_, ok := os.LookupEnv(EnvName(key))
+	return ok
+}
+
+// flatten returns the dotted keys of the leaves of a nested map
+func flatten(prefix string, m map[string]any) []string {
+	var keys []string
+	for k, v := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := v.(map[string]any); ok {
+			keys = append(keys, flatten(key, nested)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// decodeHook converts config strings into roots, sizes, ages and lists of
+// ages
+func decodeHook(from, to reflect.Type, data any) (any, error) {
+	s, ok := data.(string)
+	if !ok || from.Kind() != reflect.String {
+		return data, nil
+	}
+
+	switch to {
+	case reflect.TypeOf(cachemgr.Root{}):
+		return cachemgr.ParseRoot(s)
+	case reflect.TypeOf(ByteSize(0)):
+		if s == "" {
+			return ByteSize(0), nil
+		}
+		n, err := cache.ParseBytes(s)
+		return ByteSize(n), err
+	case reflect.TypeOf(Age(0)):
+		if s == "" {
+			return Age(0), nil
+		}
+		d, err := cache.ParseAge(s)
+		return Age(d), err
+	case reflect.TypeOf([]Age(nil)):
+		// StringToSliceHookFunc only splits into []string
+		if s == "" {
+			return []string{}, nil
+		}
+		return strings.Split(s, ","), nil
+	}
+	return data, nil
+}
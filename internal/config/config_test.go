@@ -0,0 +1,144 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const testConfig = `
+output: table
+clear:
+  targets: [build]
+roots:
+  gocache:
+    - ci=/srv/cache/ci
+    - path: /srv/cache/local
+thresholds:
+  max_total: 15GB
+profiles:
+  ci:
+    output: json
+    thresholds:
+      max_age: 30d
+`
+
+func newTestViper(t *testing.T, content string) *viper.Viper {
+	t.Helper()
+
+	v := viper.New()
+	SetDefaults(v)
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+		t.Fatalf("ReadConfig failed: %v", err)
+	}
+	return v
+}
+
+func TestLoad_Layers(t *testing.T) {
+	t.Setenv("GOCACHECTL_THRESHOLDS_MAX_MODULES", "2000")
+
+	v := newTestViper(t, testConfig)
+	cfg, sources, err := Load(v, Options{
+		Profile: "ci",
+		Flags: map[string]Flag{
+			"roots.gomodcache": {Name: "gomodcache", Value: []string{"shared=/srv/gomod"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Output != "json" || sources["output"] != "profile ci" {
+		t.Errorf("Expected output json from profile ci, got %q from %s", cfg.Output, sources["output"])
+	}
+	if len(cfg.Clear.Targets) != 1 || sources["clear.targets"] != "file" {
+		t.Errorf("Expected clear.targets from file, got %v from %s", cfg.Clear.Targets, sources["clear.targets"])
+	}
+	if cfg.Thresholds.MaxTotal != 15*1024*1024*1024 {
+		t.Errorf("Expected max_total of 15GB, got %d", cfg.Thresholds.MaxTotal)
+	}
+	if time.Duration(cfg.Thresholds.MaxAge) != 30*24*time.Hour {
+		t.Errorf("Expected max_age of 30d, got %v", cfg.Thresholds.MaxAge)
+	}
+	if cfg.Thresholds.MaxModules != 2000 || sources["thresholds.max_modules"] != "env GOCACHECTL_THRESHOLDS_MAX_MODULES" {
+		t.Errorf("Expected max_modules 2000 from env, got %d from %s", cfg.Thresholds.MaxModules, sources["thresholds.max_modules"])
+	}
+	if sources["prune.max_age"] != "default" {
+		t.Errorf("Expected prune.max_age from default, got %s", sources["prune.max_age"])
+	}
+
+	roots := cfg.Roots["gocache"]
+	if len(roots) != 2 || roots[0].Label != "ci" || roots[1].Label != "/srv/cache/local" {
+		t.Errorf("Unexpected gocache roots: %+v", roots)
+	}
+	modRoots := cfg.Roots["gomodcache"]
+	if len(modRoots) != 1 || modRoots[0].Label != "shared" || sources["roots.gomodcache"] != "flag --gomodcache" {
+		t.Errorf("Expected gomodcache root from flag, got %+v from %s", modRoots, sources["roots.gomodcache"])
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	v := newTestViper(t, testConfig)
+	if _, _, err := Load(v, Options{Profile: "nightly"}); err == nil {
+		t.Error("Expected an error for an undefined profile")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := newTestViper(t, `
+output: xml
+clear:
+  targets: [build, nonsense]
+roots:
+  gocache: [a=/x, a=/y]
+`)
+	cfg, _, err := Load(v, Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected Validate to fail")
+	}
+	for _, want := range []string{`output: unknown format "xml"`, `unknown cache "nonsense"`, `label "a" used twice`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestLoad_AgeBuckets(t *testing.T) {
+	v := newTestViper(t, "stats:\n  age_buckets: [1h, 1d]\n")
+	cfg, _, err := Load(v, Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Stats.Buckets(); len(got) != 2 || got[0] != time.Hour || got[1] != 24*time.Hour {
+		t.Errorf("Expected buckets 1h and 1d from the file, got %v", got)
+	}
+
+	// Flags and environment variables give the buckets comma-separated
+	v = newTestViper(t, "")
+	cfg, _, err = Load(v, Options{Flags: map[string]Flag{
+		"stats.age_buckets": {Name: "age-buckets", Value: "7d,30d"},
+	}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Stats.Buckets(); len(got) != 2 || got[1] != 30*24*time.Hour {
+		t.Errorf("Expected buckets 7d and 30d from the flag, got %v", got)
+	}
+
+	cfg.Stats.AgeBuckets = []Age{Age(30 * 24 * time.Hour), Age(7 * 24 * time.Hour)}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ascending") {
+		t.Errorf("Expected descending buckets to be rejected, got %v", err)
+	}
+}
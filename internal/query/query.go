@@ -0,0 +1,381 @@
+// Package query implements the filter expressions of gocachectl ls, such as
+// kind=="archive" && age>30d && size>5MB
+package query
+
+import (
+	"fmt"
+	goversion "go/version"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+// Type is the type of a field, which decides how literals compared with it
+// are read and how its values are ordered
+type Type int
+
+const (
+	String  Type = iota // compared as text
+	Size                // bytes; literals such as 5MB or 512K
+	Age                 // a duration; literals such as 30d, 2w or 12h
+	Version             // a Go version such as go1.24.1, ordered as releases
+	Semver              // a module version such as v1.2.3, ordered by semantic versioning
+	Number              // an integer
+)
+
+// Fields are the fields an expression can use, by name
+type Fields map[string]Type
+
+// Record holds the field values of one element: a string, an int64 for
+// sizes and numbers or a time.Duration for ages
+type Record interface {
+	Field(name string) any
+}
+
+// Expr is a parsed expression
+type Expr struct {
+	source string
+	match  func(Record) bool
+}
+
+// String returns the expression as it was parsed
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Match reports whether the record satisfies the expression
+func (e *Expr) Match(r Record) bool {
+	return e.match(r)
+}
+
+// Parse parses an expression over fields. Comparisons put a field on the
+// left and a literal on the right:
+//
+//	field == "text"    field != "text"    field =~ "regexp"    field !~ "regexp"
+//	field < literal    field <= literal   field > literal      field >= literal
+//
+// and combine with &&, || and !, grouped with parentheses. Strings are
+// quoted with double or single quotes; a bare word is a string too.
+func Parse(s string, fields Fields) (*Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	match, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.tokens[p.pos].text, s)
+	}
+	return &Expr{source: s, match: match}, nil
+}
+
+// Compare returns a function ordering records by field, for sorting
+func Compare(fields Fields, field string) (func(a, b Record) int, error) {
+	typ, ok := fields[field]
+	if !ok {
+		return nil, unknownField(field, fields)
+	}
+	return func(a, b Record) int { return compareValues(typ, a.Field(field), b.Field(field)) }, nil
+}
+
+// compareValues orders two values of a field of type typ
+func compareValues(typ Type, a, b any) int {
+	switch typ {
+	case Size, Number:
+		x, y := a.(int64), b.(int64)
+		return cmpOrdered(x, y)
+	case Age:
+		x, y := a.(time.Duration), b.(time.Duration)
+		return cmpOrdered(x, y)
+	case Version:
+		x, y := a.(string), b.(string)
+		if goversion.IsValid(x) && goversion.IsValid(y) {
+			return goversion.Compare(x, y)
+		}
+		return strings.Compare(x, y)
+	case Semver:
+		return compareSemver(a.(string), b.(string))
+	}
+	return strings.Compare(a.(string), b.(string))
+}
+
+func cmpOrdered[T int64 | time.Duration](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// unknownField reports a field that is not one of fields
+func unknownField(name string, fields Fields) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return fmt.Errorf("unknown field %q (want one of: %s)", name, strings.Join(names, ", "))
+}
+
+// Token kinds
+const (
+	tokenWord   = iota // a field name, bare word or number with unit
+	tokenString        // a quoted string, unquoted
+	tokenOp            // an operator or parenthesis
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			text := s[i+1 : j]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(s[i : j+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s: %w", s[i:j+1], err)
+				}
+				text = unquoted
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text})
+			i = j + 1
+		case isWordByte(c):
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q in %q", c, s)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// isWordByte reports whether c can be part of a field name, bare word or
+// literal such as go1.24, linux/amd64 or 1.5GB
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '_' || c == '.' || c == '/' || c == '-' || c == '@' || c == '+'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	fields Fields
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOp && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) or() (func(Record) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r Record) bool { return l(r) || right(r) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (func(Record) bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r Record) bool { return l(r) && right(r) }
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (func(Record) bool, error) {
+	if p.accept("!") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(r Record) bool { return !inner(r) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (func(Record) bool, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind != tokenWord {
+		return nil, fmt.Errorf("expected a field, got %q", field.text)
+	}
+	typ, ok := p.fields[field.text]
+	if !ok {
+		return nil, unknownField(field.text, p.fields)
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("expected an operator after %s", field.text)
+	}
+	if op.kind != tokenOp || !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">=", "=~", "!~"}, op.text) {
+		return nil, fmt.Errorf("expected an operator after %s, got %q", field.text, op.text)
+	}
+	literal, err := p.next()
+	if err != nil || literal.kind == tokenOp {
+		return nil, fmt.Errorf("expected a value after %s %s", field.text, op.text)
+	}
+
+	name := field.text
+	if op.text == "=~" || op.text == "!~" {
+		if typ != String && typ != Version && typ != Semver {
+			return nil, fmt.Errorf("%s cannot be matched against a regular expression", name)
+		}
+		re, err := regexp.Compile(literal.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", literal.text, err)
+		}
+		want := op.text == "=~"
+		return func(r Record) bool { return re.MatchString(r.Field(name).(string)) == want }, nil
+	}
+
+	value, err := parseLiteral(typ, literal.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	var test func(c int) bool
+	switch op.text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	default:
+		test = func(c int) bool { return c >= 0 }
+	}
+	if (typ == Version || typ == Semver) && op.text != "==" && op.text != "!=" {
+		if !validVersion(typ, value.(string)) {
+			return nil, fmt.Errorf("invalid value for %s: %q is not a version to compare with", name, literal.text)
+		}
+		// A missing or unknown version is neither older nor newer
+		return func(r Record) bool {
+			v := r.Field(name).(string)
+			return validVersion(typ, v) && test(compareValues(typ, v, value))
+		}, nil
+	}
+	return func(r Record) bool { return test(compareValues(typ, r.Field(name), value)) }, nil
+}
+
+// validVersion reports whether v is a valid version of type typ
+func validVersion(typ Type, v string) bool {
+	if typ == Semver {
+		_, ok := parseSemver(v)
+		return ok
+	}
+	return goversion.IsValid(v)
+}
+
+// parseLiteral reads a literal compared with a field of type typ
+func parseLiteral(typ Type, s string) (any, error) {
+	switch typ {
+	case Size:
+		return cache.ParseBytes(s)
+	case Age:
+		return cache.ParseAge(s)
+	case Number:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return n, nil
+	case Semver:
+		if s != "" && '0' <= s[0] && s[0] <= '9' {
+			s = "v" + s
+		}
+	case Version:
+		if !strings.HasPrefix(s, "go") && s != "" && '0' <= s[0] && s[0] <= '9' {
+			s = "go" + s
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var testFields = Fields{"kind": String, "size": Size, "age": Age, "toolchain": Version}
+
+type testRecord map[string]any
+
+func (r testRecord) Field(name string) any {
+	return r[name]
+}
+
+var archive = testRecord{"kind": "archive", "size": int64(8 << 20), "age": 40 * 24 * time.Hour, "toolchain": "go1.23.4"}
+var action = testRecord{"kind": "action", "size": int64(120), "age": time.Hour, "toolchain": ""}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		expr            string
+		archive, action bool
+	}{
+		{`kind=="archive" && age>30d && size>5MB`, true, false},
+		{`kind == 'action'`, false, true},
+		{`kind==action`, false, true},
+		{`kind != "archive"`, false, true},
+		{`size <= 120`, false, true},
+		{`size >= 8MB`, true, false},
+		{`age < 2w`, false, true},
+		{`kind =~ "^a" && !(kind =~ "ion$")`, true, false},
+		{`kind !~ "arch"`, false, true},
+		{`kind == "archive" || size < 1K`, true, true},
+		{`kind == "x" || kind == "action" && size < 1K`, false, true},
+		{`toolchain < go1.24`, true, false},
+		{`toolchain >= 1.23.4`, true, false},
+		{`toolchain == ""`, false, true},
+	} {
+		expr, err := Parse(tc.expr, testFields)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tc.expr, err)
+			continue
+		}
+		if got := expr.Match(archive); got != tc.archive {
+			t.Errorf("%q on the archive: expected %v, got %v", tc.expr, tc.archive, got)
+		}
+		if got := expr.Match(action); got != tc.action {
+			t.Errorf("%q on the action: expected %v, got %v", tc.expr, tc.action, got)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`name == "x"`,
+		`kind`,
+		`kind ==`,
+		`kind == "x`,
+		`size > lots`,
+		`age > 3 parsecs`,
+		`size =~ "1"`,
+		`kind =~ "("`,
+		`(kind == "x"`,
+		`kind == "x" &&`,
+		`kind == "x" size > 1`,
+		`kind $ "x"`,
+		`toolchain < gofoo`,
+	} {
+		if _, err := Parse(expr, testFields); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", expr)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	records := []Record{
+		testRecord{"toolchain": "go1.10"},
+		testRecord{"toolchain": "go1.9.2"},
+		testRecord{"toolchain": "go1.24rc1"},
+	}
+	cmp, err := Compare(testFields, "toolchain")
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	slices.SortFunc(records, cmp)
+	var got []any
+	for _, r := range records {
+		got = append(got, r.Field("toolchain"))
+	}
+	if !slices.Equal(got, []any{"go1.9.2", "go1.10", "go1.24rc1"}) {
+		t.Errorf("Expected release order, got %v", got)
+	}
+
+	if _, err := Compare(testFields, "name"); err == nil {
+		t.Error("Expected an unknown field to fail")
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	ordered := []string{
+		"latest",
+		"v0.0.0-20220909182711-5c715a9e8561",
+		"v0.1.0-alpha",
+		"v0.1.0-alpha.1",
+		"v0.1.0-alpha.beta",
+		"v0.1.0-beta.2",
+		"v0.1.0-beta.11",
+		"v0.1.0",
+		"v1",
+		"v1.2.3",
+		"v1.10.0",
+		"v2.0.0+incompatible",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := cmpOrdered(int64(i), int64(j))
+			if got := compareSemver(ordered[i], ordered[j]); got != want {
+				t.Errorf("compareSemver(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestParse_SemverAndNumber(t *testing.T) {
+	fields := Fields{"version": Semver, "versions": Number}
+	record := testRecord{"version": "v1.10.0", "versions": int64(3)}
+	for expr, want := range map[string]bool{
+		`version > v1.9.0`:    true,
+		`version < 1.10.1`:    true,
+		`version >= v2`:       false,
+		`version =~ "^v1\\."`: true,
+		`versions > 2`:        true,
+		`versions == 4`:       false,
+	} {
+		e, err := Parse(expr, fields)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", expr, err)
+			continue
+		}
+		if got := e.Match(record); got != want {
+			t.Errorf("%q: expected %v, got %v", expr, want, got)
+		}
+	}
+	if _, err := Parse(`versions > many`, fields); err == nil {
+		t.Error("Expected a number field to reject words")
+	}
+	if _, err := Parse(`version < latest`, fields); err == nil {
+		t.Error("Expected an ordering comparison to reject an invalid version")
+	}
+	if e, err := Parse(`version < v2`, fields); err != nil || e.Match(testRecord{"version": "master"}) {
+		t.Errorf("Expected an invalid version not to be older, got %v", err)
+	}
+}
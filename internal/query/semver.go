@@ -0,0 +1,107 @@
+package query
+
+import "strings"
+
+// semver is a parsed module version such as v1.2.3-pre+build
+type semver struct {
+	major, minor, patch string
+	prerelease          string
+}
+
+// parseSemver parses a module version, which unlike a Go version starts
+// with v and may omit its minor and patch numbers, as in v2
+func parseSemver(v string) (semver, bool) {
+	if !strings.HasPrefix(v, "v") {
+		return semver{}, false
+	}
+	v, _, _ = strings.Cut(v[1:], "+")
+	v, pre, hasPre := strings.Cut(v, "-")
+	if hasPre && pre == "" {
+		return semver{}, false
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	for _, part := range parts {
+		if !isNumber(part) {
+			return semver{}, false
+		}
+	}
+	return semver{major: parts[0], minor: parts[1], patch: parts[2], prerelease: pre}, true
+}
+
+// isNumber reports whether s is a decimal number without leading zeros
+func isNumber(s string) bool {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareNumbers orders two decimal numbers of any length
+func compareNumbers(x, y string) int {
+	if len(x) != len(y) {
+		return cmpOrdered(int64(len(x)), int64(len(y)))
+	}
+	return strings.Compare(x, y)
+}
+
+// compareSemver orders module versions by semantic versioning, ignoring
+// build metadata such as +incompatible. Versions that are not valid sort
+// before valid ones, and among themselves as text.
+func compareSemver(a, b string) int {
+	x, okX := parseSemver(a)
+	y, okY := parseSemver(b)
+	switch {
+	case !okX && !okY:
+		return strings.Compare(a, b)
+	case !okX:
+		return -1
+	case !okY:
+		return 1
+	}
+	for _, pair := range [][2]string{{x.major, y.major}, {x.minor, y.minor}, {x.patch, y.patch}} {
+		if c := compareNumbers(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	// A prerelease precedes its release; prereleases compare field by
+	// field, numeric fields lower than others
+	switch {
+	case x.prerelease == y.prerelease:
+		return 0
+	case x.prerelease == "":
+		return 1
+	case y.prerelease == "":
+		return -1
+	}
+	xs, ys := strings.Split(x.prerelease, "."), strings.Split(y.prerelease, ".")
+	for i := 0; i < len(xs) && i < len(ys); i++ {
+		xNum, yNum := isNumber(xs[i]), isNumber(ys[i])
+		var c int
+		switch {
+		case xNum && yNum:
+			c = compareNumbers(xs[i], ys[i])
+		case xNum:
+			c = -1
+		case yNum:
+			c = 1
+		default:
+			c = strings.Compare(xs[i], ys[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmpOrdered(int64(len(xs)), int64(len(ys)))
+}
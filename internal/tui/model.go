@@ -0,0 +1,383 @@
+// Package tui implements gocachectl's interactive cache browser, an ncdu
+// for Go caches: one tab per cache, sortable entry lists, module versions
+// grouped by module, and marking entries for deletion.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+// Tab is one cache shown by the browser
+type Tab struct {
+	Title  string
+	Lister cache.EntryLister
+}
+
+// Run shows the browser full-screen until the user quits
+func Run(tabs []Tab) error {
+	if len(tabs) == 0 {
+		return fmt.Errorf("no caches to browse")
+	}
+	_, err := tea.NewProgram(New(tabs), tea.WithAltScreen()).Run()
+	return err
+}
+
+// sortMode orders the rows of a tab
+type sortMode int
+
+const (
+	sortBySize sortMode = iota // largest first
+	sortByAge                  // least recently modified first
+	sortByName
+)
+
+func (s sortMode) String() string {
+	switch s {
+	case sortByAge:
+		return "oldest"
+	case sortByName:
+		return "name"
+	}
+	return "size"
+}
+
+// tabState is the scan result and browsing position of one tab
+type tabState struct {
+	Tab
+	entries []cache.Entry
+	loading bool
+	err     error
+	marked  map[string]bool // keyed by entry path
+	module  string          // module drilled into, if any
+	cursor  int
+	offset  int
+}
+
+// row is one line of a list: an entry, or a module with its versions
+type row struct {
+	name    string
+	size    int64
+	modTime time.Time
+	entries []cache.Entry
+	module  string // set for module rows, which open on enter
+}
+
+// Model is the bubbletea model of the browser
+type Model struct {
+	tabs       []*tabState
+	active     int
+	sort       sortMode
+	confirming []cache.Entry // entries awaiting delete confirmation
+	status     string
+	width      int
+	height     int
+}
+
+// scanMsg delivers the entries of a tab scanned in the background
+type scanMsg struct {
+	tab     int
+	entries []cache.Entry
+	err     error
+}
+
+// removeMsg reports a background removal
+type removeMsg struct {
+	tab     int
+	deleted int
+	freed   int64
+	err     error
+}
+
+// New creates the browser model for tabs
+func New(tabs []Tab) *Model {
+	m := &Model{width: 80, height: 24}
+	for _, tab := range tabs {
+		m.tabs = append(m.tabs, &tabState{Tab: tab, loading: true, marked: make(map[string]bool)})
+	}
+	return m
+}
+
+// Init scans every tab concurrently
+func (m *Model) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.tabs {
+		cmds = append(cmds, m.scan(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// scan lists a tab's entries in the background
+func (m *Model) scan(i int) tea.Cmd {
+	lister := m.tabs[i].Lister
+	return func() tea.Msg {
+		entries, err := lister.ListEntries()
+		return scanMsg{tab: i, entries: entries, err: err}
+	}
+}
+
+// remove deletes entries of a tab in the background
+func (m *Model) remove(i int, entries []cache.Entry) tea.Cmd {
+	lister := m.tabs[i].Lister
+	return func() tea.Msg {
+		deleted, freed, err := lister.RemoveEntries(entries)
+		return removeMsg{tab: i, deleted: deleted, freed: freed, err: err}
+	}
+}
+
+// Update handles messages and key presses
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.tabs[m.active].clamp(m.listHeight())
+
+	case scanMsg:
+		tab := m.tabs[msg.tab]
+		tab.loading = false
+		tab.entries, tab.err = msg.entries, msg.err
+		tab.clamp(m.listHeight())
+
+	case removeMsg:
+		tab := m.tabs[msg.tab]
+		m.status = fmt.Sprintf("Removed %s entries, freed %s", cache.FormatCount(msg.deleted), cache.FormatBytes(msg.freed))
+		if msg.err != nil {
+			m.status += " - " + msg.err.Error()
+		}
+		tab.marked = make(map[string]bool)
+		tab.loading = true
+		return m, m.scan(msg.tab)
+
+	case tea.KeyMsg:
+		if m.confirming != nil {
+			return m.updateConfirm(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	entries := m.confirming
+	m.confirming = nil
+
+	switch msg.String() {
+	case "y", "Y":
+		m.status = fmt.Sprintf("Removing %s entries...", cache.FormatCount(len(entries)))
+		return m, m.remove(m.active, entries)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	m.status = "Deletion cancelled"
+	return m, nil
+}
+
+func (m *Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.tabs[m.active]
+	rows := m.rows(tab)
+	page := m.listHeight()
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "tab", "right", "l":
+		m.active = (m.active + 1) % len(m.tabs)
+	case "shift+tab", "left", "h":
+		m.active = (m.active + len(m.tabs) - 1) % len(m.tabs)
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(msg.String()[0] - '1'); i < len(m.tabs) {
+			m.active = i
+		}
+
+	case "up", "k":
+		tab.cursor--
+	case "down", "j":
+		tab.cursor++
+	case "pgup", "ctrl+u":
+		tab.cursor -= page
+	case "pgdown", "ctrl+d":
+		tab.cursor += page
+	case "home", "g":
+		tab.cursor = 0
+	case "end", "G":
+		tab.cursor = len(rows) - 1
+
+	case "enter":
+		if tab.cursor < len(rows) && rows[tab.cursor].module != "" {
+			tab.module = rows[tab.cursor].module
+			tab.cursor, tab.offset = 0, 0
+		}
+	case "backspace", "esc":
+		if tab.module != "" {
+			// Return to the module just left
+			module := tab.module
+			tab.module = ""
+			for i, r := range m.rows(tab) {
+				if r.module == module {
+					tab.cursor = i
+				}
+			}
+		}
+
+	case "s":
+		m.sort = (m.sort + 1) % 3
+	case " ":
+		if tab.cursor < len(rows) {
+			tab.toggle(rows[tab.cursor].entries)
+			tab.cursor++
+		}
+	case "a":
+		var all []cache.Entry
+		for _, r := range rows {
+			all = append(all, r.entries...)
+		}
+		tab.toggle(all)
+
+	case "d", "delete":
+		entries := tab.markedEntries()
+		if len(entries) == 0 && tab.cursor < len(rows) {
+			entries = rows[tab.cursor].entries
+		}
+		if len(entries) > 0 && !tab.loading {
+			m.confirming = entries
+		}
+
+	case "r":
+		if !tab.loading {
+			tab.loading = true
+			m.status = ""
+			return m, m.scan(m.active)
+		}
+	}
+
+	m.tabs[m.active].clamp(page)
+	return m, nil
+}
+
+// toggle marks entries, or unmarks them when all are marked already
+func (t *tabState) toggle(entries []cache.Entry) {
+	mark := !t.allMarked(entries)
+	for _, e := range entries {
+		if mark {
+			t.marked[e.Path] = true
+		} else {
+			delete(t.marked, e.Path)
+		}
+	}
+}
+
+func (t *tabState) allMarked(entries []cache.Entry) bool {
+	for _, e := range entries {
+		if !t.marked[e.Path] {
+			return false
+		}
+	}
+	return len(entries) > 0
+}
+
+// markedEntries returns the marked entries, in scan order
+func (t *tabState) markedEntries() []cache.Entry {
+	var marked []cache.Entry
+	for _, e := range t.entries {
+		if t.marked[e.Path] {
+			marked = append(marked, e)
+		}
+	}
+	return marked
+}
+
+// clamp keeps the cursor on a row and in view
+func (t *tabState) clamp(height int) {
+	n := t.rowCount()
+	t.cursor = max(0, min(t.cursor, n-1))
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if height > 0 && t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+}
+
+// rowCount returns the number of rows without sorting them
+func (t *tabState) rowCount() int {
+	if !t.grouped() {
+		return len(t.entries)
+	}
+	seen := make(map[string]bool)
+	for _, e := range t.entries {
+		switch {
+		case t.module == "":
+			seen[e.Module] = true
+		case e.Module == t.module:
+			seen[e.Path] = true
+		}
+	}
+	return len(seen)
+}
+
+// grouped reports whether the tab lists module versions
+func (t *tabState) grouped() bool {
+	return len(t.entries) > 0 && t.entries[0].Module != ""
+}
+
+// rows returns the lines of a tab in the current sort order: modules, the
+// versions of the module drilled into, or plain entries
+func (m *Model) rows(t *tabState) []row {
+	var rows []row
+	switch {
+	case t.grouped() && t.module == "":
+		index := make(map[string]int)
+		for _, e := range t.entries {
+			i, ok := index[e.Module]
+			if !ok {
+				i = len(rows)
+				index[e.Module] = i
+				rows = append(rows, row{module: e.Module})
+			}
+			r := &rows[i]
+			r.size += e.Size
+			r.entries = append(r.entries, e)
+			if e.ModTime.After(r.modTime) {
+				r.modTime = e.ModTime
+			}
+		}
+		for i := range rows {
+			rows[i].name = fmt.Sprintf("%s (%d versions)", rows[i].module, len(rows[i].entries))
+			if len(rows[i].entries) == 1 {
+				rows[i].name = rows[i].module + " (1 version)"
+			}
+		}
+	case t.grouped():
+		for _, e := range t.entries {
+			if e.Module == t.module {
+				rows = append(rows, row{name: e.Version, size: e.Size, modTime: e.ModTime, entries: []cache.Entry{e}})
+			}
+		}
+	default:
+		for _, e := range t.entries {
+			rows = append(rows, row{name: e.Name, size: e.Size, modTime: e.ModTime, entries: []cache.Entry{e}})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch m.sort {
+		case sortByAge:
+			return rows[i].modTime.Before(rows[j].modTime)
+		case sortByName:
+			return strings.ToLower(rows[i].name) < strings.ToLower(rows[j].name)
+		}
+		return rows[i].size > rows[j].size
+	})
+	return rows
+}
+
+// listHeight is the number of list rows that fit between header and footer
+func (m *Model) listHeight() int {
+	return max(1, m.height-8)
+}
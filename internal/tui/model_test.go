@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+// fakeLister serves fixed entries and records removals
+type fakeLister struct {
+	entries []cache.Entry
+	removed []cache.Entry
+}
+
+func (l *fakeLister) ListEntries() ([]cache.Entry, error) {
+	var kept []cache.Entry
+	for _, e := range l.entries {
+		gone := false
+		for _, r := range l.removed {
+			gone = gone || r.Path == e.Path
+		}
+		if !gone {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
+
+func (l *fakeLister) RemoveEntries(entries []cache.Entry) (int, int64, error) {
+	var freed int64
+	for _, e := range entries {
+		freed += e.Size
+	}
+	l.removed = append(l.removed, entries...)
+	return len(entries), freed, nil
+}
+
+var (
+	day     = 24 * time.Hour
+	now     = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	modules = []cache.Entry{
+		{Name: "example.com/a@v1.0.0", Module: "example.com/a", Version: "v1.0.0", Path: "/mod/example.com/a@v1.0.0", Size: 100, ModTime: now.Add(-3 * day)},
+		{Name: "example.com/a@v1.1.0", Module: "example.com/a", Version: "v1.1.0", Path: "/mod/example.com/a@v1.1.0", Size: 300, ModTime: now.Add(-1 * day)},
+		{Name: "example.com/b@v0.1.0", Module: "example.com/b", Version: "v0.1.0", Path: "/mod/example.com/b@v0.1.0", Size: 200, ModTime: now.Add(-9 * day)},
+	}
+)
+
+// send runs msg through the model, then any command it returns, the way
+// the bubbletea runtime would
+func send(t *testing.T, m *Model, msg tea.Msg) {
+	t.Helper()
+
+	_, cmd := m.Update(msg)
+	for cmd != nil {
+		next := cmd()
+		if next == nil {
+			return
+		}
+		if _, ok := next.(tea.QuitMsg); ok {
+			return
+		}
+		_, cmd = m.Update(next)
+	}
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func newScanned(t *testing.T, lister *fakeLister) *Model {
+	t.Helper()
+
+	m := New([]Tab{{Title: "Module Cache", Lister: lister}})
+	send(t, m, m.scan(0)())
+	return m
+}
+
+func TestModel_GroupsAndSorts(t *testing.T) {
+	m := newScanned(t, &fakeLister{entries: modules})
+	tab := m.tabs[0]
+
+	rows := m.rows(tab)
+	if len(rows) != 2 || rows[0].module != "example.com/a" || rows[0].size != 400 {
+		t.Fatalf("Expected modules largest first, got %+v", rows)
+	}
+
+	send(t, m, key("s"))
+	if rows := m.rows(tab); rows[0].module != "example.com/b" {
+		t.Errorf("Expected the least recently used module first, got %+v", rows[0])
+	}
+
+	send(t, m, key("enter"))
+	if tab.module != "example.com/b" {
+		t.Fatalf("Expected to open example.com/b, got %q", tab.module)
+	}
+	if rows := m.rows(tab); len(rows) != 1 || rows[0].name != "v0.1.0" {
+		t.Errorf("Expected the versions of example.com/b, got %+v", rows)
+	}
+
+	send(t, m, key("esc"))
+	if tab.module != "" || tab.cursor != 0 {
+		t.Errorf("Expected to return to the module list at example.com/b, got %q at %d", tab.module, tab.cursor)
+	}
+}
+
+func TestModel_MarkAndDelete(t *testing.T) {
+	lister := &fakeLister{entries: modules}
+	m := newScanned(t, lister)
+	tab := m.tabs[0]
+
+	// Mark every version of example.com/a, the largest module
+	send(t, m, key(" "))
+	if got := len(tab.markedEntries()); got != 2 {
+		t.Fatalf("Expected 2 marked versions, got %d", got)
+	}
+	if !strings.Contains(m.View(), "2 marked (400 B)") {
+		t.Errorf("Expected the marks in the summary:\n%s", m.View())
+	}
+
+	// Anything but y cancels
+	send(t, m, key("d"))
+	if m.confirming == nil {
+		t.Fatal("Expected a confirmation prompt")
+	}
+	send(t, m, key("n"))
+	if m.confirming != nil || len(lister.removed) != 0 {
+		t.Fatal("Expected the deletion to be cancelled")
+	}
+
+	send(t, m, key("d"))
+	send(t, m, key("y"))
+	if len(lister.removed) != 2 {
+		t.Fatalf("Expected 2 versions removed, got %+v", lister.removed)
+	}
+	if len(tab.entries) != 1 || len(tab.marked) != 0 || tab.loading {
+		t.Errorf("Expected a rescan without marks, got %d entries and %d marks", len(tab.entries), len(tab.marked))
+	}
+	if !strings.Contains(m.status, "Removed 2 entries, freed 400 B") {
+		t.Errorf("Unexpected status %q", m.status)
+	}
+}
+
+func TestModel_DeleteCursorRow(t *testing.T) {
+	lister := &fakeLister{entries: []cache.Entry{
+		{Name: "00/a-d", Path: "/cache/00/a-d", Size: 10},
+		{Name: "01/b-d", Path: "/cache/01/b-d", Size: 20},
+	}}
+	m := newScanned(t, lister)
+
+	// Without marks, d deletes the entry under the cursor
+	send(t, m, key("down"))
+	send(t, m, key("d"))
+	send(t, m, key("y"))
+	if len(lister.removed) != 1 || lister.removed[0].Name != "00/a-d" {
+		t.Errorf("Expected the smaller entry under the cursor to be removed, got %+v", lister.removed)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muhammadali7768/gocachectl/internal/cache"
+)
+
+var (
+	titleStyle     = lipgloss.NewStyle().Bold(true)
+	activeTabStyle = lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1)
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	cursorStyle    = lipgloss.NewStyle().Reverse(true)
+	markedStyle    = lipgloss.NewStyle().Bold(true)
+	dimStyle       = lipgloss.NewStyle().Faint(true)
+	warnStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+)
+
+// View renders the browser
+func (m *Model) View() string {
+	var b strings.Builder
+	tab := m.tabs[m.active]
+
+	b.WriteString(titleStyle.Render("gocachectl - Go cache browser"))
+	b.WriteString("\n")
+
+	var tabs []string
+	for i, t := range m.tabs {
+		label := fmt.Sprintf("%d %s", i+1, t.Title)
+		if i == m.active {
+			tabs = append(tabs, activeTabStyle.Render(label))
+		} else {
+			tabs = append(tabs, tabStyle.Render(label))
+		}
+	}
+	b.WriteString(strings.Join(tabs, " "))
+	b.WriteString("\n")
+
+	b.WriteString(m.summary(tab))
+	b.WriteString("\n\n")
+
+	b.WriteString(dimStyle.Render(fmt.Sprintf("      %10s  %-16s  %s", "SIZE", "MODIFIED", "NAME")))
+	b.WriteString("\n")
+	b.WriteString(m.list(tab))
+
+	b.WriteString("\n")
+	switch {
+	case m.confirming != nil:
+		var size int64
+		for _, e := range m.confirming {
+			size += e.Size
+		}
+		b.WriteString(warnStyle.Render(fmt.Sprintf("Delete %s entries (%s)? [y/N]",
+			cache.FormatCount(len(m.confirming)), cache.FormatBytes(size))))
+	case m.status != "":
+		b.WriteString(m.status)
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(m.help(tab)))
+
+	return b.String()
+}
+
+// summary describes the tab's totals, sort order and marks
+func (m *Model) summary(t *tabState) string {
+	if t.loading {
+		return "Scanning..."
+	}
+	if t.err != nil {
+		return warnStyle.Render("Error: " + t.err.Error())
+	}
+
+	var size int64
+	for _, e := range t.entries {
+		size += e.Size
+	}
+	parts := []string{
+		fmt.Sprintf("%s entries, %s", cache.FormatCount(len(t.entries)), cache.FormatBytes(size)),
+		"sorted by " + m.sort.String(),
+	}
+	if marked := t.markedEntries(); len(marked) > 0 {
+		var markedSize int64
+		for _, e := range marked {
+			markedSize += e.Size
+		}
+		parts = append(parts, markedStyle.Render(fmt.Sprintf("%s marked (%s)", cache.FormatCount(len(marked)), cache.FormatBytes(markedSize))))
+	}
+	if t.module != "" {
+		parts = append(parts, "in "+t.module)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// list renders the visible rows, padded to the list height
+func (m *Model) list(t *tabState) string {
+	height := m.listHeight()
+	lines := make([]string, 0, height)
+
+	if !t.loading && t.err == nil {
+		rows := m.rows(t)
+		end := min(len(rows), t.offset+height)
+		for i := t.offset; i < end; i++ {
+			r := rows[i]
+			mark := "[ ]"
+			if t.allMarked(r.entries) {
+				mark = "[x]"
+			}
+			modified := "-"
+			if !r.modTime.IsZero() {
+				modified = r.modTime.Format("2006-01-02 15:04")
+			}
+
+			line := fmt.Sprintf("  %s %10s  %-16s  %s", mark, cache.FormatBytes(r.size), modified, r.name)
+			if m.width > 0 && len(line) > m.width {
+				line = line[:m.width]
+			}
+			switch {
+			case i == t.cursor:
+				line = cursorStyle.Render(line)
+			case mark == "[x]":
+				line = markedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		if len(rows) == 0 {
+			lines = append(lines, dimStyle.Render("  (empty)"))
+		}
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// help lists the keys that apply in the current state
+func (m *Model) help(t *tabState) string {
+	if m.confirming != nil {
+		return "y delete | any other key cancels"
+	}
+	keys := []string{"up/down move", "space mark", "a mark all", "d delete", "s sort", "tab switch", "r rescan", "q quit"}
+	if t.module != "" {
+		keys = append([]string{"esc back"}, keys...)
+	} else if t.grouped() {
+		keys = append([]string{"enter versions"}, keys...)
+	}
+	return strings.Join(keys, " | ")
+}